@@ -0,0 +1,84 @@
+import type { BinaryOperationName } from "./services/operations";
+import type {
+  ErrorCode,
+  ErrorResponse,
+  HealthResponse,
+  OperationResponse,
+} from "./types";
+
+// Sends a request and resolves with the response, like the global fetch or a
+// Workers service binding's fetch.
+export type Fetcher = (request: Request) => Promise<Response>;
+
+// Thrown when the calculator answers with an error status. code is absent if
+// the body was not an ErrorResponse, e.g. from a proxy in between.
+export class CalculatorClientError extends Error {
+  readonly status: number;
+  readonly code?: ErrorCode;
+
+  constructor(status: number, message: string, code?: ErrorCode) {
+    super(message);
+    this.name = "CalculatorClientError";
+    this.status = status;
+    this.code = code;
+  }
+}
+
+async function clientError(response: Response): Promise<CalculatorClientError> {
+  const body = await response
+    .json<Partial<ErrorResponse>>()
+    .catch((): Partial<ErrorResponse> => ({}));
+  return new CalculatorClientError(
+    response.status,
+    body.error ?? `Calculator responded with ${response.status}`,
+    body.code
+  );
+}
+
+// Calls another calculator instance over HTTP. Network failures reject with
+// the fetcher's error.
+export class CalculatorClient {
+  readonly baseUrl: string;
+  private readonly fetcher: Fetcher;
+
+  constructor(baseUrl: string, fetcher: Fetcher = (request) => fetch(request)) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.fetcher = fetcher;
+  }
+
+  async calculate(
+    operation: BinaryOperationName,
+    a: number,
+    b: number,
+    signal?: AbortSignal
+  ): Promise<number> {
+    const response = await this.fetcher(
+      new Request(`${this.baseUrl}/${operation}`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a, b }),
+        signal,
+      })
+    );
+    if (!response.ok) {
+      throw await clientError(response);
+    }
+    const { result } = await response.json<OperationResponse>();
+    return result;
+  }
+
+  // Resolves with the body of the instance's GET /health.
+  async health(signal?: AbortSignal): Promise<HealthResponse> {
+    const response = await this.fetcher(
+      new Request(`${this.baseUrl}/health`, { signal })
+    );
+    if (!response.ok) {
+      throw await clientError(response);
+    }
+    return response.json<HealthResponse>();
+  }
+
+  add(a: number, b: number, signal?: AbortSignal): Promise<number> {
+    return this.calculate("add", a, b, signal);
+  }
+}
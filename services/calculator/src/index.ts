@@ -1,17 +1,417 @@
 import { Hono } from "hono";
+import { admin } from "./routes/admin";
+import { checkAliases } from "./routes/aliases";
+import { batch } from "./routes/batch";
+import { bench } from "./routes/bench";
 import { calculator } from "./routes/calculator";
+import { csv } from "./routes/csv";
+import { fleet } from "./routes/fleet";
+import { jsonp } from "./routes/jsonp";
+import { operations } from "./routes/operations";
+import { ping } from "./routes/ping";
+import { recording } from "./routes/recording";
+import { schema } from "./routes/schema";
+import { history as historyRoutes } from "./routes/history";
+import { errorResponse } from "./routes/response";
+import { websocket } from "./routes/websocket";
+import {
+  DEFAULT_API_KEY_EXEMPT_PATHS,
+  requireApiKey,
+} from "./middleware/api-key";
+import { logRequestBodies } from "./middleware/body-log";
+import { chain } from "./middleware/chain";
+import { responseDelay } from "./middleware/delay";
+import { envelope } from "./middleware/envelope";
+import { etag } from "./middleware/etag";
+import { maxHeaderBytes } from "./middleware/headers";
+import { checkContentLength } from "./middleware/content-length";
+import {
+  requestCancellations,
+  requestLatency,
+} from "./middleware/metrics";
+import { withVariables } from "./middleware/variables";
+import { metrics as metricsRoutes } from "./routes/metrics";
+import { readiness } from "./routes/readiness";
+import { stats } from "./routes/stats";
+import { idempotency, IdempotencyStore } from "./middleware/idempotency";
+import { requireJson } from "./middleware/json";
+import { protobuf } from "./middleware/protobuf";
+import { recordExchanges } from "./middleware/record";
+import { requestId } from "./middleware/request-id";
+import { requireBody } from "./middleware/require-body";
+import {
+  DEFAULT_HTTPS_EXEMPT_PATHS,
+  requireHttps,
+} from "./middleware/https";
+import { securityHeaders } from "./middleware/security";
+import { logSlowRequests } from "./middleware/slow";
+import { routeTimeouts } from "./middleware/timeouts";
+import {
+  redirectTrailingSlash,
+  routeMatcher,
+  trimTrailingSlash,
+} from "./middleware/trailing-slash";
+import { verifySignature } from "./middleware/signature";
+import { systemClock } from "./services/clock";
+import { auditOperations } from "./services/audit";
+import { cacheResults } from "./services/cache";
+import {
+  canonicalJsonEncoder,
+  DEFAULT_ENCODERS,
+  jsonEncoder,
+  renameKeys,
+  withSignificantDigits,
+} from "./services/encoders";
+import { DEFAULT_FLEET_TIMEOUT_MS } from "./services/fleet";
+import { createDefaultOperationRegistry } from "./services/metadata";
+import { Metrics } from "./services/metrics";
+import { DEFAULT_REQUEST_TIMEOUT_MS } from "./services/deadline";
+import { History, recordHistory } from "./services/history";
+import {
+  DEFAULT_BATCH_CONCURRENCY,
+  DEFAULT_MAX_BATCH_WORKERS,
+  Semaphore,
+} from "./services/pool";
+import { CircuitBreaker } from "./services/breaker";
+import { proxyAdd } from "./services/proxy";
+import { DegradedMode, selfArithmeticCheck } from "./services/readiness";
+import { Recording } from "./services/recording";
+import { Stats } from "./services/stats";
+import { calculatorService } from "./services/operations";
+import { createDefaultValidators } from "./services/validators";
+import type { CalculatorClient } from "./client";
+import type { ApiKeyOptions } from "./middleware/api-key";
+import type { RequestBodyLogOptions } from "./middleware/body-log";
+import type { RequireHttpsOptions } from "./middleware/https";
+import type { SecurityHeadersOptions } from "./middleware/security";
+import type { SlowRequestOptions } from "./middleware/slow";
+import type { TrailingSlashMode } from "./middleware/trailing-slash";
+import type { CircuitBreakerOptions } from "./services/breaker";
+import type { AuditOptions } from "./services/audit";
+import type { CacheOptions } from "./services/cache";
+import type { Clock } from "./services/clock";
+import type { ResponseEncoder } from "./services/encoders";
+import type { FleetOptions } from "./services/fleet";
+import type { HistoryOptions } from "./services/history";
+import type { Lifecycle } from "./services/lifecycle";
+import type { OperationRegistry } from "./services/metadata";
+import type {
+  CalculatorService,
+  ResultTransform,
+} from "./services/operations";
+import type { ReadinessCheck } from "./services/readiness";
+import type { ValidatorRegistry } from "./services/validators";
+import type { AppEnv, ErrorPolicy } from "./types";
 
-const app = new Hono();
+export interface AppOptions {
+  // Source of time for timestamps and TTLs. Defaults to the system clock.
+  clock?: Clock;
+  // Per-operation preconditions checked before computing. Defaults to the
+  // built-in domain rules; extend createDefaultValidators() to add more.
+  validators?: ValidatorRegistry;
+  // Metadata served by GET /operations/{name}/describe. Defaults to the
+  // built-in operations.
+  operations?: OperationRegistry;
+  // Performs the arithmetic. Defaults to the built-in calculator. Wrap it in
+  // coalesce() to share concurrent identical computations.
+  service?: CalculatorService;
+  // Serve repeated operations from a cache of recent results, counted as
+  // hits and misses in /metrics. Off by default.
+  cache?: CacheOptions;
+  // Upper bounds, in seconds, of the request latency histogram buckets.
+  latencyBuckets?: number[];
+  // Path every route is mounted under, e.g. "/api/v1". Defaults to the root.
+  prefix?: string;
+  // Extra paths served by existing routes, e.g. { "/sum": "/add" }, relative
+  // to the prefix. An alias that is itself a route, or that refers to none,
+  // makes createApp throw.
+  aliases?: Record<string, string>;
+  // How paths with a trailing slash, such as /add/, are handled. Defaults to
+  // "rewrite", which serves them as if the slash were absent; unknown paths
+  // are not found either way.
+  trailingSlash?: TrailingSlashMode;
+  // Milliseconds an operation may run before the request fails with 503.
+  // Defaults to 10 seconds.
+  requestTimeoutMs?: number;
+  // Timeouts for particular routes in place of requestTimeoutMs, e.g.
+  // { "/add": 1000, "/stats": 30000 }, relative to the prefix. A path also
+  // covers those below it, and the longest path covering a request applies.
+  routeTimeouts?: Record<string, number>;
+  // Most operations of one POST /batch evaluated at the same time; results
+  // are still returned in request order. Defaults to 8.
+  batchConcurrency?: number;
+  // Most batch operations evaluated at the same time across all POST /batch
+  // requests. A batch runs with fewer workers when few are free, and gets
+  // 503 when none are. Defaults to 64.
+  maxBatchWorkers?: number;
+  // Largest total size, in bytes, of the request headers; larger requests
+  // are rejected with 431. Defaults to 32 KiB.
+  maxHeaderBytes?: number;
+  // Reject requests whose body is shorter or longer than their
+  // Content-Length with 400, as from a truncated upload. Off by default.
+  checkContentLength?: boolean;
+  // Reject POST bodies not sent as application/json with 415. Off by default
+  // for clients that omit the header.
+  strictContentType?: boolean;
+  // Reject operation requests whose body is missing, null or {} with 400
+  // body_required rather than reporting each operand as missing. Off by
+  // default, when a missing body is 400 empty_body.
+  strictBody?: boolean;
+  // Keys accepted in X-API-Key, and the paths exempt from it. Keys default to
+  // the API_KEYS binding; without any, no key is required.
+  apiKey?: ApiKeyOptions;
+  // Log requests slower than a threshold, with their path, duration and
+  // request ID. Off by default.
+  slowRequests?: SlowRequestOptions;
+  // How much of each request body is logged, and where, while the
+  // LOG_REQUEST_BODIES binding is "true". Defaults to 1 KiB to console.debug.
+  requestBodyLog?: RequestBodyLogOptions;
+  // Headers such as X-Frame-Options added to every response. Defaults to
+  // DEFAULT_SECURITY_HEADERS, plus Cache-Control: no-store on errors.
+  securityHeaders?: SecurityHeadersOptions;
+  // Behind a TLS-terminating proxy, reject or redirect requests whose
+  // X-Forwarded-Proto is not https, and send Strict-Transport-Security. Off
+  // by default.
+  requireHttps?: RequireHttpsOptions;
+  // Applied to the result of every JSON operation response. Results pass
+  // through unchanged by default.
+  resultTransform?: ResultTransform;
+  // Add isInteger to operation responses, true when the result is a whole
+  // number, e.g. for 2.5 + 2.5. Off by default.
+  reportIsInteger?: boolean;
+  // Add isExact to /divide responses, true when the quotient is exactly
+  // a / b with no rounding, e.g. for 10 / 4 but not 10 / 3. Off by default.
+  reportExactDivision?: boolean;
+  // What becomes of an unexpected error, such as a TypeError from a bug in
+  // the service. Defaults to "respond", which reports it as 400
+  // invalid_request; "panic", for development, fails fast with 500 and logs
+  // its stack instead. Validation and domain errors are reported as usual
+  // either way.
+  onError?: ErrorPolicy;
+  // Another calculator instance to compute /add, for chaining instances in
+  // demos and tests. Off by default; every other operation stays local.
+  upstream?: CalculatorClient;
+  // When upstream calls trip the circuit breaker and for how long it fails
+  // them fast. Defaults to 5 consecutive failures and 30 seconds.
+  upstreamBreaker?: CircuitBreakerOptions;
+  // Probes run by GET /readyz. Defaults to a self-test of the arithmetic.
+  readinessChecks?: ReadinessCheck[];
+  // Other calculator instances whose health GET /health/fleet aggregates,
+  // and how long each has to answer. GET /health/fleet is 404 without them.
+  fleet?: FleetOptions;
+  // Size of the GET /history buffer, and whether failed operations are
+  // recorded there too. Keeps the last 100 successful operations by default.
+  history?: HistoryOptions;
+  // Log operations for auditing, optionally only one in every sampleRate
+  // successful ones; failures are always logged. Off by default.
+  audit?: AuditOptions;
+  // Response body encodings selectable with Accept; the first is used when
+  // the client states no preference. Defaults to JSON and MessagePack.
+  encoders?: ResponseEncoder[];
+  // Write JSON bodies as canonical JSON, with sorted keys and no whitespace,
+  // for clients that hash or sign them. Replaces the built-in JSON encoder
+  // wherever it appears in encoders. Off by default.
+  canonicalJson?: boolean;
+  // Renames top-level keys of JSON bodies, e.g. { result: "value" } for a
+  // legacy client expecting {"value": 5}. Keys are unchanged by default.
+  responseKeys?: Record<string, string>;
+  // Writes the result of operation responses rounded to this many
+  // significant digits, from 1 to 17, in every encoding, e.g. 6 for 0.3
+  // rather than 0.30000000000000004. Results are written in full by default.
+  significantDigits?: number;
+  // Where to register the app's shutdown hooks, which empty the result cache
+  // and the idempotency store, for hosts that call lifecycle.shutdown() when
+  // they stop. None are registered by default; Workers never shut down.
+  lifecycle?: Lifecycle;
+}
 
-app.route("/", calculator);
+// path mounted under prefix, which has no trailing slash unless it is "/".
+function underPrefix(prefix: string, path: string): string {
+  return prefix === "/" ? path : prefix + path;
+}
 
-app.notFound((c) => {
-  return c.json({ error: "Not found" }, 404);
-});
+export function createApp(options: AppOptions = {}) {
+  const prefix = (options.prefix ?? "").replace(/\/+$/, "") || "/";
+  const aliases = new Map(
+    Object.entries(options.aliases ?? {}).map(([alias, target]) => [
+      underPrefix(prefix, alias),
+      underPrefix(prefix, target),
+    ])
+  );
+  const trailingSlash = options.trailingSlash ?? "rewrite";
+  // Aliases and trailing slashes are resolved before routing, so such a
+  // request runs through exactly the same middleware and handler as one to
+  // the target path.
+  const app = new Hono<AppEnv>({
+    getPath: (request) => {
+      const url = new URL(request.url);
+      const path =
+        trailingSlash === "rewrite"
+          ? trimTrailingSlash(url.pathname)
+          : url.pathname;
+      return aliases.get(path) ?? path;
+    },
+  });
+  // Built on first use, once every route has been registered.
+  let matchesRoute: ((path: string) => boolean) | undefined;
+  const isRoute = (path: string) => {
+    matchesRoute ??= routeMatcher(app.routes);
+    return matchesRoute(aliases.get(path) ?? path);
+  };
+  // The endpoints that take an operation's JSON body, as opposed to those
+  // with no body, such as /admin/degrade, or a CSV one.
+  const isOperation = routeMatcher(
+    [...calculator.routes, ...batch.routes]
+      .filter((route) => route.method === "POST")
+      .map((route) => ({ path: underPrefix(prefix, route.path) }))
+  );
+  const clock = options.clock ?? systemClock;
+  const base = options.service ?? calculatorService;
+  const metrics = new Metrics(options.latencyBuckets);
+  const proxied = options.upstream
+    ? proxyAdd(
+        base,
+        options.upstream,
+        new CircuitBreaker(clock, options.upstreamBreaker)
+      )
+    : base;
+  const cached = options.cache
+    ? cacheResults(proxied, clock, metrics, options.cache, options.lifecycle)
+    : proxied;
+  const service = options.audit
+    ? auditOperations(cached, clock, options.audit)
+    : cached;
+  const history = new History(clock, options.history?.size);
+  const idempotencyStore = new IdempotencyStore();
+  options.lifecycle?.onShutdown({
+    name: "idempotency",
+    run: () => idempotencyStore.clear(),
+  });
+  const encoders = (options.encoders ?? DEFAULT_ENCODERS)
+    .map((encoder) =>
+      options.canonicalJson && encoder === jsonEncoder
+        ? canonicalJsonEncoder
+        : encoder
+    )
+    .map((encoder) =>
+      options.responseKeys && encoder.contentType === "application/json"
+        ? renameKeys(encoder, options.responseKeys)
+        : encoder
+    )
+    .map((encoder) =>
+      options.significantDigits === undefined
+        ? encoder
+        : withSignificantDigits(encoder, options.significantDigits)
+    );
+  const exemptPaths = (
+    options.apiKey?.exemptPaths ?? DEFAULT_API_KEY_EXEMPT_PATHS
+  ).map((path) => underPrefix(prefix, path));
+  const httpsExemptPaths = (
+    options.requireHttps?.exemptPaths ?? DEFAULT_HTTPS_EXEMPT_PATHS
+  ).map((path) => underPrefix(prefix, path));
+  const timeouts =
+    options.routeTimeouts &&
+    Object.fromEntries(
+      Object.entries(options.routeTimeouts).map(([path, timeoutMs]) => [
+        underPrefix(prefix, path),
+        timeoutMs,
+      ])
+    );
 
-app.onError((err, c) => {
-  console.error("Unhandled error:", err);
-  return c.json({ error: "Internal server error" }, 500);
-});
+  app.use(
+    "*",
+    chain(
+      withVariables({
+        clock,
+        validators: options.validators ?? createDefaultValidators(),
+        operations: options.operations ?? createDefaultOperationRegistry(),
+        service: recordHistory(
+          service,
+          history,
+          options.history?.includeErrors
+        ),
+        metrics,
+        stats: new Stats(clock),
+        history,
+        requestTimeoutMs:
+          options.requestTimeoutMs ?? DEFAULT_REQUEST_TIMEOUT_MS,
+        batchConcurrency: options.batchConcurrency ?? DEFAULT_BATCH_CONCURRENCY,
+        batchWorkers: new Semaphore(
+          options.maxBatchWorkers ?? DEFAULT_MAX_BATCH_WORKERS
+        ),
+        resultTransform: options.resultTransform ?? ((_, result) => result),
+        reportIsInteger: options.reportIsInteger ?? false,
+        reportExactDivision: options.reportExactDivision ?? false,
+        errorPolicy: options.onError ?? "respond",
+        readinessChecks: options.readinessChecks ?? [selfArithmeticCheck],
+        degradedMode: new DegradedMode(),
+        recording: new Recording(),
+        fleet: {
+          nodes: options.fleet?.nodes ?? [],
+          timeoutMs: options.fleet?.timeoutMs ?? DEFAULT_FLEET_TIMEOUT_MS,
+        },
+        encoders,
+        responseKeys: options.responseKeys ?? {},
+      }),
+      ...(timeouts ? [routeTimeouts(timeouts)] : []),
+      recordExchanges(underPrefix(prefix, "/recording")),
+      requestId(),
+      logRequestBodies(options.requestBodyLog),
+      requestLatency(),
+      ...(options.slowRequests ? [logSlowRequests(options.slowRequests)] : []),
+      requestCancellations(),
+      securityHeaders(options.securityHeaders),
+      ...(options.requireHttps
+        ? [
+            requireHttps({
+              ...options.requireHttps,
+              exemptPaths: httpsExemptPaths,
+            }),
+          ]
+        : []),
+      maxHeaderBytes(options.maxHeaderBytes),
+      ...(options.checkContentLength ? [checkContentLength()] : []),
+      ...(trailingSlash === "redirect" ? [redirectTrailingSlash(isRoute)] : []),
+      envelope(),
+      requireApiKey({ keys: options.apiKey?.keys, exemptPaths }),
+      responseDelay(),
+      verifySignature(),
+      protobuf(),
+      ...(options.strictContentType ? [requireJson()] : []),
+      ...(options.strictBody ? [requireBody(isOperation)] : []),
+      etag(),
+      idempotency({ store: idempotencyStore })
+    )
+  );
 
-export default app;
+  app.route(prefix, calculator);
+  app.route(prefix, jsonp);
+  app.route(prefix, csv);
+  app.route(prefix, batch);
+  app.route(prefix, websocket);
+  app.route(prefix, metricsRoutes);
+  app.route(prefix, stats);
+  app.route(prefix, historyRoutes);
+  app.route(prefix, readiness);
+  app.route(prefix, fleet);
+  app.route(prefix, ping);
+  app.route(prefix, schema);
+  app.route(prefix, operations);
+  app.route(prefix, bench);
+  app.route(prefix, admin);
+  app.route(prefix, recording);
+  checkAliases(app.routes, aliases);
+
+  app.notFound((c) => {
+    return errorResponse(c, 404, "not_found", "Not found");
+  });
+
+  app.onError((err, c) => {
+    console.error("Unhandled error:", err.stack ?? err);
+    return errorResponse(c, 500, "internal_error", "Internal server error");
+  });
+
+  return app;
+}
+
+export default createApp();
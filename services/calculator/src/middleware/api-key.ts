@@ -0,0 +1,77 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import { sha256Hex } from "./digest";
+import type { AppEnv } from "../types";
+
+export const API_KEY_HEADER = "X-API-Key";
+
+// Probed by infrastructure that has no key.
+export const DEFAULT_API_KEY_EXEMPT_PATHS = ["/health", "/readyz"];
+
+export interface ApiKeyOptions {
+  // Accepted keys. Defaults to the comma-separated API_KEYS binding. No key
+  // is required when there are none.
+  keys?: string[];
+  // Paths, relative to the app's prefix, served without a key. Defaults to
+  // DEFAULT_API_KEY_EXEMPT_PATHS.
+  exemptPaths?: string[];
+}
+
+// Compares key against every accepted key without stopping at a match or a
+// differing character. Comparing SHA-256 digests keeps the work independent
+// of the keys' lengths too, so timing reveals nothing about them.
+async function isAcceptedKey(
+  key: string,
+  accepted: readonly string[]
+): Promise<boolean> {
+  const presented = await sha256Hex(key);
+  let matched = false;
+  for (const candidate of accepted) {
+    const expected = await sha256Hex(candidate);
+    let diff = 0;
+    for (let i = 0; i < expected.length; i++) {
+      diff |= presented.charCodeAt(i) ^ expected.charCodeAt(i);
+    }
+    if (diff === 0) {
+      matched = true;
+    }
+  }
+  return matched;
+}
+
+function parseKeys(binding: string | undefined): string[] {
+  return (binding ?? "")
+    .split(",")
+    .map((key) => key.trim())
+    .filter((key) => key !== "");
+}
+
+// Requires an X-API-Key header holding one of the accepted keys: 401 when it
+// is missing, 403 when it is not accepted. Exempt paths, by default the
+// health checks, are always served.
+export function requireApiKey(
+  options: ApiKeyOptions = {}
+): MiddlewareHandler<AppEnv> {
+  const exempt = new Set(options.exemptPaths ?? DEFAULT_API_KEY_EXEMPT_PATHS);
+
+  return async (c, next) => {
+    const keys = options.keys ?? parseKeys(c.env?.API_KEYS);
+    if (keys.length === 0 || exempt.has(c.req.path)) {
+      return next();
+    }
+
+    const key = c.req.header(API_KEY_HEADER);
+    if (key === undefined || key === "") {
+      return errorResponse(
+        c,
+        401,
+        "missing_api_key",
+        "Missing X-API-Key header"
+      );
+    }
+    if (!(await isAcceptedKey(key, keys))) {
+      return errorResponse(c, 403, "invalid_api_key", "Invalid API key");
+    }
+    await next();
+  };
+}
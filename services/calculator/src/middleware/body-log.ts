@@ -0,0 +1,83 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+// Bodies longer than this are cut short in the log by default.
+export const DEFAULT_BODY_LOG_BYTES = 1024;
+
+// The raw body of one request, as received.
+export interface RequestBodyEntry {
+  level: "debug";
+  message: "request body";
+  method: string;
+  path: string;
+  requestId: string;
+  // Decoded as UTF-8; a character split by truncation shows as U+FFFD.
+  body: string;
+  // Whether body holds only the first maxBytes of a longer body.
+  truncated: boolean;
+}
+
+export interface RequestBodyLogOptions {
+  // Most bytes of each body logged. Defaults to DEFAULT_BODY_LOG_BYTES.
+  maxBytes?: number;
+  // Receives each entry. Defaults to console.debug.
+  log?: (entry: RequestBodyEntry) => void;
+}
+
+// Reads at most maxBytes from the start of stream, then stops it.
+async function readPrefix(
+  stream: ReadableStream<Uint8Array>,
+  maxBytes: number
+): Promise<{ bytes: Uint8Array; truncated: boolean }> {
+  const reader = stream.getReader();
+  const bytes = new Uint8Array(maxBytes);
+  let length = 0;
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) {
+        return { bytes: bytes.subarray(0, length), truncated: false };
+      }
+      const taken = Math.min(value.length, maxBytes - length);
+      bytes.set(value.subarray(0, taken), length);
+      length += taken;
+      if (taken < value.length) {
+        return { bytes, truncated: true };
+      }
+    }
+  } finally {
+    // Not awaited: cancelling one branch of a cloned body settles only once
+    // the other branch, the handler's, is finished with too.
+    reader.cancel().catch(() => {});
+  }
+}
+
+// For diagnosing malformed-body reports: while the LOG_REQUEST_BODIES binding
+// is "true", the raw body of every request that has one is logged, cut to
+// maxBytes so large payloads stay out of the logs, before any handler reads
+// it. The log reads a copy, so the handler still gets the whole body.
+// Otherwise requests pass through untouched.
+export function logRequestBodies(
+  options: RequestBodyLogOptions = {}
+): MiddlewareHandler<AppEnv> {
+  const maxBytes = options.maxBytes ?? DEFAULT_BODY_LOG_BYTES;
+  const log = options.log ?? ((entry) => console.debug(entry));
+  return async (c, next) => {
+    if (c.env?.LOG_REQUEST_BODIES !== "true" || c.req.raw.body === null) {
+      return next();
+    }
+
+    const copy = c.req.raw.clone().body as ReadableStream<Uint8Array>;
+    const { bytes, truncated } = await readPrefix(copy, maxBytes);
+    log({
+      level: "debug",
+      message: "request body",
+      method: c.req.method,
+      path: c.req.path,
+      requestId: c.var.requestId,
+      body: new TextDecoder().decode(bytes),
+      truncated,
+    });
+    await next();
+  };
+}
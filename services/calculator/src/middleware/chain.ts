@@ -0,0 +1,24 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+// Composes middleware into one, run in the order given: the first listed is
+// outermost, seeing the request first and the response last. A middleware
+// that returns a response without calling next ends the chain there.
+export function chain(
+  ...middlewares: MiddlewareHandler<AppEnv>[]
+): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const dispatch = async (index: number): Promise<void> => {
+      const middleware = middlewares[index];
+      if (middleware === undefined) {
+        await next();
+        return;
+      }
+      const response = await middleware(c, () => dispatch(index + 1));
+      if (response) {
+        c.res = response;
+      }
+    };
+    await dispatch(0);
+  };
+}
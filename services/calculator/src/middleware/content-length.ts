@@ -0,0 +1,44 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import type { AppEnv } from "../types";
+
+const DIGITS = /^\d+$/;
+
+// Rejects requests whose body is not as long as their Content-Length says
+// with 400 content_length_mismatch, so a client whose upload was cut short on
+// the way is told so rather than getting a confusing parse error, or worse,
+// a result computed from part of its request. Requests without the header,
+// such as chunked uploads, and those with Transfer-Encoding, which overrides
+// it, are passed through unchecked. The body is counted from a copy, so the
+// handler still reads it.
+export function checkContentLength(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const declared = c.req.header("Content-Length");
+    if (declared === undefined || c.req.header("Transfer-Encoding")) {
+      return next();
+    }
+    if (!DIGITS.test(declared.trim())) {
+      return errorResponse(
+        c,
+        400,
+        "content_length_mismatch",
+        "Invalid Content-Length"
+      );
+    }
+
+    const expected = Number(declared);
+    const received =
+      c.req.raw.body === null
+        ? 0
+        : (await c.req.raw.clone().arrayBuffer()).byteLength;
+    if (received !== expected) {
+      return errorResponse(
+        c,
+        400,
+        "content_length_mismatch",
+        `Content-Length is ${expected} but the body is ${received} bytes`
+      );
+    }
+    await next();
+  };
+}
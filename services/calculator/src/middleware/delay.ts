@@ -0,0 +1,54 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse, validationErrorResponse } from "../routes/response";
+import { sleep } from "../services/deadline";
+import type { AppEnv } from "../types";
+
+// Longest delay ?delay= may ask for.
+export const MAX_DELAY_MS = 30_000;
+
+// Parses a duration such as "200ms" or "1.5s" into milliseconds. Returns
+// undefined if the text is not a duration.
+export function parseDelay(text: string): number | undefined {
+  const match = /^(\d+(?:\.\d+)?)(ms|s)$/.exec(text);
+  if (match === null) {
+    return undefined;
+  }
+  const amount = Number(match[1]);
+  return match[2] === "s" ? amount * 1000 : amount;
+}
+
+// Development aid for testing client timeouts: with ?delay=200ms the request
+// waits that long before it is handled. Unless the ENABLE_DELAY binding is
+// "true" the parameter is ignored, so it cannot be used to tie up production
+// instances. A client that disconnects during the wait stops it, and the
+// request is never handled.
+export function responseDelay(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const text = c.req.query("delay");
+    if (c.env?.ENABLE_DELAY !== "true" || text === undefined) {
+      return next();
+    }
+
+    const ms = parseDelay(text);
+    if (ms === undefined || ms > MAX_DELAY_MS) {
+      return validationErrorResponse(c, [
+        {
+          field: "delay",
+          message: `must be a duration such as 200ms, up to ${MAX_DELAY_MS}ms`,
+        },
+      ]);
+    }
+
+    try {
+      await sleep(ms, c.req.raw.signal);
+    } catch {
+      return errorResponse(
+        c,
+        503,
+        "request_cancelled",
+        "Request cancelled during delay"
+      );
+    }
+    await next();
+  };
+}
@@ -0,0 +1,19 @@
+// Shared rather than built per call; encoding keeps no state between calls.
+const textEncoder = new TextEncoder();
+
+// Two hex digits for each byte value.
+const HEX_BYTES = Array.from({ length: 256 }, (_, byte) =>
+  byte.toString(16).padStart(2, "0")
+);
+
+// Hex-encoded SHA-256 of the UTF-8 encoding of text.
+export async function sha256Hex(text: string): Promise<string> {
+  const digest = new Uint8Array(
+    await crypto.subtle.digest("SHA-256", textEncoder.encode(text))
+  );
+  let hex = "";
+  for (const byte of digest) {
+    hex += HEX_BYTES[byte];
+  }
+  return hex;
+}
@@ -0,0 +1,42 @@
+import type { MiddlewareHandler } from "hono";
+import { jsonEncoder } from "../services/encoders";
+import type { AppEnv, Envelope, ErrorResponse } from "../types";
+
+// With ?envelope=true, wraps JSON responses, successes and errors alike, in
+// an Envelope carrying the request's duration and id. Other requests, and
+// responses that are not JSON, pass through unchanged. The envelope is
+// written by the registered JSON encoder, so canonical JSON stays canonical.
+export function envelope(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    if (c.req.query("envelope") !== "true") {
+      return next();
+    }
+
+    const start = c.var.clock.now().getTime();
+    await next();
+
+    const contentType = c.res.headers.get("Content-Type") ?? "";
+    if (!contentType.includes("application/json")) {
+      return;
+    }
+    const body: unknown = await c.res.json();
+    const meta = {
+      durationMs: c.var.clock.now().getTime() - start,
+      requestId: c.var.requestId,
+    };
+    const wrapped: Envelope = c.res.ok
+      ? { data: body, meta }
+      : { error: body as ErrorResponse, meta };
+
+    const encoder =
+      c.var.encoders?.find(
+        (candidate) => candidate.contentType === "application/json"
+      ) ?? jsonEncoder;
+    const headers = new Headers(c.res.headers);
+    headers.delete("Content-Length");
+    c.res = new Response(encoder.encode(wrapped), {
+      status: c.res.status,
+      headers,
+    });
+  };
+}
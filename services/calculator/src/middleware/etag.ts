@@ -0,0 +1,41 @@
+import type { MiddlewareHandler } from "hono";
+import { responseText } from "../routes/response";
+import { sha256Hex } from "./digest";
+import type { AppEnv } from "../types";
+
+// True if an If-None-Match header value matches the entity tag. Comparison
+// is weak, as RFC 9110 requires for If-None-Match.
+export function matchesEtag(ifNoneMatch: string, etag: string): boolean {
+  const opaque = (tag: string) => tag.trim().replace(/^W\//, "");
+  return ifNoneMatch
+    .split(",")
+    .some((tag) => tag.trim() === "*" || opaque(tag) === opaque(etag));
+}
+
+// Tags successful JSON responses with an ETag derived from the request and
+// the result, and answers a matching If-None-Match with 304. Results are
+// deterministic, so the same request always yields the same tag.
+export function etag(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    await next();
+
+    const contentType = c.res.headers.get("Content-Type") ?? "";
+    if (c.res.status !== 200 || !contentType.includes("application/json")) {
+      return;
+    }
+
+    const url = new URL(c.req.url);
+    const request = await c.req.text();
+    const result = await responseText(c.res);
+    const tag = `"${await sha256Hex(
+      `${c.req.method} ${url.pathname}${url.search}\n${request}\n${result}`
+    )}"`;
+
+    const ifNoneMatch = c.req.header("If-None-Match");
+    if (ifNoneMatch !== undefined && matchesEtag(ifNoneMatch, tag)) {
+      c.res = new Response(null, { status: 304, headers: { ETag: tag } });
+      return;
+    }
+    c.header("ETag", tag);
+  };
+}
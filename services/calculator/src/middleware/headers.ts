@@ -0,0 +1,37 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import type { AppEnv } from "../types";
+
+// Largest total size of request headers accepted by default.
+export const DEFAULT_MAX_HEADER_BYTES = 32 * 1024;
+
+// Size of headers as sent over HTTP/1.1: "name: value\r\n" per header, with
+// names and values measured in UTF-8 bytes.
+export function headerBytes(headers: Headers): number {
+  const encoder = new TextEncoder();
+  let total = 0;
+  headers.forEach((value, name) => {
+    total += encoder.encode(name).length + encoder.encode(value).length + 4;
+  });
+  return total;
+}
+
+// Rejects requests whose headers add up to more than maxBytes with 431
+// Request Header Fields Too Large, before any other work is done for them.
+// Slow header delivery never reaches the Worker: the Cloudflare edge reads
+// the complete request head before invoking it and enforces its own timeout.
+export function maxHeaderBytes(
+  maxBytes: number = DEFAULT_MAX_HEADER_BYTES
+): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    if (headerBytes(c.req.raw.headers) > maxBytes) {
+      return errorResponse(
+        c,
+        431,
+        "headers_too_large",
+        `Request headers exceed ${maxBytes} bytes`
+      );
+    }
+    await next();
+  };
+}
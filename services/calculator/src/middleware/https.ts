@@ -0,0 +1,64 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import type { AppEnv } from "../types";
+
+// One year, covering subdomains, as commonly recommended.
+export const DEFAULT_HSTS = "max-age=31536000; includeSubDomains";
+
+// Load balancers often probe health checks over plain HTTP from inside the
+// network, without X-Forwarded-Proto.
+export const DEFAULT_HTTPS_EXEMPT_PATHS = ["/health", "/readyz"];
+
+export interface RequireHttpsOptions {
+  // What a request that reached the proxy over plain HTTP gets: "reject"
+  // answers 400 insecure_request, "redirect" answers 308 to the https URL.
+  // Defaults to "reject".
+  insecure?: "reject" | "redirect";
+  // Strict-Transport-Security value set on HTTPS responses. Defaults to
+  // DEFAULT_HSTS.
+  hsts?: string;
+  // Paths served over either scheme. Defaults to DEFAULT_HTTPS_EXEMPT_PATHS.
+  exemptPaths?: string[];
+}
+
+// The scheme the client used, as reported by a TLS-terminating proxy. With
+// a chain of proxies the first entry is the one the client connected to.
+function forwardedProto(header: string | undefined): string | undefined {
+  return header?.split(",")[0].trim().toLowerCase();
+}
+
+// Enforces HTTPS behind a proxy that terminates TLS and forwards plain HTTP,
+// so the request's own URL says nothing about what the client used. A
+// request is HTTPS only if X-Forwarded-Proto says so; a missing header counts
+// as HTTP. Exempt paths are served either way. HTTPS responses get
+// Strict-Transport-Security so browsers keep to HTTPS; browsers ignore it
+// over HTTP, so other responses go without.
+export function requireHttps(
+  options: RequireHttpsOptions = {}
+): MiddlewareHandler<AppEnv> {
+  const insecure = options.insecure ?? "reject";
+  const hsts = options.hsts ?? DEFAULT_HSTS;
+  const exempt = new Set(options.exemptPaths ?? DEFAULT_HTTPS_EXEMPT_PATHS);
+
+  return async (c, next) => {
+    const proto = forwardedProto(c.req.header("X-Forwarded-Proto"));
+    if (proto !== "https") {
+      if (exempt.has(c.req.path)) {
+        return next();
+      }
+      if (insecure === "redirect") {
+        const url = new URL(c.req.url);
+        url.protocol = "https:";
+        // 308 makes clients repeat the method and body over HTTPS.
+        return c.redirect(url.toString(), 308);
+      }
+      return errorResponse(c, 400, "insecure_request", "HTTPS required");
+    }
+
+    await next();
+
+    if (c.res.status !== 101) {
+      c.res.headers.set("Strict-Transport-Security", hsts);
+    }
+  };
+}
@@ -0,0 +1,167 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import { sha256Hex } from "./digest";
+import type { AppEnv } from "../types";
+
+export const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key";
+export const IDEMPOTENT_REPLAYED_HEADER = "Idempotent-Replayed";
+
+const DEFAULT_TTL_MS = 24 * 60 * 60 * 1000;
+export const DEFAULT_IDEMPOTENCY_MAX_ENTRIES = 10000;
+const MAX_KEY_LENGTH = 255;
+
+interface StoredResponse {
+  status: number;
+  headers: [string, string][];
+  // Kept as bytes so binary encodings such as MessagePack replay intact.
+  body: ArrayBuffer;
+}
+
+interface IdempotencyEntry {
+  fingerprint: string;
+  expiresAt: number;
+  response: Promise<StoredResponse>;
+}
+
+// In-memory store of first responses keyed by Idempotency-Key. Entries are
+// registered before the handler runs, so a concurrent retry with the same key
+// waits on the in-flight response instead of executing the handler again.
+// The store lives in isolate memory and is not shared across isolates, so
+// it stays bounded: expired entries are swept whenever one is added, and
+// beyond maxEntries the oldest is evicted, after which its key runs the
+// handler again.
+export class IdempotencyStore {
+  private entries = new Map<string, IdempotencyEntry>();
+  private readonly maxEntries: number;
+
+  constructor(maxEntries = DEFAULT_IDEMPOTENCY_MAX_ENTRIES) {
+    if (!Number.isInteger(maxEntries) || maxEntries < 1) {
+      throw new RangeError("maxEntries must be a positive integer");
+    }
+    this.maxEntries = maxEntries;
+  }
+
+  get(key: string, now: number): IdempotencyEntry | undefined {
+    const entry = this.entries.get(key);
+    if (entry && entry.expiresAt <= now) {
+      this.entries.delete(key);
+      return undefined;
+    }
+    return entry;
+  }
+
+  set(key: string, entry: IdempotencyEntry, now: number): void {
+    this.entries.delete(key);
+    // Maps iterate in insertion order and every entry of a store usually has
+    // the same TTL, so the expired ones are at the front; one that outlives
+    // those after it is swept on a later call or when it is looked up.
+    for (const [other, { expiresAt }] of this.entries) {
+      if (expiresAt > now) {
+        break;
+      }
+      this.entries.delete(other);
+    }
+    if (this.entries.size >= this.maxEntries) {
+      this.entries.delete(this.entries.keys().next().value as string);
+    }
+    this.entries.set(key, entry);
+  }
+
+  delete(key: string): void {
+    this.entries.delete(key);
+  }
+
+  // Forgets every key, e.g. at shutdown.
+  clear(): void {
+    this.entries.clear();
+  }
+
+  get size(): number {
+    return this.entries.size;
+  }
+}
+
+export interface IdempotencyOptions {
+  ttlMs?: number;
+  // Keys remembered at most, when store is not given. Defaults to
+  // DEFAULT_IDEMPOTENCY_MAX_ENTRIES.
+  maxEntries?: number;
+  store?: IdempotencyStore;
+}
+
+function fingerprint(method: string, path: string, body: string) {
+  return sha256Hex(`${method} ${path}\n${body}`);
+}
+
+function replay(stored: StoredResponse): Response {
+  const response = new Response(stored.body, {
+    status: stored.status,
+    headers: stored.headers,
+  });
+  response.headers.set(IDEMPOTENT_REPLAYED_HEADER, "true");
+  return response;
+}
+
+export function idempotency(
+  options: IdempotencyOptions = {}
+): MiddlewareHandler<AppEnv> {
+  const ttlMs = options.ttlMs ?? DEFAULT_TTL_MS;
+  const store = options.store ?? new IdempotencyStore(options.maxEntries);
+
+  return async (c, next) => {
+    const key = c.req.header(IDEMPOTENCY_KEY_HEADER);
+    if (c.req.method !== "POST" || key === undefined) {
+      return next();
+    }
+
+    if (key.length === 0 || key.length > MAX_KEY_LENGTH) {
+      return errorResponse(
+        c,
+        400,
+        "invalid_idempotency_key",
+        "Invalid Idempotency-Key"
+      );
+    }
+
+    const body = await c.req.text();
+    // The query is part of the request: ?exact=true or ?envelope=true on the
+    // same body asks for a different response.
+    const target = c.req.path + new URL(c.req.url).search;
+    const print = await fingerprint(c.req.method, target, body);
+    const now = c.var.clock.now().getTime();
+
+    const existing = store.get(key, now);
+    if (existing) {
+      if (existing.fingerprint !== print) {
+        return errorResponse(
+          c,
+          409,
+          "idempotency_conflict",
+          "Idempotency-Key reused with a different request"
+        );
+      }
+      return replay(await existing.response);
+    }
+
+    let resolve!: (stored: StoredResponse) => void;
+    const response = new Promise<StoredResponse>((r) => (resolve = r));
+    store.set(
+      key,
+      { fingerprint: print, expiresAt: now + ttlMs, response },
+      now
+    );
+
+    await next();
+
+    const stored: StoredResponse = {
+      status: c.res.status,
+      headers: [...c.res.headers],
+      body: await c.res.clone().arrayBuffer(),
+    };
+    // Server errors are not remembered so the client can retry them.
+    if (stored.status >= 500) {
+      store.delete(key);
+    }
+    resolve(stored);
+  };
+}
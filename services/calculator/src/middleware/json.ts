@@ -0,0 +1,76 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import type { AppEnv } from "../types";
+
+export interface MediaType {
+  // Lower-cased "type/subtype", e.g. "application/json".
+  type: string;
+  // Lower-cased parameter names mapped to their (unquoted) values.
+  parameters: Record<string, string>;
+}
+
+const TOKEN = /^[!#$%&'*+.^_`|~0-9A-Za-z-]+$/;
+
+// Parses a Content-Type header value into its media type and parameters, in
+// the manner of RFC 9110 section 8.3.1. Returns undefined if the value is
+// malformed.
+export function parseMediaType(value: string): MediaType | undefined {
+  const [essence, ...rest] = value.split(";");
+  const [type, subtype, ...extra] = essence.trim().split("/");
+  if (
+    extra.length > 0 ||
+    type === undefined ||
+    subtype === undefined ||
+    !TOKEN.test(type) ||
+    !TOKEN.test(subtype)
+  ) {
+    return undefined;
+  }
+
+  const parameters: Record<string, string> = {};
+  for (const parameter of rest) {
+    if (parameter.trim() === "") {
+      continue;
+    }
+    const separator = parameter.indexOf("=");
+    if (separator === -1) {
+      return undefined;
+    }
+    const name = parameter.slice(0, separator).trim().toLowerCase();
+    let text = parameter.slice(separator + 1).trim();
+    if (text.length >= 2 && text.startsWith('"') && text.endsWith('"')) {
+      text = text.slice(1, -1).replace(/\\(.)/g, "$1");
+    }
+    if (!TOKEN.test(name)) {
+      return undefined;
+    }
+    parameters[name] = text;
+  }
+
+  return { type: `${type}/${subtype}`.toLowerCase(), parameters };
+}
+
+// Rejects POST requests whose Content-Type is not application/json with 415.
+// Bulk endpoints under .../csv take text/csv instead. Parameters such as
+// charset are accepted whatever their value.
+export function requireJson(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    if (c.req.method !== "POST") {
+      return next();
+    }
+    const expected = c.req.path.endsWith("/csv")
+      ? "text/csv"
+      : "application/json";
+    const header = c.req.header("Content-Type");
+    const mediaType = header === undefined ? undefined : parseMediaType(header);
+    if (mediaType?.type !== expected) {
+      return errorResponse(
+        c,
+        415,
+        "unsupported_media_type",
+        `Content-Type must be ${expected}`
+      );
+    }
+    await next();
+  };
+}
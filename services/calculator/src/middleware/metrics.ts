@@ -0,0 +1,25 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+// Records how long the downstream handlers took to produce a response. The
+// timer stops when the handler returns, before the body is streamed, so slow
+// clients reading the response do not skew the measurement.
+export function requestLatency(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const start = c.var.clock.now().getTime();
+    await next();
+    const elapsed = c.var.clock.now().getTime() - start;
+    c.var.metrics.requestDuration.observe(elapsed / 1000);
+  };
+}
+
+// Counts requests whose client disconnected before the downstream handlers
+// produced a response, as reported by the request's abort signal.
+export function requestCancellations(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    await next();
+    if (c.req.raw.signal.aborted) {
+      c.var.metrics.requestsCancelled.inc();
+    }
+  };
+}
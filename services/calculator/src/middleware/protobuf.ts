@@ -0,0 +1,93 @@
+import type { MiddlewareHandler } from "hono";
+import { errorBody } from "../routes/response";
+import { restoreKeys } from "../services/encoders";
+import {
+  decodeOperationRequest,
+  encodeErrorResponse,
+  encodeOperationResponse,
+  ProtobufError,
+} from "../services/protobuf";
+import { parseMediaType } from "./json";
+import type { AppEnv, ErrorResponse, OperationResponse } from "../types";
+
+export const PROTOBUF_CONTENT_TYPE = "application/x-protobuf";
+
+function protobufResponse(
+  body: Uint8Array,
+  status: number,
+  init?: Headers
+): Response {
+  const headers = new Headers(init);
+  headers.set("Content-Type", PROTOBUF_CONTENT_TYPE);
+  headers.delete("Content-Length");
+  return new Response(body.buffer as ArrayBuffer, { status, headers });
+}
+
+// Re-encodes a JSON reply as the matching calculator.proto message, or
+// returns undefined when it has none.
+function encodeReply(body: unknown): Uint8Array | undefined {
+  if (typeof body !== "object" || body === null) {
+    return undefined;
+  }
+  if (typeof (body as OperationResponse).result === "number") {
+    return encodeOperationResponse(body as OperationResponse);
+  }
+  if (typeof (body as ErrorResponse).code === "string") {
+    return encodeErrorResponse(body as ErrorResponse);
+  }
+  return undefined;
+}
+
+// Accepts POST bodies sent as application/x-protobuf: the body is decoded as
+// an OperationRequest and handed on as the equivalent JSON, so handlers need
+// no changes, and the JSON reply is encoded back as an OperationResponse or
+// ErrorResponse. Replies with no protobuf message, such as /divmod's, stay
+// JSON. Keys renamed by responseKeys are restored first, since the messages
+// have fixed fields. Requests in any other format pass through unchanged.
+export function protobuf(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const header = c.req.header("Content-Type");
+    const mediaType = header === undefined ? undefined : parseMediaType(header);
+    if (c.req.method !== "POST" || mediaType?.type !== PROTOBUF_CONTENT_TYPE) {
+      return next();
+    }
+
+    let body: string;
+    try {
+      const bytes = new Uint8Array(await c.req.arrayBuffer());
+      body = JSON.stringify(decodeOperationRequest(bytes));
+    } catch (error) {
+      if (!(error instanceof ProtobufError)) {
+        throw error;
+      }
+      const message = `Malformed protobuf: ${error.message}`;
+      const reply = errorBody(c, "malformed_protobuf", message);
+      return protobufResponse(encodeErrorResponse(reply), 400);
+    }
+    const headers = new Headers(c.req.raw.headers);
+    headers.set("Content-Type", "application/json");
+    headers.delete("Content-Length");
+    c.req.raw = new Request(c.req.url, {
+      method: "POST",
+      headers,
+      body,
+      signal: c.req.raw.signal,
+    });
+    // Hono answers later reads from its cache of the body, which holds the
+    // protobuf bytes once they have been read, here or by verifySignature.
+    c.req.bodyCache = {};
+
+    await next();
+
+    const contentType = c.res.headers.get("Content-Type") ?? "";
+    if (!contentType.includes("application/json")) {
+      return;
+    }
+    const encoded = encodeReply(
+      restoreKeys(await c.res.clone().json(), c.var.responseKeys)
+    );
+    if (encoded !== undefined) {
+      c.res = protobufResponse(encoded, c.res.status, c.res.headers);
+    }
+  };
+}
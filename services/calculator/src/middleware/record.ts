@@ -0,0 +1,20 @@
+import type { MiddlewareHandler } from "hono";
+import { recordRequest, recordResponse } from "../services/recording";
+import type { AppEnv } from "../types";
+
+// For integration tests: while the RECORD_REQUESTS binding is "true", every
+// request and the response sent for it are added to c.var.recording, to be
+// downloaded from GET /recording and replayed later with replay(). Requests
+// to ownPath, the recording itself, are not recorded. Otherwise requests
+// pass through untouched.
+export function recordExchanges(ownPath: string): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    if (c.env?.RECORD_REQUESTS !== "true" || c.req.path === ownPath) {
+      return next();
+    }
+
+    const request = await recordRequest(c.req.raw);
+    await next();
+    c.var.recording.record({ request, response: await recordResponse(c.res) });
+  };
+}
@@ -0,0 +1,35 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+export const REQUEST_ID_HEADER = "X-Request-Id";
+
+const MAX_REQUEST_ID_LENGTH = 128;
+
+// Letters, digits and ._:- only, so the id is safe to log and echo.
+const REQUEST_ID_PATTERN = /^[\w.:-]+$/;
+
+export function isValidRequestId(id: string): boolean {
+  return id.length <= MAX_REQUEST_ID_LENGTH && REQUEST_ID_PATTERN.test(id);
+}
+
+// Assigns each request an id in c.var.requestId and echoes it in the
+// X-Request-Id response header. An id set by an upstream gateway is reused
+// when valid, so traces line up across services; otherwise a UUID is
+// generated.
+export function requestId(): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const incoming = c.req.header(REQUEST_ID_HEADER);
+    const id =
+      incoming !== undefined && isValidRequestId(incoming)
+        ? incoming
+        : crypto.randomUUID();
+    c.set("requestId", id);
+
+    await next();
+
+    // WebSocket upgrade responses are left as the runtime built them.
+    if (c.res.status !== 101) {
+      c.res.headers.set(REQUEST_ID_HEADER, id);
+    }
+  };
+}
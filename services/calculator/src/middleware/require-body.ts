@@ -0,0 +1,44 @@
+import type { MiddlewareHandler } from "hono";
+import { errorResponse } from "../routes/response";
+import type { AppEnv } from "../types";
+
+// Whether text stands for no request at all: blank, JSON null, or an object
+// without a single field. Anything else is left to the handler, which
+// reports invalid JSON as malformed_json and a wrong shape as field errors.
+function isAbsent(text: string): boolean {
+  if (text.trim() === "") {
+    return true;
+  }
+  let body: unknown;
+  try {
+    body = JSON.parse(text);
+  } catch {
+    return false;
+  }
+  return (
+    body === null ||
+    (typeof body === "object" &&
+      !Array.isArray(body) &&
+      Object.keys(body).length === 0)
+  );
+}
+
+// Rejects POST requests to operation endpoints, as told by isOperation,
+// whose body is missing, null or {} with 400 body_required, for clients that
+// post nothing and expect defaults. Operations have no defaults, so such a
+// request is a mistake, and this says so in one error rather than a field
+// error per operand. Hono caches the body, so the handler can still read
+// it, as can verifySignature before this.
+export function requireBody(
+  isOperation: (path: string) => boolean
+): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    if (c.req.method !== "POST" || !isOperation(c.req.path)) {
+      return next();
+    }
+    if (isAbsent(await c.req.text())) {
+      return errorResponse(c, 400, "body_required", "request body required");
+    }
+    await next();
+  };
+}
@@ -0,0 +1,46 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+export interface SecurityHeadersOptions {
+  // Headers set on every response.
+  headers?: Record<string, string>;
+  // Headers additionally set on 4xx and 5xx responses.
+  errorHeaders?: Record<string, string>;
+}
+
+export const DEFAULT_SECURITY_HEADERS: Record<string, string> = {
+  "X-Content-Type-Options": "nosniff",
+  "X-Frame-Options": "DENY",
+};
+
+export const DEFAULT_ERROR_SECURITY_HEADERS: Record<string, string> = {
+  "Cache-Control": "no-store",
+};
+
+// Adds the configured headers to each response. A header the handler already
+// set, such as Content-Type, is left alone. WebSocket upgrades are skipped.
+export function securityHeaders(
+  options: SecurityHeadersOptions = {}
+): MiddlewareHandler<AppEnv> {
+  const headers = options.headers ?? DEFAULT_SECURITY_HEADERS;
+  const errorHeaders = options.errorHeaders ?? DEFAULT_ERROR_SECURITY_HEADERS;
+
+  return async (c, next) => {
+    await next();
+
+    if (c.res.status === 101) {
+      return;
+    }
+    const set = (values: Record<string, string>) => {
+      for (const [name, value] of Object.entries(values)) {
+        if (!c.res.headers.has(name)) {
+          c.res.headers.set(name, value);
+        }
+      }
+    };
+    set(headers);
+    if (c.res.status >= 400) {
+      set(errorHeaders);
+    }
+  };
+}
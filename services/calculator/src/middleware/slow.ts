@@ -0,0 +1,47 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+// One request that took longer than the threshold.
+export interface SlowRequestEntry {
+  level: "warn";
+  message: "slow request";
+  method: string;
+  path: string;
+  status: number;
+  durationMs: number;
+  requestId: string;
+}
+
+export interface SlowRequestOptions {
+  // Requests taking longer than this many milliseconds are logged.
+  thresholdMs: number;
+  // Receives each entry. Defaults to console.warn, which Workers Logs
+  // record as a structured warning.
+  log?: (entry: SlowRequestEntry) => void;
+}
+
+// Logs requests whose handlers took longer than the threshold, timed as
+// requestLatency times them, so outliers show up without tracing every
+// request. Faster requests are not logged.
+export function logSlowRequests(
+  options: SlowRequestOptions
+): MiddlewareHandler<AppEnv> {
+  const log = options.log ?? ((entry) => console.warn(entry));
+  return async (c, next) => {
+    const start = c.var.clock.now().getTime();
+    await next();
+    const durationMs = c.var.clock.now().getTime() - start;
+    if (durationMs <= options.thresholdMs) {
+      return;
+    }
+    log({
+      level: "warn",
+      message: "slow request",
+      method: c.req.method,
+      path: c.req.path,
+      status: c.res.status,
+      durationMs,
+      requestId: c.var.requestId,
+    });
+  };
+}
@@ -0,0 +1,34 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+// Whether key covers path: the same path, or one of its ancestors, so "/stats"
+// covers "/stats/summary" but not "/statsx".
+function covers(key: string, path: string): boolean {
+  return path === key || path.startsWith(key.endsWith("/") ? key : key + "/");
+}
+
+// Sets c.var.requestTimeoutMs from timeouts, a map of paths to milliseconds,
+// so that slow routes can be given longer than the global timeout, or quick
+// ones less. A key applies to its path and every path below it; where
+// several apply, the longest wins, and paths no key covers keep the global
+// timeout.
+export function routeTimeouts(
+  timeouts: Record<string, number>
+): MiddlewareHandler<AppEnv> {
+  for (const [path, timeoutMs] of Object.entries(timeouts)) {
+    if (!Number.isFinite(timeoutMs) || timeoutMs <= 0) {
+      throw new RangeError(`timeout for ${path} must be a positive number`);
+    }
+  }
+  // Longest first, so the first key that covers a path is the most specific.
+  const entries = Object.entries(timeouts).sort(
+    ([a], [b]) => b.length - a.length
+  );
+  return async (c, next) => {
+    const entry = entries.find(([key]) => covers(key, c.req.path));
+    if (entry !== undefined) {
+      c.set("requestTimeoutMs", entry[1]);
+    }
+    await next();
+  };
+}
@@ -0,0 +1,55 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+// How a request path ending in a slash, such as /add/, is handled:
+// "rewrite" routes it as /add, "redirect" answers 308 to /add, and "strict"
+// routes it as written, so it is usually not found.
+export type TrailingSlashMode = "rewrite" | "redirect" | "strict";
+
+interface RegisteredRoute {
+  path: string;
+}
+
+// path without trailing slashes; the root stays "/".
+export function trimTrailingSlash(path: string): string {
+  return path.replace(/\/+$/, "") || "/";
+}
+
+// Returns whether a path is served by one of routes, whose paths may have
+// parameter segments such as /:op/csv. Routes registered for every path, as
+// middleware is, are ignored.
+export function routeMatcher(
+  routes: readonly RegisteredRoute[]
+): (path: string) => boolean {
+  const patterns = routes
+    .filter((route) => !route.path.includes("*"))
+    .map((route) => {
+      const segments = route.path
+        .split("/")
+        .map((segment) =>
+          segment.startsWith(":")
+            ? "[^/]+"
+            : segment.replace(/[.+?^${}()|[\]\\]/g, "\\$&")
+        );
+      return new RegExp(`^${segments.join("/")}$`);
+    });
+  return (path) => patterns.some((pattern) => pattern.test(path));
+}
+
+// Redirects a path with a trailing slash to the same path without it, when
+// that is a route according to isRoute, keeping the query string. 308 makes
+// clients repeat the method and body, so a POST to /add/ is retried as a
+// POST to /add. Any other path, such as an unknown /nope/, is handled as
+// written.
+export function redirectTrailingSlash(
+  isRoute: (path: string) => boolean
+): MiddlewareHandler<AppEnv> {
+  return async (c, next) => {
+    const url = new URL(c.req.url);
+    const path = trimTrailingSlash(url.pathname);
+    if (path === url.pathname || !isRoute(path)) {
+      return next();
+    }
+    return c.redirect(path + url.search, 308);
+  };
+}
@@ -0,0 +1,21 @@
+import type { MiddlewareHandler } from "hono";
+import type { AppEnv } from "../types";
+
+type Variables = AppEnv["Variables"];
+
+// Exposes app-scoped dependencies (clock, validators, ...) to downstream
+// handlers as c.var entries.
+export function withVariables(
+  variables: Partial<Variables>
+): MiddlewareHandler<AppEnv> {
+  const keys = Object.keys(variables) as (keyof Variables)[];
+  return async (c, next) => {
+    for (const key of keys) {
+      const value = variables[key];
+      if (value !== undefined) {
+        c.set(key, value);
+      }
+    }
+    await next();
+  };
+}
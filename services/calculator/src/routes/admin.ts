@@ -0,0 +1,32 @@
+import { Hono } from "hono";
+import type { Context } from "hono";
+import { errorResponse, methodNotAllowed, respond } from "./response";
+import type { AppEnv, DegradedModeResponse } from "../types";
+
+const admin = new Hono<AppEnv>();
+
+// Switches simulated degradation on or off and reports the new state. For
+// testing alerting: unless the ENABLE_ADMIN binding is "true" the routes
+// answer 404, as if they did not exist.
+function setDegraded(c: Context<AppEnv>, degraded: boolean) {
+  if (c.env?.ENABLE_ADMIN !== "true") {
+    return errorResponse(c, 404, "not_found", "Not found");
+  }
+  if (degraded) {
+    c.var.degradedMode.degrade();
+  } else {
+    c.var.degradedMode.recover();
+  }
+  const response: DegradedModeResponse = {
+    degraded: c.var.degradedMode.active,
+  };
+  return respond(c, response);
+}
+
+admin.post("/admin/degrade", (c) => setDegraded(c, true));
+admin.post("/admin/recover", (c) => setDegraded(c, false));
+
+admin.all("/admin/degrade", methodNotAllowed);
+admin.all("/admin/recover", methodNotAllowed);
+
+export { admin };
@@ -0,0 +1,22 @@
+interface RegisteredRoute {
+  path: string;
+}
+
+// Checks aliases, a map from alias path to the path it stands for, against
+// the routes the app registered. An alias may not shadow a route of its own,
+// so configuring one can never silently replace a built-in endpoint, and its
+// target must exist.
+export function checkAliases(
+  routes: readonly RegisteredRoute[],
+  aliases: ReadonlyMap<string, string>
+): void {
+  const paths = new Set(routes.map((route) => route.path));
+  for (const [alias, target] of aliases) {
+    if (paths.has(alias)) {
+      throw new Error(`Alias ${alias} would shadow an existing route`);
+    }
+    if (!paths.has(target)) {
+      throw new Error(`Alias ${alias} refers to unknown route ${target}`);
+    }
+  }
+}
@@ -0,0 +1,147 @@
+import { Hono } from "hono";
+import {
+  deadlineSignal,
+  isTimeout,
+  untilAborted,
+} from "../services/deadline";
+import {
+  isBinaryOperationName,
+  isUnaryOperationName,
+} from "../services/operations";
+import { mapConcurrent } from "../services/pool";
+import { errorResponseFor } from "./errors";
+import { evaluateOperation } from "./evaluate";
+import { recordOutcome } from "./outcome";
+import { readJsonBody } from "./request";
+import {
+  errorResponse,
+  methodNotAllowed,
+  respond,
+  validationErrorResponse,
+} from "./response";
+import type {
+  AppEnv,
+  BatchResponse,
+  ErrorResponse,
+  FieldError,
+  OperationResponse,
+} from "../types";
+import { isNamedOperationRequest } from "../types";
+
+const batch = new Hono<AppEnv>();
+
+export const MAX_BATCH_SIZE = 1000;
+
+function batchErrors(body: unknown): FieldError[] {
+  const operations =
+    typeof body === "object" && body !== null && !Array.isArray(body)
+      ? (body as Record<string, unknown>).operations
+      : undefined;
+  if (operations === undefined) {
+    return [{ field: "operations", message: "required" }];
+  }
+  if (!Array.isArray(operations)) {
+    return [{ field: "operations", message: "must be an array" }];
+  }
+  if (operations.length > MAX_BATCH_SIZE) {
+    return [
+      {
+        field: "operations",
+        message: `must have at most ${MAX_BATCH_SIZE} items`,
+      },
+    ];
+  }
+  return [];
+}
+
+// Names outside the known operations are counted together, so clients cannot
+// grow /stats and /metrics without bound.
+function operationName(name: string): string {
+  return isBinaryOperationName(name) || isUnaryOperationName(name)
+    ? name
+    : "unknown";
+}
+
+// 200 when every operation succeeded, as for an empty batch; 207
+// Multi-Status when some did and some did not; and 422 when none did, since
+// nothing the client asked for was done. The body is per-item results
+// either way, unlike the 400 for a body that is not a batch.
+function batchStatus(
+  results: readonly (OperationResponse | ErrorResponse)[]
+): 200 | 207 | 422 {
+  const succeeded = results.filter((reply) => "result" in reply).length;
+  if (succeeded === results.length) {
+    return 200;
+  }
+  return succeeded === 0 ? 422 : 207;
+}
+
+// Evaluates each named operation independently, up to c.var.batchConcurrency
+// at a time under one deadline, and replies with a result or error per
+// operation in request order whatever order they finish in, with the
+// status from batchStatus; a body that is not a batch at all is rejected
+// with 400.
+//
+// Workers are taken from c.var.batchWorkers, shared by every batch, so a
+// batch runs with fewer when others hold most of them and is turned away
+// with 503 and Retry-After when none are free.
+batch.post("/batch", async (c) => {
+  let body: unknown;
+  try {
+    body = await readJsonBody(c);
+  } catch (error) {
+    return errorResponseFor(c, error);
+  }
+  const errors = batchErrors(body);
+  if (errors.length > 0) {
+    return validationErrorResponse(c, errors);
+  }
+  const { operations } = body as { operations: unknown[] };
+
+  const workers = c.var.batchWorkers.tryAcquire(
+    Math.max(1, Math.min(c.var.batchConcurrency, operations.length))
+  );
+  if (workers === 0) {
+    c.header("Retry-After", "1");
+    return errorResponse(c, 503, "overloaded", "Batch capacity exhausted");
+  }
+
+  const signal = deadlineSignal(c.var.requestTimeoutMs, c.req.raw.signal);
+  const work = mapConcurrent(
+    operations,
+    workers,
+    async (operation) => {
+      const reply = await evaluateOperation(c, operation, signal);
+      if (isNamedOperationRequest(operation)) {
+        const name = operationName(operation.operation);
+        recordOutcome(c, name, "result" in reply);
+      }
+      return reply;
+    },
+    signal
+  );
+  // Released once the workers stop, which may be after the deadline has
+  // given up on them, so timed-out batches still count against the limit.
+  const release = () => c.var.batchWorkers.release(workers);
+  void work.then(release, release);
+
+  let results: (OperationResponse | ErrorResponse)[];
+  try {
+    results = await untilAborted(work, signal);
+  } catch (error) {
+    if (c.req.raw.signal.aborted) {
+      return errorResponse(c, 503, "request_cancelled", "Request cancelled");
+    }
+    if (isTimeout(error)) {
+      return errorResponse(c, 503, "timeout", "Request timed out");
+    }
+    throw error;
+  }
+
+  const response: BatchResponse = { results };
+  return respond(c, response, batchStatus(results));
+});
+
+batch.all("/batch", methodNotAllowed);
+
+export { batch };
@@ -0,0 +1,57 @@
+import { Hono } from "hono";
+import { runBenchmark } from "../services/bench";
+import { isBinaryOperationName } from "../services/operations";
+import {
+  errorResponse,
+  methodNotAllowed,
+  respond,
+  validationErrorResponse,
+} from "./response";
+import type { AppEnv, BenchResponse, FieldError } from "../types";
+
+const bench = new Hono<AppEnv>();
+
+const DEFAULT_ITERATIONS = 100_000;
+const MAX_ITERATIONS = 10_000_000;
+
+// Development aid for capacity planning. Unless the ENABLE_BENCH binding is
+// "true" the route answers 404, as if it did not exist.
+bench.get("/bench", async (c) => {
+  if (c.env?.ENABLE_BENCH !== "true") {
+    return errorResponse(c, 404, "not_found", "Not found");
+  }
+
+  const operation = c.req.query("operation") ?? "add";
+  const iterationsText = c.req.query("iterations");
+  const iterations =
+    iterationsText === undefined ? DEFAULT_ITERATIONS : Number(iterationsText);
+
+  const iterationsValid =
+    (iterationsText === undefined || /^\d+$/.test(iterationsText)) &&
+    iterations >= 1 &&
+    iterations <= MAX_ITERATIONS;
+  if (!isBinaryOperationName(operation) || !iterationsValid) {
+    const errors: FieldError[] = [];
+    if (!isBinaryOperationName(operation)) {
+      errors.push({ field: "operation", message: "unknown operation" });
+    }
+    if (!iterationsValid) {
+      errors.push({
+        field: "iterations",
+        message: `must be an integer from 1 to ${MAX_ITERATIONS}`,
+      });
+    }
+    return validationErrorResponse(c, errors);
+  }
+
+  const response: BenchResponse = await runBenchmark(
+    c.var.service,
+    operation,
+    iterations
+  );
+  return respond(c, response);
+});
+
+bench.all("/bench", methodNotAllowed);
+
+export { bench };
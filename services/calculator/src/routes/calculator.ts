@@ -1,86 +1,753 @@
 import { Hono } from "hono";
 import type { Context } from "hono";
-import type { ContentfulStatusCode } from "hono/utils/http-status";
+import { streamSSE } from "hono/streaming";
 import {
   add,
-  subtract,
-  multiply,
   InvalidInputError,
+  isRoundingMode,
+  MAX_PLACES,
+  normalizeResult,
+  OverflowError,
+  ROUNDING_MODES,
+  roundHalfEven,
 } from "../services/calculator";
+import { deadlineSignal, untilAborted } from "../services/deadline";
+import {
+  exactAdd,
+  exactSubtract,
+  exactMultiply,
+  exactDivide,
+  isExactQuotient,
+  parseExactInteger,
+} from "../services/exact";
+import {
+  fromIeeeJson,
+  IEEE_OPERATIONS,
+  isIeeeOperationName,
+  toIeeeJson,
+} from "../services/ieee";
+import type { IeeeOperationName } from "../services/ieee";
+import { evaluateRpn } from "../services/rpn";
+import type {
+  BinaryOperationName,
+  ExactOperation,
+  UnaryOperationName,
+} from "../services/operations";
+import { errorResponseFor, RequestValidationError } from "./errors";
+import { presentResult } from "./evaluate";
+import { recordOutcome } from "./outcome";
+import { DECIMAL, readBodyText, readJsonBody } from "./request";
+import {
+  errorBody,
+  errorResponse,
+  methodNotAllowed,
+  respond,
+} from "./response";
 import type {
+  AppEnv,
+  ApproxEqualRequest,
+  ApproxEqualResponse,
+  CompareResponse,
+  DivModResponse,
+  FactorialResponse,
+  SummaryResponse,
   OperationRequest,
+  UnaryOperationRequest,
+  NumberListRequest,
+  PolynomialRequest,
+  WeightedSumRequest,
+  ConvertRequest,
+  FmaRequest,
+  ClampRequest,
+  FieldError,
+  IeeeOperationResponse,
+  RoundRequest,
+  RpnRequest,
+  SumListRequest,
   OperationResponse,
-  ErrorResponse,
   HealthResponse,
 } from "../types";
-import { isOperationRequest } from "../types";
+import {
+  isSumListRequest,
+  numberListErrors,
+  operandErrors,
+  stringListErrors,
+} from "../types";
 
-const calculator = new Hono();
+const calculator = new Hono<AppEnv>();
 
-async function parseOperationRequest(c: Context): Promise<OperationRequest> {
-  const body = await c.req.json();
-  if (!isOperationRequest(body)) {
-    throw new Error("Invalid request body");
+function checkOperands(body: unknown, fields: readonly string[]) {
+  const errors = operandErrors(body, fields);
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
   }
-  return body;
 }
 
-function errorResponse(c: Context, status: ContentfulStatusCode, message: string) {
-  const error: ErrorResponse = { error: message };
-  return c.json(error, status);
+async function parseOperationRequest(
+  c: Context<AppEnv>
+): Promise<OperationRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["a", "b"]);
+  return body as OperationRequest;
 }
 
-calculator.post("/add", async (c) => {
-  try {
-    const { a, b } = await parseOperationRequest(c);
-    const result = add(a, b);
-    const response: OperationResponse = { result };
-    return c.json(response);
-  } catch (error) {
-    if (error instanceof InvalidInputError) {
-      return errorResponse(c, 400, error.message);
+// Parses an operation request keeping the source text of each operand, so
+// integers beyond 2^53 are not rounded by JSON.parse.
+async function parseExactOperationRequest(
+  c: Context<AppEnv>
+): Promise<{ a: bigint; b: bigint }> {
+  const operands: { holder: unknown; key: string; source: string }[] = [];
+  const body: unknown = JSON.parse(
+    await readBodyText(c),
+    function (
+      this: unknown,
+      key: string,
+      value: unknown,
+      context?: { source?: string }
+    ) {
+      if (typeof value === "number" && context?.source !== undefined) {
+        operands.push({ holder: this, key, source: context.source });
+      }
+      return value;
     }
-    return errorResponse(c, 400, "Invalid request");
+  );
+  checkOperands(body, ["a", "b"]);
+  const source = (key: string) =>
+    operands.find((operand) => operand.holder === body && operand.key === key)
+      ?.source;
+  const a = source("a");
+  const b = source("b");
+  if (a === undefined || b === undefined) {
+    throw new InvalidInputError(
+      "invalid input: exact mode is not supported by this runtime"
+    );
   }
-});
+  return { a: parseExactInteger(a), b: parseExactInteger(b) };
+}
+
+async function parseUnaryOperationRequest(
+  c: Context<AppEnv>
+): Promise<UnaryOperationRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["a"]);
+  return body as UnaryOperationRequest;
+}
+
+async function parseNumberListRequest(
+  c: Context<AppEnv>
+): Promise<NumberListRequest> {
+  const body = await readJsonBody(c);
+  const errors = numberListErrors(body, "numbers");
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return body as NumberListRequest;
+}
+
+async function parsePolynomialRequest(
+  c: Context<AppEnv>
+): Promise<PolynomialRequest> {
+  const body = await readJsonBody(c);
+  const errors = [
+    ...numberListErrors(body, "coefficients"),
+    ...operandErrors(body, ["x"]),
+  ];
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return body as PolynomialRequest;
+}
+
+async function parseWeightedSumRequest(
+  c: Context<AppEnv>
+): Promise<WeightedSumRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["a", "wa", "b", "wb"]);
+  return body as WeightedSumRequest;
+}
+
+async function parseConvertRequest(
+  c: Context<AppEnv>
+): Promise<ConvertRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["value", "scale", "offset"]);
+  return body as ConvertRequest;
+}
+
+async function parseFmaRequest(c: Context<AppEnv>): Promise<FmaRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["a", "b", "c"]);
+  return body as FmaRequest;
+}
+
+async function parseRpnRequest(c: Context<AppEnv>): Promise<RpnRequest> {
+  const body = await readJsonBody(c);
+  const errors = stringListErrors(body, "tokens");
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return body as RpnRequest;
+}
+
+async function parseClampRequest(c: Context<AppEnv>): Promise<ClampRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["value", "min", "max"]);
+  return body as ClampRequest;
+}
+
+async function parseApproxEqualRequest(
+  c: Context<AppEnv>
+): Promise<ApproxEqualRequest> {
+  const body = await readJsonBody(c);
+  checkOperands(body, ["a", "b", "epsilon"]);
+  return body as ApproxEqualRequest;
+}
+
+// mode is optional, so it is checked here rather than by checkOperands.
+async function parseRoundRequest(c: Context<AppEnv>): Promise<RoundRequest> {
+  const body = await readJsonBody(c);
+  const errors = operandErrors(body, ["value", "places"]);
+  const { mode } = (body ?? {}) as { mode?: unknown };
+  if (mode !== undefined && !isRoundingMode(mode)) {
+    errors.push({
+      field: "mode",
+      message: `must be one of ${ROUNDING_MODES.join(", ")}`,
+    });
+  }
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return body as RoundRequest;
+}
+
+// Reads the named operands of an IEEE mode request, each a JSON number or
+// "NaN", "Infinity" or "-Infinity".
+async function parseIeeeOperands(
+  c: Context<AppEnv>,
+  fields: readonly string[]
+): Promise<number[]> {
+  const body = await readJsonBody(c);
+  const object =
+    typeof body === "object" && body !== null && !Array.isArray(body)
+      ? (body as Record<string, unknown>)
+      : {};
+  const errors: FieldError[] = [];
+  const operands = fields.map((field) => {
+    const value = object[field];
+    const operand = fromIeeeJson(value);
+    if (value === undefined) {
+      errors.push({ field, message: "required" });
+    } else if (operand === undefined) {
+      errors.push({
+        field,
+        message: 'must be a number or "NaN", "Infinity" or "-Infinity"',
+      });
+    }
+    return operand ?? NaN;
+  });
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return operands;
+}
+
+// Reads operands from the query string, e.g. ?a=2&b=3, reporting each that
+// is missing or not a decimal number as a body field would be.
+function parseQueryOperands(
+  c: Context<AppEnv>,
+  fields: readonly string[]
+): number[] {
+  const errors: FieldError[] = [];
+  const operands = fields.map((field) => {
+    const text = c.req.query(field);
+    if (text === undefined) {
+      errors.push({ field, message: "required" });
+    } else if (!DECIMAL.test(text)) {
+      errors.push({ field, message: "must be a number" });
+    }
+    return Number(text);
+  });
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return operands;
+}
+
+// Reads ?places=N, the decimal places to round a quotient to. Undefined
+// means full precision.
+function parsePlaces(c: Context<AppEnv>): number | undefined {
+  const text = c.req.query("places");
+  if (text === undefined) {
+    return undefined;
+  }
+  const places = Number(text);
+  if (!/^\d+$/.test(text) || places > MAX_PLACES) {
+    throw new RequestValidationError([
+      {
+        field: "places",
+        message: `must be an integer from 0 to ${MAX_PLACES}`,
+      },
+    ]);
+  }
+  return places;
+}
 
-calculator.post("/subtract", async (c) => {
+async function parseSumListRequest(
+  c: Context<AppEnv>
+): Promise<SumListRequest> {
+  const body = await readJsonBody(c);
+  if (!isSumListRequest(body)) {
+    const numbers = (body as Record<string, unknown> | null)?.numbers;
+    throw new RequestValidationError([
+      {
+        field: "numbers",
+        message: numbers === undefined ? "required" : "must be an array",
+      },
+    ]);
+  }
+  return body;
+}
+
+// Runs compute under the request deadline and maps its errors to responses,
+// counting the outcome under the operation name in c.var.stats. compute is
+// handed the deadline's signal to pass to the service; it also aborts if the
+// client disconnects. A successful response is passed through present before
+// it is written.
+async function handleComputation<T>(
+  c: Context<AppEnv>,
+  name: string,
+  compute: (signal: AbortSignal) => Promise<T>,
+  present: (response: T) => unknown = (response) => response
+) {
+  const signal = deadlineSignal(c.var.requestTimeoutMs, c.req.raw.signal);
+  let response: T;
   try {
-    const { a, b } = await parseOperationRequest(c);
-    const result = subtract(a, b);
-    const response: OperationResponse = { result };
-    return c.json(response);
+    response = await untilAborted(compute(signal), signal);
   } catch (error) {
-    if (error instanceof InvalidInputError) {
-      return errorResponse(c, 400, error.message);
+    recordOutcome(c, name, false);
+    if (c.req.raw.signal.aborted) {
+      return errorResponse(c, 503, "request_cancelled", "Request cancelled");
+    }
+    return errorResponseFor(c, error);
+  }
+  recordOutcome(c, name, true);
+  return respond(c, present(response));
+}
+
+// handleComputation for operations with a single result, presented by
+// presentResult; an exact value is left as computed.
+function handleOperation(
+  c: Context<AppEnv>,
+  name: string,
+  compute: (signal: AbortSignal) => Promise<OperationResponse>
+) {
+  return handleComputation(c, name, compute, (response) =>
+    presentResult(c, name, response)
+  );
+}
+
+// ?ieee=true computes with plain IEEE 754 arithmetic instead of the service,
+// skipping validators, so 1/0 is "Infinity" rather than a 400. Only for
+// operations in IEEE_OPERATIONS; others ignore the parameter.
+function isIeeeMode(
+  c: Context<AppEnv>,
+  name: string
+): name is IeeeOperationName {
+  return c.req.query("ieee") === "true" && isIeeeOperationName(name);
+}
+
+function handleIeeeOperation(c: Context<AppEnv>, name: IeeeOperationName) {
+  return handleComputation(
+    c,
+    name,
+    async (): Promise<IeeeOperationResponse> => {
+      const operation = IEEE_OPERATIONS[name] as (
+        ...operands: number[]
+      ) => number;
+      const fields = operation.length === 2 ? ["a", "b"] : ["a"];
+      const operands = await parseIeeeOperands(c, fields);
+      const result = c.var.resultTransform(name, operation(...operands));
+      return { result: toIeeeJson(result) };
     }
-    return errorResponse(c, 400, "Invalid request");
+  );
+}
+
+async function computeBinary(
+  c: Context<AppEnv>,
+  name: BinaryOperationName,
+  signal: AbortSignal
+): Promise<OperationResponse> {
+  const { a, b } = await parseOperationRequest(c);
+  c.var.validators.validate(name, [a, b]);
+  return { result: await c.var.service[name](a, b, signal) };
+}
+
+async function computeExact(
+  c: Context<AppEnv>,
+  operation: ExactOperation
+): Promise<OperationResponse> {
+  const { a, b } = await parseExactOperationRequest(c);
+  const exact = operation(a, b);
+  const result = typeof exact === "bigint" ? Number(exact) : exact.toNumber();
+  return { result, exact: exact.toString() };
+}
+
+// Integer arithmetic that honours ?exact=true by computing with BigInt.
+// Division yields a fraction in lowest terms.
+function computeArithmetic(
+  c: Context<AppEnv>,
+  name: BinaryOperationName,
+  exactOperation: ExactOperation,
+  signal: AbortSignal
+): Promise<OperationResponse> {
+  if (c.req.query("exact") === "true") {
+    return computeExact(c, exactOperation);
+  }
+  return computeBinary(c, name, signal);
+}
+
+function handleBinaryOperation(
+  c: Context<AppEnv>,
+  name: BinaryOperationName
+) {
+  if (isIeeeMode(c, name)) {
+    return handleIeeeOperation(c, name);
+  }
+  return handleOperation(c, name, (signal) => computeBinary(c, name, signal));
+}
+
+function handleArithmetic(
+  c: Context<AppEnv>,
+  name: BinaryOperationName,
+  exactOperation: ExactOperation
+) {
+  if (isIeeeMode(c, name)) {
+    return handleIeeeOperation(c, name);
+  }
+  return handleOperation(c, name, (signal) =>
+    computeArithmetic(c, name, exactOperation, signal)
+  );
+}
+
+function handleListOperation(
+  c: Context<AppEnv>,
+  name: "addMany" | "kahanSum" | "multiplyMany"
+) {
+  return handleOperation(c, name, async (signal) => {
+    const { numbers } = await parseNumberListRequest(c);
+    c.var.validators.validate(name, numbers);
+    return { result: await c.var.service[name](numbers, signal) };
+  });
+}
+
+function handleUnaryOperation(c: Context<AppEnv>, name: UnaryOperationName) {
+  if (isIeeeMode(c, name)) {
+    return handleIeeeOperation(c, name);
   }
+  return handleOperation(c, name, async (signal) => {
+    const { a } = await parseUnaryOperationRequest(c);
+    c.var.validators.validate(name, [a]);
+    return { result: await c.var.service[name](a, signal) };
+  });
+}
+
+calculator.post("/add", (c) => handleArithmetic(c, "add", exactAdd));
+// GET /add?a=2&b=3, for clients that can only send GET requests or want the
+// response cached by URL.
+calculator.get("/add", (c) =>
+  handleOperation(c, "add", async (signal) => {
+    const [a, b] = parseQueryOperands(c, ["a", "b"]);
+    c.var.validators.validate("add", [a, b]);
+    return { result: await c.var.service.add(a, b, signal) };
+  })
+);
+calculator.post("/subtract", (c) =>
+  handleArithmetic(c, "subtract", exactSubtract)
+);
+calculator.post("/multiply", (c) =>
+  handleArithmetic(c, "multiply", exactMultiply)
+);
+
+// ?places=N rounds the quotient half-to-even; an exact fraction is left as
+// is. With reportExactDivision the response also says whether the quotient
+// returned is exactly a / b; exact mode's fraction already tells, so it goes
+// without. In IEEE mode the quotient is returned as computed.
+calculator.post("/divide", (c) => {
+  if (isIeeeMode(c, "divide")) {
+    return handleIeeeOperation(c, "divide");
+  }
+  return handleOperation(c, "divide", async (signal) => {
+    const places = parsePlaces(c);
+    const round = (quotient: number) =>
+      places === undefined ? quotient : roundHalfEven(quotient, places);
+    if (!c.var.reportExactDivision || c.req.query("exact") === "true") {
+      const response = await computeArithmetic(
+        c,
+        "divide",
+        exactDivide,
+        signal
+      );
+      return { ...response, result: round(response.result) };
+    }
+    const { a, b } = await parseOperationRequest(c);
+    c.var.validators.validate("divide", [a, b]);
+    const result = round(await c.var.service.divide(a, b, signal));
+    return { result, isExact: isExactQuotient(a, b, result) };
+  });
 });
 
-calculator.post("/multiply", async (c) => {
-  try {
+// Truncating division, e.g. -7 divmod 2 is quotient -3, remainder -1. The
+// pair has no single result, so resultTransform does not apply.
+calculator.post("/divmod", (c) =>
+  handleComputation(c, "divmod", async (signal): Promise<DivModResponse> => {
     const { a, b } = await parseOperationRequest(c);
-    const result = multiply(a, b);
-    const response: OperationResponse = { result };
-    return c.json(response);
-  } catch (error) {
-    if (error instanceof InvalidInputError) {
-      return errorResponse(c, 400, error.message);
+    c.var.validators.validate("divmod", [a, b]);
+    const { quotient, remainder } = await c.var.service.divmod(a, b, signal);
+    return {
+      quotient: normalizeResult(quotient),
+      remainder: normalizeResult(remainder),
+    };
+  })
+);
+
+calculator.post("/hypot", (c) => handleBinaryOperation(c, "hypot"));
+calculator.post("/diff", (c) => handleBinaryOperation(c, "diff"));
+calculator.post("/gcd", (c) => handleBinaryOperation(c, "gcd"));
+calculator.post("/lcm", (c) => handleBinaryOperation(c, "lcm"));
+calculator.post("/percent-change", (c) =>
+  handleBinaryOperation(c, "percentChange")
+);
+
+calculator.post("/add/many", (c) => handleListOperation(c, "addMany"));
+// Compensated summation, for long lists mixing large and small values.
+calculator.post("/sum/kahan", (c) => handleListOperation(c, "kahanSum"));
+calculator.post("/multiply/many", (c) =>
+  handleListOperation(c, "multiplyMany")
+);
+
+calculator.post("/weighted-sum", (c) =>
+  handleOperation(c, "weightedSum", async (signal) => {
+    const { a, wa, b, wb } = await parseWeightedSumRequest(c);
+    c.var.validators.validate("weightedSum", [a, wa, b, wb]);
+    return { result: await c.var.service.weightedSum(a, wa, b, wb, signal) };
+  })
+);
+
+calculator.post("/polynomial", (c) =>
+  handleOperation(c, "polynomial", async (signal) => {
+    const { coefficients, x } = await parsePolynomialRequest(c);
+    c.var.validators.validate("polynomial", [...coefficients, x]);
+    return {
+      result: await c.var.service.evalPolynomial(coefficients, x, signal),
+    };
+  })
+);
+
+calculator.post("/convert", (c) =>
+  handleOperation(c, "convert", async (signal) => {
+    const { value, scale, offset } = await parseConvertRequest(c);
+    c.var.validators.validate("convert", [value, scale, offset]);
+    return {
+      result: await c.var.service.convert(value, scale, offset, signal),
+    };
+  })
+);
+
+// a*b + c rounded once, more accurate than computing it in two steps.
+calculator.post("/fma", (c) =>
+  handleOperation(c, "fma", async (signal) => {
+    const { a, b, c: addend } = await parseFmaRequest(c);
+    c.var.validators.validate("fma", [a, b, addend]);
+    return { result: await c.var.service.fma(a, b, addend, signal) };
+  })
+);
+
+calculator.post("/clamp", (c) =>
+  handleOperation(c, "clamp", async (signal) => {
+    const { value, min, max } = await parseClampRequest(c);
+    c.var.validators.validate("clamp", [value, min, max]);
+    return { result: await c.var.service.clamp(value, min, max, signal) };
+  })
+);
+
+// Evaluates an RPN token stream, computing each operator with the service as
+// /add and the others do, so a zero divisor fails the same way.
+calculator.post("/calc/reverse-polish", (c) =>
+  handleOperation(c, "reversePolish", async (signal) => {
+    const { tokens } = await parseRpnRequest(c);
+    const result = await evaluateRpn(tokens, (name, a, b) => {
+      c.var.validators.validate(name, [a, b]);
+      return c.var.service[name](a, b, signal);
+    });
+    return { result };
+  })
+);
+
+// Computed exactly with BigInt, so unlike other operations it has no float
+// overflow; the operand is capped instead.
+calculator.post("/factorial", (c) =>
+  handleComputation(
+    c,
+    "factorial",
+    async (signal): Promise<FactorialResponse> => {
+      const { a } = await parseUnaryOperationRequest(c);
+      c.var.validators.validate("factorial", [a]);
+      return c.var.service.factorial(a, signal);
+    }
+  )
+);
+
+// Count, mean, population variance, standard deviation and range of a
+// non-empty list. Several figures rather than one result, so
+// resultTransform does not apply.
+calculator.post("/stats/summary", (c) =>
+  handleComputation(
+    c,
+    "summary",
+    async (signal): Promise<SummaryResponse> => {
+      const { numbers } = await parseNumberListRequest(c);
+      c.var.validators.validate("summary", numbers);
+      return c.var.service.summary(numbers, signal);
     }
-    return errorResponse(c, 400, "Invalid request");
+  )
+);
+
+// For sorting clients. The sign is not a magnitude, so resultTransform does
+// not apply.
+calculator.post("/compare", (c) =>
+  handleComputation(c, "compare", async (signal): Promise<CompareResponse> => {
+    const { a, b } = await parseOperationRequest(c);
+    c.var.validators.validate("compare", [a, b]);
+    const comparison = await c.var.service.compare(a, b, signal);
+    return { comparison: comparison as CompareResponse["comparison"] };
+  })
+);
+
+// For clients comparing computed results, which exact equality would fail
+// over representation error. The answer is not a magnitude, so
+// resultTransform does not apply.
+calculator.post("/approx-equal", (c) =>
+  handleComputation(
+    c,
+    "approxEqual",
+    async (signal): Promise<ApproxEqualResponse> => {
+      const { a, b, epsilon } = await parseApproxEqualRequest(c);
+      c.var.validators.validate("approxEqual", [a, b, epsilon]);
+      return {
+        equal: await c.var.service.approxEqual(a, b, epsilon, signal),
+      };
+    }
+  )
+);
+
+calculator.post("/round", (c) =>
+  handleOperation(c, "round", async (signal) => {
+    const { value, places, mode = "half_even" } = await parseRoundRequest(c);
+    c.var.validators.validate("round", [value, places]);
+    return {
+      result: await c.var.service.round(value, places, mode, signal),
+    };
+  })
+);
+
+calculator.post("/sin", (c) => handleUnaryOperation(c, "sin"));
+calculator.post("/cos", (c) => handleUnaryOperation(c, "cos"));
+calculator.post("/tan", (c) => handleUnaryOperation(c, "tan"));
+calculator.post("/log", (c) => handleUnaryOperation(c, "log"));
+calculator.post("/ln", (c) => handleUnaryOperation(c, "ln"));
+
+// Streams the running sum after each element as an SSE data event. An invalid
+// element emits a single "error" event and ends the stream.
+calculator.post("/sum-list/sse", async (c) => {
+  let numbers: unknown[];
+  try {
+    ({ numbers } = await parseSumListRequest(c));
+  } catch (error) {
+    return errorResponseFor(c, error);
   }
+
+  return streamSSE(c, async (stream) => {
+    const fail = (message: string) => {
+      const error = errorBody(c, "invalid_input", message);
+      return stream.writeSSE({ event: "error", data: JSON.stringify(error) });
+    };
+
+    let sum = 0;
+    for (const [index, value] of numbers.entries()) {
+      if (typeof value !== "number") {
+        await fail(`invalid input: element ${index} is not a number`);
+        return;
+      }
+      try {
+        sum = add(sum, value);
+      } catch (error) {
+        if (error instanceof OverflowError) {
+          await fail(
+            `invalid input: partial sum overflowed at element ${index}`
+          );
+          return;
+        }
+        if (error instanceof InvalidInputError) {
+          await fail(`invalid input: element ${index} is not finite`);
+          return;
+        }
+        throw error;
+      }
+      const response: OperationResponse = { result: sum };
+      await stream.writeSSE({ data: JSON.stringify(response) });
+    }
+  });
 });
 
+// Hono also answers HEAD /health from this handler, without the body, for
+// load balancer checks.
 calculator.get("/health", (c) => {
   const response: HealthResponse = { status: "ok" };
-  return c.json(response);
+  return respond(c, response);
+});
+
+// Health checks are often probed by infrastructure with the wrong method, so
+// the 405 says which methods work and carries its own code.
+calculator.all("/health", (c) => {
+  c.header("Allow", "GET, HEAD");
+  return errorResponse(
+    c,
+    405,
+    "health_method_not_allowed",
+    "Method not allowed"
+  );
 });
 
 // Handle wrong HTTP methods
-calculator.all("/add", (c) => errorResponse(c, 405, "Method not allowed"));
-calculator.all("/subtract", (c) => errorResponse(c, 405, "Method not allowed"));
-calculator.all("/multiply", (c) => errorResponse(c, 405, "Method not allowed"));
-calculator.all("/health", (c) => errorResponse(c, 405, "Method not allowed"));
+calculator.all("/add", methodNotAllowed);
+calculator.all("/subtract", methodNotAllowed);
+calculator.all("/multiply", methodNotAllowed);
+calculator.all("/divide", methodNotAllowed);
+calculator.all("/divmod", methodNotAllowed);
+calculator.all("/hypot", methodNotAllowed);
+calculator.all("/diff", methodNotAllowed);
+calculator.all("/gcd", methodNotAllowed);
+calculator.all("/lcm", methodNotAllowed);
+calculator.all("/percent-change", methodNotAllowed);
+calculator.all("/add/many", methodNotAllowed);
+calculator.all("/sum/kahan", methodNotAllowed);
+calculator.all("/multiply/many", methodNotAllowed);
+calculator.all("/weighted-sum", methodNotAllowed);
+calculator.all("/polynomial", methodNotAllowed);
+calculator.all("/convert", methodNotAllowed);
+calculator.all("/fma", methodNotAllowed);
+calculator.all("/clamp", methodNotAllowed);
+calculator.all("/calc/reverse-polish", methodNotAllowed);
+calculator.all("/factorial", methodNotAllowed);
+calculator.all("/stats/summary", methodNotAllowed);
+calculator.all("/compare", methodNotAllowed);
+calculator.all("/approx-equal", methodNotAllowed);
+calculator.all("/round", methodNotAllowed);
+calculator.all("/sin", methodNotAllowed);
+calculator.all("/cos", methodNotAllowed);
+calculator.all("/tan", methodNotAllowed);
+calculator.all("/log", methodNotAllowed);
+calculator.all("/ln", methodNotAllowed);
+calculator.all("/sum-list/sse", methodNotAllowed);
 
 export { calculator };
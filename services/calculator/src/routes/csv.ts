@@ -0,0 +1,128 @@
+import { Hono } from "hono";
+import type { Context } from "hono";
+import { formatCsv, parseCsv } from "../services/csv";
+import {
+  deadlineSignal,
+  isTimeout,
+  untilAborted,
+} from "../services/deadline";
+import type { BinaryOperationName } from "../services/operations";
+import { describeError, panicOnUnexpected } from "./errors";
+import { recordOutcome } from "./outcome";
+import { DECIMAL } from "./request";
+import { errorResponse, methodNotAllowed } from "./response";
+import type { AppEnv } from "../types";
+
+const csv = new Hono<AppEnv>();
+
+const OUTPUT_HEADER = ["a", "b", "result", "error"];
+
+// Rows accepted in one body, not counting a header, as MAX_BATCH_SIZE limits
+// a batch.
+export const MAX_CSV_ROWS = 1000;
+
+// A cell's number, or undefined unless it is a decimal; padding around the
+// number is allowed, as in "1, 2".
+function parseCell(cell: string): number | undefined {
+  const text = cell.trim();
+  return DECIMAL.test(text) ? Number(text) : undefined;
+}
+
+// A first row with no numeric cells, such as "a,b", is a header.
+function isHeader(row: string[]): boolean {
+  return row.every((cell) => parseCell(cell) === undefined);
+}
+
+// Computes one "a,b" row. Problems are reported in the error column so a bad
+// row does not abort the file, as describeError words them; an error it does
+// not recognise is subject to the error policy, as on any operation route.
+// A row cut short by the deadline or the client leaving ends the file.
+async function evaluateRow(
+  c: Context<AppEnv>,
+  name: BinaryOperationName,
+  row: string[],
+  signal: AbortSignal
+): Promise<string[]> {
+  const [a = "", b = ""] = row;
+  if (row.length !== 2) {
+    recordOutcome(c, name, false);
+    return [a, b, "", `expected 2 columns, got ${row.length}`];
+  }
+  const x = parseCell(a);
+  const y = parseCell(b);
+  if (x === undefined || y === undefined) {
+    recordOutcome(c, name, false);
+    return [a, b, "", "invalid number"];
+  }
+  try {
+    c.var.validators.validate(name, [x, y]);
+    const result = await c.var.service[name](x, y, signal);
+    recordOutcome(c, name, true);
+    return [a, b, String(result), ""];
+  } catch (error) {
+    if (signal.aborted) {
+      throw error;
+    }
+    panicOnUnexpected(c, error);
+    recordOutcome(c, name, false);
+    return [a, b, "", describeError(error).message];
+  }
+}
+
+// Applies the operation to each "a,b" row of a text/csv body and returns
+// "a,b,result,error" rows in the same order. Rows share one deadline.
+async function handleCsv(c: Context<AppEnv>, name: BinaryOperationName) {
+  const rows = parseCsv(await c.req.text());
+  if (rows.length > 0 && isHeader(rows[0])) {
+    rows.shift();
+  }
+  if (rows.length > MAX_CSV_ROWS) {
+    return errorResponse(
+      c,
+      400,
+      "invalid_request",
+      `CSV body must have at most ${MAX_CSV_ROWS} rows`
+    );
+  }
+
+  const signal = deadlineSignal(c.var.requestTimeoutMs, c.req.raw.signal);
+  let output: string[][];
+  try {
+    output = await untilAborted(
+      (async () => {
+        const results: string[][] = [];
+        for (const row of rows) {
+          results.push(await evaluateRow(c, name, row, signal));
+        }
+        return results;
+      })(),
+      signal
+    );
+  } catch (error) {
+    if (c.req.raw.signal.aborted) {
+      return errorResponse(c, 503, "request_cancelled", "Request cancelled");
+    }
+    if (isTimeout(error)) {
+      return errorResponse(c, 503, "timeout", "Request timed out");
+    }
+    throw error;
+  }
+
+  return c.body(formatCsv([OUTPUT_HEADER, ...output]), 200, {
+    "Content-Type": "text/csv; charset=utf-8",
+  });
+}
+
+csv.post("/add/csv", (c) => handleCsv(c, "add"));
+csv.post("/subtract/csv", (c) => handleCsv(c, "subtract"));
+csv.post("/multiply/csv", (c) => handleCsv(c, "multiply"));
+csv.post("/divide/csv", (c) => handleCsv(c, "divide"));
+csv.post("/hypot/csv", (c) => handleCsv(c, "hypot"));
+
+csv.all("/add/csv", methodNotAllowed);
+csv.all("/subtract/csv", methodNotAllowed);
+csv.all("/multiply/csv", methodNotAllowed);
+csv.all("/divide/csv", methodNotAllowed);
+csv.all("/hypot/csv", methodNotAllowed);
+
+export { csv };
@@ -0,0 +1,92 @@
+import type { Context } from "hono";
+import type { ContentfulStatusCode } from "hono/utils/http-status";
+import { CircuitOpenError } from "../services/breaker";
+import { InvalidInputError } from "../services/calculator";
+import { isTimeout } from "../services/deadline";
+import { UpstreamError } from "../services/proxy";
+import { EmptyBodyError } from "./request";
+import { errorResponse, validationErrorResponse } from "./response";
+import type { AppEnv, ErrorCode, FieldError } from "../types";
+
+// Thrown by the parse helpers when the body is missing fields or has fields
+// of the wrong type.
+export class RequestValidationError extends Error {
+  readonly errors: FieldError[];
+
+  constructor(errors: FieldError[]) {
+    super("Invalid request body");
+    this.name = "RequestValidationError";
+    this.errors = errors;
+  }
+}
+
+export interface ErrorDescription {
+  status: ContentfulStatusCode;
+  code: ErrorCode;
+  message: string;
+}
+
+const UNRECOGNISED: ErrorDescription = {
+  status: 400,
+  code: "invalid_request",
+  message: "Invalid request",
+};
+
+// How an error thrown while handling an operation is reported to the client.
+// Matching is by class, so subclasses such as DivisionByZeroError,
+// OverflowError and NonIntegerError are reported as the InvalidInputError
+// they extend. Anything unrecognised is blamed on the request.
+export function describeError(error: unknown): ErrorDescription {
+  return recognise(error) ?? UNRECOGNISED;
+}
+
+function recognise(error: unknown): ErrorDescription | undefined {
+  if (error instanceof InvalidInputError) {
+    return { status: 400, code: "invalid_input", message: error.message };
+  }
+  if (error instanceof RequestValidationError) {
+    return { status: 400, code: "invalid_request", message: "Invalid request" };
+  }
+  if (error instanceof EmptyBodyError) {
+    return { status: 400, code: "empty_body", message: error.message };
+  }
+  if (error instanceof SyntaxError) {
+    return { status: 400, code: "malformed_json", message: "Malformed JSON" };
+  }
+  if (error instanceof UpstreamError) {
+    return { status: 502, code: "upstream_error", message: error.message };
+  }
+  if (error instanceof CircuitOpenError) {
+    return { status: 503, code: "circuit_open", message: error.message };
+  }
+  if (isTimeout(error)) {
+    return { status: 503, code: "timeout", message: "Request timed out" };
+  }
+  return undefined;
+}
+
+// Rethrows an error describeError does not recognise, such as a TypeError
+// from a bug in the service, when c.var.errorPolicy is "panic", so that in
+// development it reaches the app's error handler and is logged with its
+// stack rather than passed off as a bad request. Validation and domain
+// errors are never rethrown.
+export function panicOnUnexpected(c: Context<AppEnv>, error: unknown): void {
+  if (c.var.errorPolicy === "panic" && recognise(error) === undefined) {
+    throw error;
+  }
+}
+
+export function statusForError(error: unknown): ContentfulStatusCode {
+  return describeError(error).status;
+}
+
+// Writes the error response describeError gives for error. Validation errors
+// also list the offending fields. Subject to panicOnUnexpected.
+export function errorResponseFor(c: Context<AppEnv>, error: unknown) {
+  if (error instanceof RequestValidationError) {
+    return validationErrorResponse(c, error.errors);
+  }
+  panicOnUnexpected(c, error);
+  const { status, code, message } = describeError(error);
+  return errorResponse(c, status, code, message);
+}
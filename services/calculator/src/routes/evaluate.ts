@@ -0,0 +1,72 @@
+import type { Context } from "hono";
+import { normalizeResult } from "../services/calculator";
+import {
+  isBinaryOperationName,
+  isUnaryOperationName,
+} from "../services/operations";
+import { describeError, panicOnUnexpected } from "./errors";
+import { errorBody } from "./response";
+import type { AppEnv, ErrorResponse, OperationResponse } from "../types";
+import {
+  isNamedOperationRequest,
+  isOperationRequest,
+  isUnaryOperationRequest,
+} from "../types";
+
+// The response for an operation's result: normalized to positive zero, then
+// passed through c.var.resultTransform, and with reportIsInteger saying
+// whether the final result is a whole number. Shared by the single-operation
+// routes, batches and the WebSocket so an operation answers alike on each.
+export function presentResult<T extends OperationResponse>(
+  c: Context<AppEnv>,
+  name: string,
+  response: T
+): T {
+  const result = c.var.resultTransform(name, normalizeResult(response.result));
+  return c.var.reportIsInteger
+    ? { ...response, result, isInteger: Number.isInteger(result) }
+    : { ...response, result };
+}
+
+// Evaluates one named operation such as {"operation":"add","a":1,"b":2}, as
+// sent over the WebSocket or in a batch. Errors are returned as the reply
+// rather than thrown, so one bad operation does not stop the others, except
+// unexpected ones under the "panic" error policy; see panicOnUnexpected.
+export async function evaluateOperation(
+  c: Context<AppEnv>,
+  message: unknown,
+  signal?: AbortSignal
+): Promise<OperationResponse | ErrorResponse> {
+  if (!isNamedOperationRequest(message)) {
+    return errorBody(c, "invalid_request", "Invalid request");
+  }
+
+  const { operation } = message;
+  try {
+    if (isBinaryOperationName(operation)) {
+      if (!isOperationRequest(message)) {
+        return errorBody(c, "invalid_request", "Invalid request");
+      }
+      c.var.validators.validate(operation, [message.a, message.b]);
+      const result = await c.var.service[operation](
+        message.a,
+        message.b,
+        signal
+      );
+      return presentResult(c, operation, { result });
+    }
+    if (isUnaryOperationName(operation)) {
+      if (!isUnaryOperationRequest(message)) {
+        return errorBody(c, "invalid_request", "Invalid request");
+      }
+      c.var.validators.validate(operation, [message.a]);
+      const result = await c.var.service[operation](message.a, signal);
+      return presentResult(c, operation, { result });
+    }
+    return errorBody(c, "unknown_operation", `Unknown operation: ${operation}`);
+  } catch (error) {
+    panicOnUnexpected(c, error);
+    const { code, message } = describeError(error);
+    return errorBody(c, code, message);
+  }
+}
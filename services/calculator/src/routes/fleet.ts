@@ -0,0 +1,28 @@
+import { Hono } from "hono";
+import { checkFleet } from "../services/fleet";
+import { errorResponse, methodNotAllowed, respond } from "./response";
+import type { AppEnv, FleetHealthResponse } from "../types";
+
+const fleet = new Hono<AppEnv>();
+
+// Checks the /health of each calculator configured through AppOptions.fleet
+// and answers "ok", or "degraded" with 503 if any of them is down, listing
+// every node. With no nodes configured it answers 404, as if it did not
+// exist.
+fleet.get("/health/fleet", async (c) => {
+  const { nodes, timeoutMs } = c.var.fleet;
+  if (nodes.length === 0) {
+    return errorResponse(c, 404, "not_found", "Not found");
+  }
+  const checked = await checkFleet(nodes, timeoutMs);
+  const up = checked.every((node) => node.status === "ok");
+  const response: FleetHealthResponse = {
+    status: up ? "ok" : "degraded",
+    nodes: checked,
+  };
+  return respond(c, response, up ? 200 : 503);
+});
+
+fleet.all("/health/fleet", methodNotAllowed);
+
+export { fleet };
@@ -0,0 +1,14 @@
+import { Hono } from "hono";
+import { methodNotAllowed, respond } from "./response";
+import type { AppEnv, HistoryResponse } from "../types";
+
+const history = new Hono<AppEnv>();
+
+history.get("/history", (c) => {
+  const response: HistoryResponse = { entries: c.var.history.entries() };
+  return respond(c, response);
+});
+
+history.all("/history", methodNotAllowed);
+
+export { history };
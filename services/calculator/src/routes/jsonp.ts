@@ -0,0 +1,117 @@
+import { Hono } from "hono";
+import type { Context } from "hono";
+import type { ContentfulStatusCode } from "hono/utils/http-status";
+import { normalizeResult } from "../services/calculator";
+import {
+  describeError,
+  panicOnUnexpected,
+  RequestValidationError,
+  statusForError,
+} from "./errors";
+import { errorBody, errorResponse, methodNotAllowed } from "./response";
+import type {
+  AppEnv,
+  ErrorResponse,
+  FieldError,
+  OperationResponse,
+  ValidationErrorResponse,
+} from "../types";
+
+const jsonp = new Hono<AppEnv>();
+
+const MAX_CALLBACK_LENGTH = 128;
+
+// A plain or dotted JavaScript identifier such as `cb` or `widget.onResult`.
+// Anything else could smuggle script into the response, so it is rejected.
+const CALLBACK_PATTERN = /^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$/;
+
+export function isValidCallback(name: string): boolean {
+  return name.length <= MAX_CALLBACK_LENGTH && CALLBACK_PATTERN.test(name);
+}
+
+// Wraps a JSON body in a call to the named function. U+2028 and U+2029 are
+// valid in JSON strings but not in older JavaScript engines, so they are
+// escaped; the leading comment stops the body being sniffed as another type.
+function script(
+  callback: string,
+  body: OperationResponse | ErrorResponse
+): string {
+  const json = JSON.stringify(body)
+    .replace(/\u2028/g, "\\u2028")
+    .replace(/\u2029/g, "\\u2029");
+  return `/**/${callback}(${json});`;
+}
+
+function reply(
+  c: Context<AppEnv>,
+  callback: string | undefined,
+  body: OperationResponse | ErrorResponse,
+  status: ContentfulStatusCode = 200
+) {
+  if (callback === undefined) {
+    return c.json(body, status);
+  }
+  return c.body(script(callback, body), status, {
+    "Content-Type": "application/javascript; charset=utf-8",
+    "X-Content-Type-Options": "nosniff",
+  });
+}
+
+// Reads ?a= and ?b=, reporting each that is missing or not a number as a
+// body field would be. Infinity passes here and is rejected as invalid_input
+// by the validators, as in a JSON body.
+function parseOperands(c: Context<AppEnv>): [number, number] {
+  const errors: FieldError[] = [];
+  const [a, b] = ["a", "b"].map((field) => {
+    const value = c.req.query(field);
+    const operand = Number(value);
+    if (value === undefined) {
+      errors.push({ field, message: "required" });
+    } else if (value.trim() === "" || Number.isNaN(operand)) {
+      errors.push({ field, message: "must be a number" });
+    }
+    return operand;
+  });
+  if (errors.length > 0) {
+    throw new RequestValidationError(errors);
+  }
+  return [a, b];
+}
+
+// The error body for error, listing the offending fields of a validation
+// error as validationErrorResponse does.
+function errorReply(
+  c: Context<AppEnv>,
+  error: unknown
+): ErrorResponse | ValidationErrorResponse {
+  const { code, message } = describeError(error);
+  const body = errorBody(c, code, message);
+  return error instanceof RequestValidationError
+    ? { ...body, errors: error.errors }
+    : body;
+}
+
+// GET /add/jsonp?a=2&b=3&callback=cb for embeds limited to script tags.
+// Without a callback the response is plain JSON.
+jsonp.get("/add/jsonp", async (c) => {
+  const callback = c.req.query("callback");
+  if (callback !== undefined && !isValidCallback(callback)) {
+    return errorResponse(c, 400, "invalid_callback", "Invalid callback name");
+  }
+
+  try {
+    const [a, b] = parseOperands(c);
+    c.var.validators.validate("add", [a, b]);
+    const response: OperationResponse = {
+      result: normalizeResult(await c.var.service.add(a, b)),
+    };
+    return reply(c, callback, response);
+  } catch (error) {
+    panicOnUnexpected(c, error);
+    return reply(c, callback, errorReply(c, error), statusForError(error));
+  }
+});
+
+jsonp.all("/add/jsonp", methodNotAllowed);
+
+export { jsonp };
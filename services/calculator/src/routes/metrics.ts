@@ -0,0 +1,15 @@
+import { Hono } from "hono";
+import { methodNotAllowed } from "./response";
+import type { AppEnv } from "../types";
+
+const metrics = new Hono<AppEnv>();
+
+metrics.get("/metrics", (c) => {
+  return c.text(c.var.metrics.render(), 200, {
+    "Content-Type": "text/plain; version=0.0.4; charset=utf-8",
+  });
+});
+
+metrics.all("/metrics", methodNotAllowed);
+
+export { metrics };
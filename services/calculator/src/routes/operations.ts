@@ -0,0 +1,22 @@
+import { Hono } from "hono";
+import { errorResponse, methodNotAllowed, respond } from "./response";
+import type { AppEnv, OperationDescriptionResponse } from "../types";
+
+const operations = new Hono<AppEnv>();
+
+// Arity, operand fields, domain constraints and an example request and
+// response of one operation, from the registry in AppOptions.operations,
+// for clients that build their calls from it.
+operations.get("/operations/:name/describe", (c) => {
+  const name = c.req.param("name");
+  const metadata = c.var.operations.describe(name);
+  if (metadata === undefined) {
+    return errorResponse(c, 404, "not_found", `Unknown operation "${name}"`);
+  }
+  const response: OperationDescriptionResponse = metadata;
+  return respond(c, response);
+});
+
+operations.all("/operations/:name/describe", methodNotAllowed);
+
+export { operations };
@@ -0,0 +1,14 @@
+import type { Context } from "hono";
+import type { AppEnv } from "../types";
+
+// Counts the outcome of one operation in /stats and, labelled by status, in
+// /metrics. Callers pass a name from a fixed set, never raw client input, so
+// the metric's label values stay bounded.
+export function recordOutcome(
+  c: Context<AppEnv>,
+  operation: string,
+  succeeded: boolean
+): void {
+  c.var.stats.record(operation, succeeded);
+  c.var.metrics.operations.inc(operation, succeeded ? "success" : "error");
+}
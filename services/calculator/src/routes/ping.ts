@@ -0,0 +1,39 @@
+import { Hono } from "hono";
+import { methodNotAllowed, respond, validationErrorResponse } from "./response";
+import type { AppEnv, PingResponse } from "../types";
+
+const ping = new Hono<AppEnv>();
+
+const MAX_NONCE_LENGTH = 128;
+
+// Identifies this isolate, standing in for a process id. Workers forbid
+// generating random values at startup, so it is chosen on the first ping.
+let instanceId: string | undefined;
+
+// Round-trip check that touches no arithmetic: echoes ?nonce= so the client
+// can match replies to requests, with the server's time and instance. Never
+// cached, so every ping reaches the Worker.
+ping.get("/ping", (c) => {
+  const nonce = c.req.query("nonce") ?? null;
+  if (nonce !== null && nonce.length > MAX_NONCE_LENGTH) {
+    return validationErrorResponse(c, [
+      {
+        field: "nonce",
+        message: `must be at most ${MAX_NONCE_LENGTH} characters`,
+      },
+    ]);
+  }
+
+  instanceId ??= crypto.randomUUID();
+  const response: PingResponse = {
+    nonce,
+    serverTime: c.var.clock.now().toISOString(),
+    instanceId,
+  };
+  c.header("Cache-Control", "no-store");
+  return respond(c, response);
+});
+
+ping.all("/ping", methodNotAllowed);
+
+export { ping };
@@ -0,0 +1,29 @@
+import { Hono } from "hono";
+import { runReadinessChecks } from "../services/readiness";
+import { methodNotAllowed, respond } from "./response";
+import type { AppEnv, ReadinessResponse } from "../types";
+
+const readiness = new Hono<AppEnv>();
+
+// Unlike /health, which only shows the Worker is up, /readyz runs every
+// registered check and answers 503 if any fails. ?verbose=true lists each
+// check with its latency, to spot a slow dependency before it fails. While
+// an outage is simulated it answers 503 "degraded" regardless of the checks.
+readiness.get("/readyz", async (c) => {
+  const signal = AbortSignal.timeout(c.var.requestTimeoutMs);
+  const checks = await runReadinessChecks(c.var.readinessChecks, signal);
+  const degraded = c.var.degradedMode.active;
+  const ready = !degraded && checks.every((check) => check.status === "ok");
+
+  const response: ReadinessResponse = {
+    status: degraded ? "degraded" : ready ? "ok" : "unavailable",
+  };
+  if (c.req.query("verbose") === "true") {
+    response.checks = checks;
+  }
+  return respond(c, response, ready ? 200 : 503);
+});
+
+readiness.all("/readyz", methodNotAllowed);
+
+export { readiness };
@@ -0,0 +1,22 @@
+import { Hono } from "hono";
+import { errorResponse, methodNotAllowed, respond } from "./response";
+import type { AppEnv, RecordingResponse } from "../types";
+
+const recording = new Hono<AppEnv>();
+
+// The recorded exchanges as a RecordingFile, to save and pass to replay().
+// Answers 404 unless the RECORD_REQUESTS binding is "true", as recordings
+// hold request bodies and headers.
+recording.get("/recording", (c) => {
+  if (c.env?.RECORD_REQUESTS !== "true") {
+    return errorResponse(c, 404, "not_found", "Not found");
+  }
+  const response: RecordingResponse = {
+    exchanges: c.var.recording.exchanges(),
+  };
+  return respond(c, response);
+});
+
+recording.all("/recording", methodNotAllowed);
+
+export { recording };
@@ -0,0 +1,31 @@
+import type { Context } from "hono";
+import type { AppEnv } from "../types";
+
+// Thrown when a body is required but the request has none, or only
+// whitespace, so the client is told that rather than that its JSON is
+// malformed.
+export class EmptyBodyError extends Error {
+  constructor() {
+    super("Request body is empty");
+    this.name = "EmptyBodyError";
+  }
+}
+
+// A decimal number such as 2, -0.5 or 1e3. Stricter than Number(), which
+// also takes "", "0x10" and "Infinity".
+export const DECIMAL = /^[+-]?(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?$/;
+
+// The request body as text, throwing EmptyBodyError if it is blank.
+export async function readBodyText(c: Context<AppEnv>): Promise<string> {
+  const text = await c.req.text();
+  if (text.trim() === "") {
+    throw new EmptyBodyError();
+  }
+  return text;
+}
+
+// The request body parsed as JSON. A blank body throws EmptyBodyError and
+// invalid JSON a SyntaxError; describeError reports them differently.
+export async function readJsonBody(c: Context<AppEnv>): Promise<unknown> {
+  return JSON.parse(await readBodyText(c));
+}
@@ -0,0 +1,79 @@
+import type { Context } from "hono";
+import type { ContentfulStatusCode } from "hono/utils/http-status";
+import { DEFAULT_ENCODERS, selectEncoder } from "../services/encoders";
+import type {
+  AppEnv,
+  ErrorCode,
+  ErrorResponse,
+  FieldError,
+  ValidationErrorResponse,
+} from "../types";
+
+// Bodies of the responses respond() wrote, as encoded, so that middleware
+// reading one back need not clone the response and drain the copy.
+const encodedBodies = new WeakMap<Response, string | ArrayBuffer>();
+
+// Writes data in the encoding the Accept header selects from c.var.encoders,
+// JSON when the client has no preference. Middleware mounted without the
+// encoders variable gets the defaults.
+export function respond(
+  c: Context<AppEnv>,
+  data: unknown,
+  status: ContentfulStatusCode = 200
+) {
+  const encoders = c.var.encoders ?? DEFAULT_ENCODERS;
+  const encoder = selectEncoder(c.req.header("Accept"), encoders);
+  const body = encoder.encode(data);
+  const response = c.body(body, status, {
+    "Content-Type": encoder.contentType,
+    Vary: "Accept",
+  });
+  encodedBodies.set(response, body);
+  return response;
+}
+
+// The body of response as text, leaving response itself unread. Taken from
+// the encoded body when respond() wrote the response, and read from a clone
+// otherwise.
+export function responseText(response: Response): Promise<string> {
+  const body = encodedBodies.get(response);
+  return typeof body === "string"
+    ? Promise.resolve(body)
+    : response.clone().text();
+}
+
+export function errorBody(
+  c: Context<AppEnv>,
+  code: ErrorCode,
+  message: string
+): ErrorResponse {
+  return {
+    error: message,
+    code,
+    timestamp: c.var.clock.now().toISOString(),
+  };
+}
+
+export function errorResponse(
+  c: Context<AppEnv>,
+  status: ContentfulStatusCode,
+  code: ErrorCode,
+  message: string
+) {
+  return respond(c, errorBody(c, code, message), status);
+}
+
+export function validationErrorResponse(
+  c: Context<AppEnv>,
+  errors: FieldError[]
+) {
+  const body: ValidationErrorResponse = {
+    ...errorBody(c, "invalid_request", "Invalid request"),
+    errors,
+  };
+  return respond(c, body, 400);
+}
+
+export function methodNotAllowed(c: Context<AppEnv>) {
+  return errorResponse(c, 405, "method_not_allowed", "Method not allowed");
+}
@@ -0,0 +1,138 @@
+import { Hono } from "hono";
+import { MAX_PLACES, ROUNDING_MODES } from "../services/calculator";
+import { OPERATION_NAMES } from "../services/operations";
+import { MAX_BATCH_SIZE } from "./batch";
+import { errorResponse, methodNotAllowed, respond } from "./response";
+import type { AppEnv, SchemaListResponse } from "../types";
+
+type JsonSchema = Record<string, unknown>;
+
+const JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema";
+
+const number = (description: string): JsonSchema => ({
+  type: "number",
+  description,
+});
+
+// An object of the given properties, all required unless listed in optional.
+function objectSchema(
+  title: string,
+  properties: Record<string, JsonSchema>,
+  optional: readonly string[] = []
+): JsonSchema {
+  return {
+    title,
+    type: "object",
+    required: Object.keys(properties).filter(
+      (name) => !optional.includes(name)
+    ),
+    properties,
+  };
+}
+
+const namedOperationRequest = objectSchema(
+  "NamedOperationRequest",
+  {
+    operation: { type: "string", enum: OPERATION_NAMES },
+    a: number("First operand"),
+    b: number("Second operand; ignored by unary operations"),
+  },
+  ["b"]
+);
+
+// Hand-written JSON Schemas of the request bodies in src/types, served at
+// GET /schema/{name} so clients can validate before sending. Keep them in
+// step with the interfaces and the route parsers; the tests check each
+// schema's required fields against what its endpoint reports missing.
+export const REQUEST_SCHEMAS: Readonly<Record<string, JsonSchema>> = {
+  "operation-request": objectSchema("OperationRequest", {
+    a: number("First operand"),
+    b: number("Second operand"),
+  }),
+  "unary-operation-request": objectSchema("UnaryOperationRequest", {
+    a: number("Operand"),
+  }),
+  "number-list-request": objectSchema("NumberListRequest", {
+    numbers: { type: "array", items: { type: "number" } },
+  }),
+  "weighted-sum-request": objectSchema("WeightedSumRequest", {
+    a: number("First operand"),
+    wa: number("Weight of the first operand"),
+    b: number("Second operand"),
+    wb: number("Weight of the second operand"),
+  }),
+  "convert-request": objectSchema("ConvertRequest", {
+    value: number("Quantity to convert"),
+    scale: number("Multiplier applied to value"),
+    offset: number("Added after scaling"),
+  }),
+  "fma-request": objectSchema("FmaRequest", {
+    a: number("Multiplicand"),
+    b: number("Multiplier"),
+    c: number("Added to the exact product"),
+  }),
+  "rpn-request": objectSchema("RpnRequest", {
+    tokens: {
+      type: "array",
+      items: { type: "string" },
+      description: "Numbers and the operators +, -, * and / in postfix order",
+    },
+  }),
+  "clamp-request": objectSchema("ClampRequest", {
+    value: number("Number to bound"),
+    min: number("Lower bound; must not exceed max"),
+    max: number("Upper bound"),
+  }),
+  "polynomial-request": objectSchema("PolynomialRequest", {
+    coefficients: {
+      type: "array",
+      items: { type: "number" },
+      minItems: 1,
+      description: "Coefficients, highest degree first",
+    },
+    x: number("Point at which to evaluate the polynomial"),
+  }),
+  "round-request": objectSchema(
+    "RoundRequest",
+    {
+      value: number("Number to round"),
+      places: { type: "integer", minimum: 0, maximum: MAX_PLACES },
+      mode: { type: "string", enum: ROUNDING_MODES, default: "half_even" },
+    },
+    ["mode"]
+  ),
+  "named-operation-request": namedOperationRequest,
+  "batch-request": objectSchema("BatchRequest", {
+    operations: {
+      type: "array",
+      maxItems: MAX_BATCH_SIZE,
+      items: namedOperationRequest,
+    },
+  }),
+};
+
+const schema = new Hono<AppEnv>();
+
+// Names of the available schemas.
+schema.get("/schema", (c) => {
+  const response: SchemaListResponse = {
+    schemas: Object.keys(REQUEST_SCHEMAS),
+  };
+  return respond(c, response);
+});
+
+schema.get("/schema/:name", (c) => {
+  const name = c.req.param("name");
+  if (!Object.hasOwn(REQUEST_SCHEMAS, name)) {
+    return errorResponse(c, 404, "not_found", `No schema named ${name}`);
+  }
+  const document = { $schema: JSON_SCHEMA_DIALECT, ...REQUEST_SCHEMAS[name] };
+  return c.body(JSON.stringify(document), 200, {
+    "Content-Type": "application/schema+json",
+  });
+});
+
+schema.all("/schema", methodNotAllowed);
+schema.all("/schema/:name", methodNotAllowed);
+
+export { schema };
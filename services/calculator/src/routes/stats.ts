@@ -0,0 +1,14 @@
+import { Hono } from "hono";
+import { methodNotAllowed, respond } from "./response";
+import type { AppEnv, StatsResponse } from "../types";
+
+const stats = new Hono<AppEnv>();
+
+stats.get("/stats", (c) => {
+  const response: StatsResponse = c.var.stats.snapshot();
+  return respond(c, response);
+});
+
+stats.all("/stats", methodNotAllowed);
+
+export { stats };
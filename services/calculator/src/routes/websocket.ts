@@ -0,0 +1,62 @@
+import { Hono } from "hono";
+import type { Context } from "hono";
+import { evaluateOperation } from "./evaluate";
+import { errorBody, errorResponse, methodNotAllowed } from "./response";
+import type { AppEnv, ErrorResponse, OperationResponse } from "../types";
+
+const websocket = new Hono<AppEnv>();
+
+// Evaluates one inbound WebSocket message and returns the reply body. Errors
+// are reported in the reply rather than thrown so the connection stays open,
+// except unexpected ones under the "panic" error policy.
+export async function evaluateMessage(
+  c: Context<AppEnv>,
+  data: string | ArrayBuffer
+): Promise<OperationResponse | ErrorResponse> {
+  if (typeof data !== "string") {
+    return errorBody(c, "invalid_request", "Messages must be text");
+  }
+
+  let message: unknown;
+  try {
+    message = JSON.parse(data);
+  } catch {
+    return errorBody(c, "malformed_json", "Malformed JSON");
+  }
+  return evaluateOperation(c, message);
+}
+
+// Each text message is an operation such as {"operation":"add","a":1,"b":2}
+// and receives exactly one reply. An unexpected error under the "panic"
+// error policy has no app error handler to reach, so it is logged with its
+// stack here and the connection closed with 1011.
+websocket.get("/ws", (c) => {
+  if (c.req.header("Upgrade")?.toLowerCase() !== "websocket") {
+    return errorResponse(
+      c,
+      426,
+      "upgrade_required",
+      "Expected WebSocket upgrade"
+    );
+  }
+
+  const [client, server] = Object.values(new WebSocketPair());
+  server.accept();
+  server.addEventListener("message", async (event) => {
+    try {
+      server.send(JSON.stringify(await evaluateMessage(c, event.data)));
+    } catch (error) {
+      console.error(
+        "Unhandled error:",
+        error instanceof Error ? error.stack : error
+      );
+      server.close(1011, "Internal server error");
+    }
+  });
+
+  return new Response(null, { status: 101, webSocket: client });
+});
+
+websocket.all("/ws", methodNotAllowed);
+
+export { websocket };
@@ -0,0 +1,150 @@
+import type { Clock } from "./clock";
+import type {
+  Awaitable,
+  CalculatorService,
+  OperationResult,
+} from "./operations";
+
+// One operation, as written to the audit log.
+export interface AuditEntry {
+  // "warn" for operations the service rejected, e.g. on overflow.
+  level: "info" | "warn";
+  message: "operation";
+  operation: string;
+  operands: number[];
+  // Exactly one of result and error is present.
+  result?: OperationResult;
+  error?: string;
+  timestamp: string;
+}
+
+export interface AuditOptions {
+  // Log one in every sampleRate successful operations, e.g. 100 for 1%.
+  // Failed operations are always logged. Defaults to 1, logging every one.
+  sampleRate?: number;
+  // Receives each entry. Defaults to console.info.
+  log?: (entry: AuditEntry) => void;
+}
+
+// Wraps a service so that its operations are logged for auditing. Logging
+// every operation is costly at high throughput, so with a sampleRate of N
+// only the first of every N successful operations is logged; failures are
+// rarer and more interesting, so each one is. The counter is shared by all
+// operations, and a Worker isolate runs one task at a time, so it needs no
+// synchronization.
+export function auditOperations(
+  service: CalculatorService,
+  clock: Clock,
+  options: AuditOptions = {}
+): CalculatorService {
+  const sampleRate = options.sampleRate ?? 1;
+  if (!Number.isInteger(sampleRate) || sampleRate < 1) {
+    throw new RangeError("audit sample rate must be a positive integer");
+  }
+  const log = options.log ?? ((entry) => console.info(entry));
+  let succeeded = 0;
+
+  const audit = async <T extends OperationResult>(
+    operation: string,
+    operands: number[],
+    run: () => Awaitable<T>
+  ): Promise<T> => {
+    const timestamp = clock.now().toISOString();
+    let result: T;
+    try {
+      result = await run();
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      log({
+        level: "warn",
+        message: "operation",
+        operation,
+        operands: [...operands],
+        error: message,
+        timestamp,
+      });
+      throw error;
+    }
+    if (succeeded++ % sampleRate === 0) {
+      log({
+        level: "info",
+        message: "operation",
+        operation,
+        operands: [...operands],
+        result,
+        timestamp,
+      });
+    }
+    return result;
+  };
+
+  return {
+    add: (a, b, signal) =>
+      audit("add", [a, b], () => service.add(a, b, signal)),
+    subtract: (a, b, signal) =>
+      audit("subtract", [a, b], () => service.subtract(a, b, signal)),
+    multiply: (a, b, signal) =>
+      audit("multiply", [a, b], () => service.multiply(a, b, signal)),
+    divide: (a, b, signal) =>
+      audit("divide", [a, b], () => service.divide(a, b, signal)),
+    divmod: (a, b, signal) =>
+      audit("divmod", [a, b], () => service.divmod(a, b, signal)),
+    hypot: (a, b, signal) =>
+      audit("hypot", [a, b], () => service.hypot(a, b, signal)),
+    diff: (a, b, signal) =>
+      audit("diff", [a, b], () => service.diff(a, b, signal)),
+    gcd: (a, b, signal) =>
+      audit("gcd", [a, b], () => service.gcd(a, b, signal)),
+    lcm: (a, b, signal) =>
+      audit("lcm", [a, b], () => service.lcm(a, b, signal)),
+    percentChange: (a, b, signal) =>
+      audit("percentChange", [a, b], () =>
+        service.percentChange(a, b, signal)
+      ),
+    addMany: (numbers, signal) =>
+      audit("addMany", numbers, () => service.addMany(numbers, signal)),
+    kahanSum: (numbers, signal) =>
+      audit("kahanSum", numbers, () => service.kahanSum(numbers, signal)),
+    multiplyMany: (numbers, signal) =>
+      audit("multiplyMany", numbers, () =>
+        service.multiplyMany(numbers, signal)
+      ),
+    summary: (numbers, signal) =>
+      audit("summary", numbers, () => service.summary(numbers, signal)),
+    weightedSum: (a, wa, b, wb, signal) =>
+      audit("weightedSum", [a, wa, b, wb], () =>
+        service.weightedSum(a, wa, b, wb, signal)
+      ),
+    evalPolynomial: (coefficients, x, signal) =>
+      audit("evalPolynomial", [...coefficients, x], () =>
+        service.evalPolynomial(coefficients, x, signal)
+      ),
+    convert: (value, scale, offset, signal) =>
+      audit("convert", [value, scale, offset], () =>
+        service.convert(value, scale, offset, signal)
+      ),
+    fma: (a, b, c, signal) =>
+      audit("fma", [a, b, c], () => service.fma(a, b, c, signal)),
+    clamp: (value, min, max, signal) =>
+      audit("clamp", [value, min, max], () =>
+        service.clamp(value, min, max, signal)
+      ),
+    compare: (a, b, signal) =>
+      audit("compare", [a, b], () => service.compare(a, b, signal)),
+    approxEqual: (a, b, epsilon, signal) =>
+      audit("approxEqual", [a, b, epsilon], () =>
+        service.approxEqual(a, b, epsilon, signal)
+      ),
+    round: (value, places, mode, signal) =>
+      audit("round", [value, places], () =>
+        service.round(value, places, mode, signal)
+      ),
+    sin: (a, signal) => audit("sin", [a], () => service.sin(a, signal)),
+    cos: (a, signal) => audit("cos", [a], () => service.cos(a, signal)),
+    tan: (a, signal) => audit("tan", [a], () => service.tan(a, signal)),
+    log: (a, signal) => audit("log", [a], () => service.log(a, signal)),
+    ln: (a, signal) => audit("ln", [a], () => service.ln(a, signal)),
+    factorial: (a, signal) =>
+      audit("factorial", [a], () => service.factorial(a, signal)),
+  };
+}
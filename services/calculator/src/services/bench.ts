@@ -0,0 +1,31 @@
+import type { BinaryOperationName, CalculatorService } from "./operations";
+
+export interface BenchmarkResult {
+  operation: BinaryOperationName;
+  iterations: number;
+  durationMs: number;
+  // Null when no time was measured to elapse; see runBenchmark.
+  opsPerSecond: number | null;
+}
+
+// Calls the operation iterations times in sequence and reports throughput.
+// Workers may freeze timers during pure computation (a Spectre mitigation),
+// in which case durationMs is 0 and opsPerSecond is null.
+export async function runBenchmark(
+  service: CalculatorService,
+  operation: BinaryOperationName,
+  iterations: number,
+  now: () => number = () => performance.now()
+): Promise<BenchmarkResult> {
+  const start = now();
+  for (let i = 0; i < iterations; i++) {
+    await service[operation](i, 1);
+  }
+  const durationMs = now() - start;
+  return {
+    operation,
+    iterations,
+    durationMs,
+    opsPerSecond: durationMs > 0 ? (iterations / durationMs) * 1000 : null,
+  };
+}
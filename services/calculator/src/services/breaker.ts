@@ -0,0 +1,89 @@
+import type { Clock } from "./clock";
+
+export const DEFAULT_FAILURE_THRESHOLD = 5;
+export const DEFAULT_RESET_TIMEOUT_MS = 30_000;
+
+// "closed" passes calls through, "open" fails them fast, and "half_open"
+// lets one trial call through to see whether the dependency has recovered.
+export type CircuitState = "closed" | "open" | "half_open";
+
+export interface CircuitBreakerOptions {
+  // Consecutive failures that open the circuit. Defaults to 5.
+  failureThreshold?: number;
+  // Milliseconds the circuit stays open before a trial call is let through.
+  // Defaults to 30 seconds.
+  resetTimeoutMs?: number;
+}
+
+// Thrown instead of making a call while the circuit is open.
+export class CircuitOpenError extends Error {
+  constructor() {
+    super("Upstream calculator unavailable");
+    this.name = "CircuitOpenError";
+  }
+}
+
+// Stops calling a failing dependency for a while, so requests fail at once
+// rather than each waiting on it. After failureThreshold consecutive
+// failures the circuit opens; once resetTimeoutMs has passed, the next call
+// is a trial, closing the circuit if it succeeds and reopening it if not.
+// Other calls made during the trial fail fast.
+export class CircuitBreaker {
+  private readonly clock: Clock;
+  private readonly failureThreshold: number;
+  private readonly resetTimeoutMs: number;
+  private failures = 0;
+  private openedAt: number | undefined;
+  private trialInFlight = false;
+
+  constructor(clock: Clock, options: CircuitBreakerOptions = {}) {
+    this.clock = clock;
+    this.failureThreshold =
+      options.failureThreshold ?? DEFAULT_FAILURE_THRESHOLD;
+    this.resetTimeoutMs = options.resetTimeoutMs ?? DEFAULT_RESET_TIMEOUT_MS;
+    if (!Number.isInteger(this.failureThreshold) || this.failureThreshold < 1) {
+      throw new RangeError("failure threshold must be a positive integer");
+    }
+  }
+
+  get state(): CircuitState {
+    if (this.openedAt === undefined) {
+      return "closed";
+    }
+    const elapsed = this.clock.now().getTime() - this.openedAt;
+    return elapsed >= this.resetTimeoutMs ? "half_open" : "open";
+  }
+
+  // Runs operation unless the circuit is open. isFailure decides which
+  // errors count against the dependency; by default all do. Errors that do
+  // not count are rethrown without affecting the circuit.
+  async call<T>(
+    operation: () => Promise<T>,
+    isFailure: (error: unknown) => boolean = () => true
+  ): Promise<T> {
+    const state = this.state;
+    if (state === "open" || (state === "half_open" && this.trialInFlight)) {
+      throw new CircuitOpenError();
+    }
+    const trial = state === "half_open";
+    this.trialInFlight = trial;
+    try {
+      const result = await operation();
+      this.failures = 0;
+      this.openedAt = undefined;
+      return result;
+    } catch (error) {
+      if (isFailure(error)) {
+        this.failures++;
+        if (trial || this.failures >= this.failureThreshold) {
+          this.openedAt = this.clock.now().getTime();
+        }
+      }
+      throw error;
+    } finally {
+      if (trial) {
+        this.trialInFlight = false;
+      }
+    }
+  }
+}
@@ -0,0 +1,156 @@
+import { computationKey } from "./coalesce";
+import type { Clock } from "./clock";
+import type { Lifecycle } from "./lifecycle";
+import type { Metrics } from "./metrics";
+import type {
+  Awaitable,
+  CalculatorService,
+  OperationResult,
+} from "./operations";
+
+export const DEFAULT_CACHE_MAX_ENTRIES = 1000;
+
+export interface CacheOptions {
+  // Milliseconds a result is served from the cache after it was computed.
+  ttlMs: number;
+  // Results kept at most; the oldest is evicted to make room. Defaults to
+  // DEFAULT_CACHE_MAX_ENTRIES.
+  maxEntries?: number;
+}
+
+interface CacheEntry {
+  result: OperationResult;
+  expiresAt: number;
+}
+
+// Wraps a service so that successful results are remembered for
+// options.ttlMs and repeated calls with the same operation and operands are
+// answered without calling it, counting hits and misses in metrics. Errors
+// are not cached. Expired entries are dropped when read, and swept before
+// the cache would grow past options.maxEntries, so it stays bounded however
+// many distinct calls it sees. A Worker isolate runs one task at a time, so
+// the map needs no locking between concurrent requests; concurrent misses
+// for one key each call the service, which coalesce() prevents. Given a
+// lifecycle, the cache is emptied at shutdown by a hook named "cache".
+export function cacheResults(
+  service: CalculatorService,
+  clock: Clock,
+  metrics: Metrics,
+  options: CacheOptions,
+  lifecycle?: Lifecycle
+): CalculatorService {
+  const entries = new Map<string, CacheEntry>();
+  lifecycle?.onShutdown({ name: "cache", run: () => entries.clear() });
+  const maxEntries = options.maxEntries ?? DEFAULT_CACHE_MAX_ENTRIES;
+
+  const store = (key: string, result: OperationResult) => {
+    const now = clock.now().getTime();
+    entries.delete(key);
+    if (entries.size >= maxEntries) {
+      for (const [other, entry] of entries) {
+        if (entry.expiresAt <= now) {
+          entries.delete(other);
+        }
+      }
+    }
+    // Maps iterate in insertion order, so the first key is the oldest.
+    if (entries.size >= maxEntries) {
+      entries.delete(entries.keys().next().value as string);
+    }
+    entries.set(key, { result, expiresAt: now + options.ttlMs });
+  };
+
+  // Keys start with the operation name, so a cached result is always of the
+  // type the caller's operation returns.
+  const remember = <T extends OperationResult>(
+    name: string,
+    operands: number[],
+    run: () => Awaitable<T>
+  ): Awaitable<T> => {
+    const key = computationKey(name, operands);
+    const entry = entries.get(key);
+    if (entry !== undefined && entry.expiresAt > clock.now().getTime()) {
+      metrics.cacheHits.inc();
+      return entry.result as T;
+    }
+    entries.delete(key);
+    metrics.cacheMisses.inc();
+    return Promise.resolve()
+      .then(run)
+      .then((result) => {
+        store(key, result);
+        return result;
+      });
+  };
+
+  return {
+    add: (a, b, signal) =>
+      remember("add", [a, b], () => service.add(a, b, signal)),
+    subtract: (a, b, signal) =>
+      remember("subtract", [a, b], () => service.subtract(a, b, signal)),
+    multiply: (a, b, signal) =>
+      remember("multiply", [a, b], () => service.multiply(a, b, signal)),
+    divide: (a, b, signal) =>
+      remember("divide", [a, b], () => service.divide(a, b, signal)),
+    divmod: (a, b, signal) =>
+      remember("divmod", [a, b], () => service.divmod(a, b, signal)),
+    hypot: (a, b, signal) =>
+      remember("hypot", [a, b], () => service.hypot(a, b, signal)),
+    diff: (a, b, signal) =>
+      remember("diff", [a, b], () => service.diff(a, b, signal)),
+    gcd: (a, b, signal) =>
+      remember("gcd", [a, b], () => service.gcd(a, b, signal)),
+    lcm: (a, b, signal) =>
+      remember("lcm", [a, b], () => service.lcm(a, b, signal)),
+    percentChange: (a, b, signal) =>
+      remember("percentChange", [a, b], () =>
+        service.percentChange(a, b, signal)
+      ),
+    addMany: (numbers, signal) =>
+      remember("addMany", numbers, () => service.addMany(numbers, signal)),
+    kahanSum: (numbers, signal) =>
+      remember("kahanSum", numbers, () => service.kahanSum(numbers, signal)),
+    multiplyMany: (numbers, signal) =>
+      remember("multiplyMany", numbers, () =>
+        service.multiplyMany(numbers, signal)
+      ),
+    summary: (numbers, signal) =>
+      remember("summary", numbers, () => service.summary(numbers, signal)),
+    weightedSum: (a, wa, b, wb, signal) =>
+      remember("weightedSum", [a, wa, b, wb], () =>
+        service.weightedSum(a, wa, b, wb, signal)
+      ),
+    evalPolynomial: (coefficients, x, signal) =>
+      remember("evalPolynomial", [...coefficients, x], () =>
+        service.evalPolynomial(coefficients, x, signal)
+      ),
+    convert: (value, scale, offset, signal) =>
+      remember("convert", [value, scale, offset], () =>
+        service.convert(value, scale, offset, signal)
+      ),
+    fma: (a, b, c, signal) =>
+      remember("fma", [a, b, c], () => service.fma(a, b, c, signal)),
+    clamp: (value, min, max, signal) =>
+      remember("clamp", [value, min, max], () =>
+        service.clamp(value, min, max, signal)
+      ),
+    compare: (a, b, signal) =>
+      remember("compare", [a, b], () => service.compare(a, b, signal)),
+    approxEqual: (a, b, epsilon, signal) =>
+      remember("approxEqual", [a, b, epsilon], () =>
+        service.approxEqual(a, b, epsilon, signal)
+      ),
+    // The mode is part of the name, as results differ between modes.
+    round: (value, places, mode, signal) =>
+      remember(`round:${mode}`, [value, places], () =>
+        service.round(value, places, mode, signal)
+      ),
+    sin: (a, signal) => remember("sin", [a], () => service.sin(a, signal)),
+    cos: (a, signal) => remember("cos", [a], () => service.cos(a, signal)),
+    tan: (a, signal) => remember("tan", [a], () => service.tan(a, signal)),
+    log: (a, signal) => remember("log", [a], () => service.log(a, signal)),
+    ln: (a, signal) => remember("ln", [a], () => service.ln(a, signal)),
+    factorial: (a, signal) =>
+      remember("factorial", [a], () => service.factorial(a, signal)),
+  };
+}
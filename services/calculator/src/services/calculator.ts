@@ -7,23 +7,569 @@ export class InvalidInputError extends Error {
   }
 }
 
-export function validateInputs(a: number, b: number): void {
-  if (!Number.isFinite(a) || !Number.isFinite(b)) {
+// Thrown when finite operands produce a result too large to represent.
+export class OverflowError extends InvalidInputError {
+  constructor(message: string = "invalid input: result overflowed") {
+    super(message);
+    this.name = "OverflowError";
+  }
+}
+
+export class DivisionByZeroError extends InvalidInputError {
+  constructor() {
+    super("invalid input: division by zero");
+    this.name = "DivisionByZeroError";
+  }
+}
+
+// Thrown when an integer operation is given an operand with a fractional
+// part.
+export class NonIntegerError extends InvalidInputError {
+  constructor() {
+    super("invalid input: operands must be integers");
+    this.name = "NonIntegerError";
+  }
+}
+
+export function validateInputs(...operands: number[]): void {
+  validateInputList(operands);
+}
+
+// validateInputs for a list of any length, such as the numbers a client
+// posts to /add/many. Spreading hundreds of thousands of them into a call
+// would overflow the stack, so list operations take and check arrays.
+export function validateInputList(operands: readonly number[]): void {
+  if (!operands.every(Number.isFinite)) {
     throw new InvalidInputError();
   }
 }
 
+// Domain rule for logarithms. Also registered by name in the default
+// validator registry.
+export function validatePositive(a: number): void {
+  if (a <= 0) {
+    throw new InvalidInputError(
+      "invalid input: logarithm requires a positive operand"
+    );
+  }
+}
+
+// Domain rule for division. Also registered by name in the default validator
+// registry.
+export function validateNonZeroDivisor(b: number): void {
+  if (b === 0) {
+    throw new DivisionByZeroError();
+  }
+}
+
+// Within a bit of this many log2 bits of magnitude, a product may or may not
+// exceed Number.MAX_VALUE (just under 2^1024).
+const MAX_EXPONENT = 1024;
+const SCALE = 2 ** -512;
+
+// True if |a * b| would round to Infinity. Products well clear of the limit
+// are decided from log magnitudes alone. Near it, one operand is at least
+// 2^511, so scaling that one by 2^-512 is exact and the scaled product
+// rounds exactly as the real one would.
+function productOverflows(a: number, b: number): boolean {
+  const x = Math.abs(a);
+  const y = Math.abs(b);
+  if (x === 0 || y === 0) {
+    return false;
+  }
+  const bits = Math.log2(x) + Math.log2(y);
+  if (bits < MAX_EXPONENT - 1) {
+    return false;
+  }
+  if (bits > MAX_EXPONENT + 1) {
+    return true;
+  }
+  const scaled = x >= y ? x * SCALE * y : y * SCALE * x;
+  return scaled > Number.MAX_VALUE * SCALE;
+}
+
+// Domain rule for multiplication, checked before computing so that an
+// overflowing product is reported as such rather than as a generic overflow.
+// Registered in the default validator registry; multiply itself still catches
+// overflow after the fact.
+export function validateProductMagnitude(a: number, b: number): void {
+  if (productOverflows(a, b)) {
+    throw new OverflowError(
+      "invalid input: product magnitude exceeds the largest finite number"
+    );
+  }
+}
+
+// Domain rule for gcd and lcm. Also registered by name in the default
+// validator registry.
+export function validateIntegers(...operands: number[]): void {
+  if (!operands.every(Number.isInteger)) {
+    throw new NonIntegerError();
+  }
+}
+
+// Largest operand factorial accepts. 10000! already has 35,660 digits, and
+// larger operands would let a single request burn noticeable CPU.
+export const MAX_FACTORIAL_OPERAND = 10_000;
+
+// Domain rule for factorial. Also registered by name in the default validator
+// registry.
+export function validateFactorialOperand(a: number): void {
+  validateIntegers(a);
+  if (a < 0) {
+    throw new InvalidInputError(
+      "invalid input: factorial requires a non-negative operand"
+    );
+  }
+  if (a > MAX_FACTORIAL_OPERAND) {
+    throw new InvalidInputError(
+      `invalid input: factorial operand exceeds ${MAX_FACTORIAL_OPERAND}`
+    );
+  }
+}
+
+// Finite operands can still overflow to ±Infinity, so arithmetic results are
+// checked before they are returned.
+function checkResult(result: number): number {
+  if (!Number.isFinite(result)) {
+    throw new OverflowError();
+  }
+  return result;
+}
+
 export function add(a: number, b: number): number {
   validateInputs(a, b);
-  return a + b;
+  return checkResult(a + b);
 }
 
 export function subtract(a: number, b: number): number {
   validateInputs(a, b);
-  return a - b;
+  return checkResult(a - b);
 }
 
 export function multiply(a: number, b: number): number {
   validateInputs(a, b);
-  return a * b;
+  return checkResult(a * b);
+}
+
+export function divide(a: number, b: number): number {
+  validateInputs(a, b);
+  validateNonZeroDivisor(b);
+  return checkResult(a / b);
+}
+
+export interface DivMod {
+  quotient: number;
+  remainder: number;
+}
+
+// Truncating division: the quotient is rounded toward zero and the remainder
+// takes the sign of a, so a === quotient * b + remainder. divmod(-7, 2) is
+// { quotient: -3, remainder: -1 }, not the floored -4 and 1.
+export function divmod(a: number, b: number): DivMod {
+  validateInputs(a, b);
+  validateNonZeroDivisor(b);
+  return { quotient: Math.trunc(checkResult(a / b)), remainder: a % b };
+}
+
+// Most decimal places a result may be rounded to. Doubles carry about 15
+// significant digits, so more would only expose representation error.
+export const MAX_PLACES = 15;
+
+// How round() picks between the two nearest values. The half_ modes differ
+// only on ties: half_even goes to the even digit, half_up away from zero and
+// half_down toward zero. ceil, floor and trunc ignore ties and round toward
+// +Infinity, -Infinity and zero respectively.
+export type RoundingMode =
+  | "half_even"
+  | "half_up"
+  | "half_down"
+  | "ceil"
+  | "floor"
+  | "trunc";
+
+export const ROUNDING_MODES: readonly RoundingMode[] = [
+  "half_even",
+  "half_up",
+  "half_down",
+  "ceil",
+  "floor",
+  "trunc",
+];
+
+export function isRoundingMode(mode: unknown): mode is RoundingMode {
+  return ROUNDING_MODES.includes(mode as RoundingMode);
+}
+
+// Domain rule for rounding. Also registered by name in the default validator
+// registry.
+export function validatePlaces(places: number): void {
+  if (!Number.isInteger(places) || places < 0 || places > MAX_PLACES) {
+    throw new InvalidInputError(
+      `invalid input: places must be an integer from 0 to ${MAX_PLACES}`
+    );
+  }
+}
+
+// Rounds scaled, a value already multiplied up to the target scale, to an
+// integer.
+function roundScaled(scaled: number, mode: RoundingMode): number {
+  switch (mode) {
+    case "ceil":
+      return Math.ceil(scaled);
+    case "floor":
+      return Math.floor(scaled);
+    case "trunc":
+      return Math.trunc(scaled);
+  }
+  const floor = Math.floor(scaled);
+  if (scaled - floor !== 0.5) {
+    return Math.round(scaled);
+  }
+  if (mode === "half_even") {
+    return floor % 2 === 0 ? floor : floor + 1;
+  }
+  const awayFromZero = scaled > 0 ? floor + 1 : floor;
+  const towardZero = scaled > 0 ? floor : floor + 1;
+  return mode === "half_up" ? awayFromZero : towardZero;
+}
+
+// Rounds value to the given number of decimal places using mode, so
+// round(2.5, 0, "half_even") is 2 and round(-2.5, 0, "half_up") is -3.
+export function round(
+  value: number,
+  places: number,
+  mode: RoundingMode
+): number {
+  validateInputs(value);
+  validatePlaces(places);
+  const factor = 10 ** places;
+  const product = value * factor;
+  // Beyond 2^53 the value has no digits at this scale left to round.
+  if (Math.abs(product) >= Number.MAX_SAFE_INTEGER) {
+    return value;
+  }
+  // Scaling is inexact (2.675 * 100 is 267.49999999999997), so snap away the
+  // representation error before looking for a tie or a boundary.
+  const scaled = Number(product.toFixed(8));
+  return roundScaled(scaled, mode) / factor;
+}
+
+// Rounds value to the given number of decimal places, breaking ties towards
+// the even digit (banker's rounding), so 0.125 becomes 0.12 at two places.
+export function roundHalfEven(value: number, places: number): number {
+  return round(value, places, "half_even");
+}
+
+// Replaces negative zero with positive zero, so results such as -1 * 0 or
+// 0 / -5 read as plain 0 to clients. JSON cannot carry the sign of zero, so
+// responses are given the value they would serialize as anyway.
+export function normalizeResult(value: number): number {
+  return value === 0 ? 0 : value;
+}
+
+// Length of the hypotenuse, sqrt(a² + b²), without intermediate overflow. The
+// result itself can still exceed the largest finite number.
+export function hypot(a: number, b: number): number {
+  validateInputs(a, b);
+  return checkResult(Math.hypot(a, b));
+}
+
+// Distance between a and b on the number line, |a - b|. The subtraction can
+// overflow for far-apart operands of opposite sign.
+export function absDiff(a: number, b: number): number {
+  validateInputs(a, b);
+  return checkResult(Math.abs(a - b));
+}
+
+// Greatest common divisor by the Euclidean algorithm, always non-negative.
+// gcd(a, 0) is |a|, and gcd(0, 0) is 0.
+export function gcd(a: number, b: number): number {
+  validateInputs(a, b);
+  validateIntegers(a, b);
+  let x = Math.abs(a);
+  let y = Math.abs(b);
+  while (y !== 0) {
+    [x, y] = [y, x % y];
+  }
+  return x;
+}
+
+// Least common multiple, always non-negative; 0 if either operand is 0. A
+// result above Number.MAX_SAFE_INTEGER could not be exact, so it is reported
+// as an overflow.
+export function lcm(a: number, b: number): number {
+  validateInputs(a, b);
+  validateIntegers(a, b);
+  if (a === 0 || b === 0) {
+    return 0;
+  }
+  const result = Math.abs((a / gcd(a, b)) * b);
+  if (result > Number.MAX_SAFE_INTEGER) {
+    throw new OverflowError();
+  }
+  return result;
+}
+
+// Change from a to b as a percentage of a, ((b - a) / a) * 100, so
+// percentChange(50, 75) is 50 and percentChange(50, 25) is -50. A zero a is
+// division by zero, and a tiny one can overflow the result.
+export function percentChange(a: number, b: number): number {
+  validateInputs(a, b);
+  validateNonZeroDivisor(a);
+  return checkResult(((b - a) / a) * 100);
+}
+
+// Sums any number of operands; the empty sum is 0.
+export function addMany(numbers: readonly number[]): number {
+  validateInputList(numbers);
+  return checkResult(numbers.reduce((sum, n) => sum + n, 0));
+}
+
+// Sums any number of operands with compensated summation, carrying the
+// low-order bits each addition rounds away into a correction term. Unlike
+// addMany, small operands are not lost next to large ones: 1e16 plus ten 1s
+// is 1e16 + 10 rather than 1e16. Uses Neumaier's variant of Kahan's
+// algorithm, which also holds when an operand is larger than the running sum.
+export function kahanSum(numbers: readonly number[]): number {
+  validateInputList(numbers);
+  let sum = 0;
+  let compensation = 0;
+  for (const n of numbers) {
+    const total = sum + n;
+    compensation +=
+      Math.abs(sum) >= Math.abs(n) ? sum - total + n : n - total + sum;
+    sum = total;
+  }
+  return checkResult(sum + compensation);
+}
+
+export interface Summary {
+  count: number;
+  mean: number;
+  // Population variance: the mean squared deviation from the mean.
+  variance: number;
+  stddev: number;
+  min: number;
+  max: number;
+}
+
+// Describes a non-empty list in one pass with Welford's algorithm, which
+// updates the mean and the sum of squared deviations per element instead of
+// subtracting sums of squares, so the variance of large, close values does
+// not cancel to nonsense.
+export function summarize(numbers: readonly number[]): Summary {
+  validateInputList(numbers);
+  if (numbers.length === 0) {
+    throw new InvalidInputError(
+      "invalid input: summary requires at least one number"
+    );
+  }
+  let mean = 0;
+  let squaredDeviations = 0;
+  let min = Infinity;
+  let max = -Infinity;
+  for (const [index, n] of numbers.entries()) {
+    const delta = n - mean;
+    mean += delta / (index + 1);
+    squaredDeviations += delta * (n - mean);
+    min = Math.min(min, n);
+    max = Math.max(max, n);
+  }
+  const variance = checkResult(squaredDeviations / numbers.length);
+  return {
+    count: numbers.length,
+    mean: checkResult(mean),
+    variance,
+    stddev: Math.sqrt(variance),
+    min,
+    max,
+  };
+}
+
+// Multiplies any number of operands; the empty product is 1.
+export function multiplyMany(numbers: readonly number[]): number {
+  validateInputList(numbers);
+  return checkResult(numbers.reduce((product, n) => product * n, 1));
+}
+
+// Computes a*wa + b*wb.
+export function weightedSum(
+  a: number,
+  wa: number,
+  b: number,
+  wb: number
+): number {
+  validateInputs(a, wa, b, wb);
+  return checkResult(a * wa + b * wb);
+}
+
+// Domain rule for evalPolynomial. Also registered by name in the default
+// validator registry.
+export function validateCoefficients(coefficients: readonly number[]): void {
+  if (coefficients.length === 0) {
+    throw new InvalidInputError(
+      "invalid input: polynomial requires at least one coefficient"
+    );
+  }
+}
+
+// Evaluates the polynomial with the given coefficients, highest degree first,
+// at x by Horner's method: [2, 3, 1] is 2x² + 3x + 1, computed as
+// (2x + 3)x + 1. That takes one multiplication per coefficient instead of
+// raising x to every power. Each step is checked, so a running value that
+// overflows fails rather than carrying Infinity into the next step, where a
+// later term could turn it into NaN.
+export function evalPolynomial(
+  coefficients: readonly number[],
+  x: number
+): number {
+  validateInputList(coefficients);
+  validateInputs(x);
+  validateCoefficients(coefficients);
+  let result = 0;
+  for (const coefficient of coefficients) {
+    result = checkResult(result * x + coefficient);
+  }
+  return result;
+}
+
+// A finite float64 as mantissa * 2^exponent with an integer mantissa.
+function decompose(x: number): { mantissa: bigint; exponent: number } {
+  const view = new DataView(new ArrayBuffer(8));
+  view.setFloat64(0, x);
+  const bits = view.getBigUint64(0);
+  const biased = Number((bits >> 52n) & 0x7ffn);
+  const fraction = bits & 0xfffffffffffffn;
+  // Subnormals have no implicit leading bit and the exponent of the
+  // smallest normals.
+  const mantissa = biased === 0 ? fraction : fraction | (1n << 52n);
+  return {
+    mantissa: bits >> 63n === 1n ? -mantissa : mantissa,
+    exponent: Math.max(biased, 1) - 1075,
+  };
+}
+
+// Nearest float64 to n * 2^exponent, ties to even, rounding only once.
+function toNearestFloat(n: bigint, exponent: number): number {
+  const magnitude = n < 0n ? -n : n;
+  const length = magnitude.toString(2).length;
+  // Exponent of the last bit kept: 53 significant bits, or fewer where the
+  // result is subnormal.
+  const last = Math.max(length + exponent - 53, -1074);
+  const shift = last - exponent;
+  let kept = magnitude;
+  if (shift > 0) {
+    const dropped = magnitude & ((1n << BigInt(shift)) - 1n);
+    const half = 1n << BigInt(shift - 1);
+    kept = magnitude >> BigInt(shift);
+    if (dropped > half || (dropped === half && (kept & 1n) === 1n)) {
+      kept += 1n;
+    }
+  }
+  const result = Number(kept) * 2 ** (shift > 0 ? last : exponent);
+  return n < 0n ? -result : result;
+}
+
+// Computes a*b + c with a single rounding, like C's fma(): the product is
+// not rounded before c is added, so e.g. fma(a, a, -(a*a)) recovers the
+// rounding error of a*a where the naive expression gives 0.
+export function fma(a: number, b: number, c: number): number {
+  validateInputs(a, b, c);
+  const x = decompose(a);
+  const y = decompose(b);
+  const z = decompose(c);
+  const productExponent = x.exponent + y.exponent;
+  const exponent = Math.min(productExponent, z.exponent);
+  const sum =
+    ((x.mantissa * y.mantissa) << BigInt(productExponent - exponent)) +
+    (z.mantissa << BigInt(z.exponent - exponent));
+  if (sum === 0n) {
+    // An exact zero; the naive expression has the right sign.
+    return a * b + c;
+  }
+  return checkResult(toNearestFloat(sum, exponent));
+}
+
+// Linear unit conversion, value*scale + offset; e.g. scale 1.8 and offset 32
+// convert Celsius to Fahrenheit.
+export function convert(value: number, scale: number, offset: number): number {
+  validateInputs(value, scale, offset);
+  return checkResult(value * scale + offset);
+}
+
+// value bounded to [min, max]: min when below it, max when above it.
+export function clamp(value: number, min: number, max: number): number {
+  validateInputs(value, min, max);
+  if (min > max) {
+    throw new InvalidInputError("invalid input: min must not exceed max");
+  }
+  return Math.min(Math.max(value, min), max);
+}
+
+// Sign of a - b: -1 when a < b, 0 when equal, 1 when a > b. Compared
+// directly rather than by subtracting, so huge operands of opposite sign
+// cannot overflow. -0 and 0 are equal.
+export function compare(a: number, b: number): -1 | 0 | 1 {
+  validateInputs(a, b);
+  if (a < b) {
+    return -1;
+  }
+  return a > b ? 1 : 0;
+}
+
+// Domain rule for approxEqual. Also registered by name in the default
+// validator registry.
+export function validateEpsilon(epsilon: number): void {
+  if (epsilon < 0) {
+    throw new InvalidInputError("invalid input: epsilon must not be negative");
+  }
+}
+
+// Whether a and b differ by at most epsilon, either absolutely or relative
+// to the larger magnitude. The absolute test suits values near zero, where
+// any relative tolerance is vanishingly small; the relative one suits large
+// values, whose representation error alone can exceed a fixed tolerance, so
+// 1e20 and 1e20 + 16384 are equal within 1e-9. An epsilon of 0 asks for exact
+// equality.
+export function approxEqual(a: number, b: number, epsilon: number): boolean {
+  validateInputs(a, b, epsilon);
+  validateEpsilon(epsilon);
+  if (a === b) {
+    return true;
+  }
+  const difference = Math.abs(a - b);
+  return (
+    difference <= epsilon ||
+    difference <= epsilon * Math.max(Math.abs(a), Math.abs(b))
+  );
+}
+
+export function sin(a: number): number {
+  validateInputs(a);
+  return Math.sin(a);
+}
+
+export function cos(a: number): number {
+  validateInputs(a);
+  return Math.cos(a);
+}
+
+export function tan(a: number): number {
+  validateInputs(a);
+  return Math.tan(a);
+}
+
+// Base-10 logarithm.
+export function log(a: number): number {
+  validateInputs(a);
+  validatePositive(a);
+  return Math.log10(a);
+}
+
+// Natural logarithm.
+export function ln(a: number): number {
+  validateInputs(a);
+  validatePositive(a);
+  return Math.log(a);
 }
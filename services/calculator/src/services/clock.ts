@@ -0,0 +1,30 @@
+// Clock is the single source of time for the service so tests can substitute
+// a deterministic implementation.
+export interface Clock {
+  now(): Date;
+}
+
+export const systemClock: Clock = {
+  now: () => new Date(),
+};
+
+// FakeClock returns a fixed time that only moves when advanced explicitly.
+export class FakeClock implements Clock {
+  private current: number;
+
+  constructor(start: Date = new Date(0)) {
+    this.current = start.getTime();
+  }
+
+  now(): Date {
+    return new Date(this.current);
+  }
+
+  advance(ms: number): void {
+    this.current += ms;
+  }
+
+  set(time: Date): void {
+    this.current = time.getTime();
+  }
+}
@@ -0,0 +1,109 @@
+import type {
+  Awaitable,
+  CalculatorService,
+  OperationResult,
+} from "./operations";
+
+// Identifies a computation by operation name and operands. -0 is kept apart
+// from 0 because some operations give a different result for it.
+export function computationKey(name: string, operands: number[]): string {
+  const parts = operands.map((n) => (Object.is(n, -0) ? "-0" : String(n)));
+  return [name, ...parts].join(",");
+}
+
+// Wraps a service so that concurrent calls with the same operation and
+// operands share one execution of the inner service. Only in-flight calls are
+// shared; once a computation settles the next call runs it again. The shared
+// execution receives the signal of the call that started it.
+export function coalesce(service: CalculatorService): CalculatorService {
+  const inFlight = new Map<string, Promise<OperationResult>>();
+
+  // Keys start with the operation name, so a shared result is always of the
+  // type the caller's operation returns.
+  const share = <T extends OperationResult>(
+    name: string,
+    operands: number[],
+    run: () => Awaitable<T>
+  ): Promise<T> => {
+    const key = computationKey(name, operands);
+    let pending = inFlight.get(key) as Promise<T> | undefined;
+    if (pending === undefined) {
+      pending = Promise.resolve()
+        .then(run)
+        .finally(() => inFlight.delete(key));
+      inFlight.set(key, pending);
+    }
+    return pending;
+  };
+
+  return {
+    add: (a, b, signal) =>
+      share("add", [a, b], () => service.add(a, b, signal)),
+    subtract: (a, b, signal) =>
+      share("subtract", [a, b], () => service.subtract(a, b, signal)),
+    multiply: (a, b, signal) =>
+      share("multiply", [a, b], () => service.multiply(a, b, signal)),
+    divide: (a, b, signal) =>
+      share("divide", [a, b], () => service.divide(a, b, signal)),
+    divmod: (a, b, signal) =>
+      share("divmod", [a, b], () => service.divmod(a, b, signal)),
+    hypot: (a, b, signal) =>
+      share("hypot", [a, b], () => service.hypot(a, b, signal)),
+    diff: (a, b, signal) =>
+      share("diff", [a, b], () => service.diff(a, b, signal)),
+    gcd: (a, b, signal) =>
+      share("gcd", [a, b], () => service.gcd(a, b, signal)),
+    lcm: (a, b, signal) =>
+      share("lcm", [a, b], () => service.lcm(a, b, signal)),
+    percentChange: (a, b, signal) =>
+      share("percentChange", [a, b], () =>
+        service.percentChange(a, b, signal)
+      ),
+    addMany: (numbers, signal) =>
+      share("addMany", numbers, () => service.addMany(numbers, signal)),
+    kahanSum: (numbers, signal) =>
+      share("kahanSum", numbers, () => service.kahanSum(numbers, signal)),
+    multiplyMany: (numbers, signal) =>
+      share("multiplyMany", numbers, () =>
+        service.multiplyMany(numbers, signal)
+      ),
+    summary: (numbers, signal) =>
+      share("summary", numbers, () => service.summary(numbers, signal)),
+    weightedSum: (a, wa, b, wb, signal) =>
+      share("weightedSum", [a, wa, b, wb], () =>
+        service.weightedSum(a, wa, b, wb, signal)
+      ),
+    evalPolynomial: (coefficients, x, signal) =>
+      share("evalPolynomial", [...coefficients, x], () =>
+        service.evalPolynomial(coefficients, x, signal)
+      ),
+    convert: (value, scale, offset, signal) =>
+      share("convert", [value, scale, offset], () =>
+        service.convert(value, scale, offset, signal)
+      ),
+    fma: (a, b, c, signal) =>
+      share("fma", [a, b, c], () => service.fma(a, b, c, signal)),
+    clamp: (value, min, max, signal) =>
+      share("clamp", [value, min, max], () =>
+        service.clamp(value, min, max, signal)
+      ),
+    compare: (a, b, signal) =>
+      share("compare", [a, b], () => service.compare(a, b, signal)),
+    approxEqual: (a, b, epsilon, signal) =>
+      share("approxEqual", [a, b, epsilon], () =>
+        service.approxEqual(a, b, epsilon, signal)
+      ),
+    // The mode is part of the name, as results differ between modes.
+    round: (value, places, mode, signal) =>
+      share(`round:${mode}`, [value, places], () =>
+        service.round(value, places, mode, signal)
+      ),
+    sin: (a, signal) => share("sin", [a], () => service.sin(a, signal)),
+    cos: (a, signal) => share("cos", [a], () => service.cos(a, signal)),
+    tan: (a, signal) => share("tan", [a], () => service.tan(a, signal)),
+    log: (a, signal) => share("log", [a], () => service.log(a, signal)),
+    ln: (a, signal) => share("ln", [a], () => service.ln(a, signal)),
+    factorial: (a, signal) =>
+      share("factorial", [a], () => service.factorial(a, signal)),
+  };
+}
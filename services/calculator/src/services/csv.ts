@@ -0,0 +1,63 @@
+// Minimal RFC 4180 CSV reading and writing. Fields may be quoted, with ""
+// escaping a quote; records end in LF or CRLF. Blank lines are skipped.
+export function parseCsv(text: string): string[][] {
+  const records: string[][] = [];
+  let record: string[] = [];
+  let field = "";
+  let quoted = false;
+  let fieldStarted = false;
+
+  const endField = () => {
+    record.push(field);
+    field = "";
+    fieldStarted = false;
+  };
+  const endRecord = () => {
+    endField();
+    if (record.length > 1 || record[0] !== "") {
+      records.push(record);
+    }
+    record = [];
+  };
+
+  for (let i = 0; i < text.length; i++) {
+    const char = text[i];
+    if (quoted) {
+      if (char === '"' && text[i + 1] === '"') {
+        field += '"';
+        i++;
+      } else if (char === '"') {
+        quoted = false;
+      } else {
+        field += char;
+      }
+    } else if (char === '"' && !fieldStarted) {
+      quoted = true;
+      fieldStarted = true;
+    } else if (char === ",") {
+      endField();
+    } else if (char === "\n") {
+      endRecord();
+    } else if (char === "\r" && text[i + 1] === "\n") {
+      continue;
+    } else {
+      field += char;
+      fieldStarted = true;
+    }
+  }
+  if (fieldStarted || record.length > 0) {
+    endRecord();
+  }
+  return records;
+}
+
+function formatField(field: string): string {
+  return /[",\r\n]/.test(field) ? `"${field.replace(/"/g, '""')}"` : field;
+}
+
+// Formats records as CSV with CRLF line endings, quoting fields as needed.
+export function formatCsv(records: string[][]): string {
+  return records
+    .map((record) => record.map(formatField).join(",") + "\r\n")
+    .join("");
+}
@@ -0,0 +1,53 @@
+export const DEFAULT_REQUEST_TIMEOUT_MS = 10_000;
+
+// True for the reason an AbortSignal.timeout() signal aborts with.
+export function isTimeout(error: unknown): boolean {
+  return error instanceof DOMException && error.name === "TimeoutError";
+}
+
+// Aborts when the request's deadline passes or its client disconnects,
+// whichever comes first. isTimeout tells the deadline's reason apart.
+export function deadlineSignal(
+  timeoutMs: number,
+  client: AbortSignal
+): AbortSignal {
+  return AbortSignal.any([AbortSignal.timeout(timeoutMs), client]);
+}
+
+// Settles with work, or rejects with the signal's reason once it aborts,
+// whichever comes first. Operations should watch the signal themselves; this
+// bounds the wait for any that do not.
+export function untilAborted<T>(
+  work: Promise<T>,
+  signal: AbortSignal
+): Promise<T> {
+  if (signal.aborted) {
+    return Promise.reject(signal.reason);
+  }
+  return new Promise<T>((resolve, reject) => {
+    const abort = () => reject(signal.reason);
+    signal.addEventListener("abort", abort, { once: true });
+    work.then(resolve, reject).finally(() => {
+      signal.removeEventListener("abort", abort);
+    });
+  });
+}
+
+// Resolves after ms milliseconds, or rejects with the signal's reason as soon
+// as it aborts.
+export function sleep(ms: number, signal: AbortSignal): Promise<void> {
+  if (signal.aborted) {
+    return Promise.reject(signal.reason);
+  }
+  return new Promise<void>((resolve, reject) => {
+    const abort = () => {
+      clearTimeout(timer);
+      reject(signal.reason);
+    };
+    const timer = setTimeout(() => {
+      signal.removeEventListener("abort", abort);
+      resolve();
+    }, ms);
+    signal.addEventListener("abort", abort, { once: true });
+  });
+}
@@ -0,0 +1,227 @@
+import { encodeMessagePack } from "./msgpack";
+
+// Serializes response bodies in one media type. Encoders are chosen per
+// request from the Accept header; see selectEncoder.
+export interface ResponseEncoder {
+  // Sent as the response Content-Type, e.g. "application/json".
+  contentType: string;
+  encode(data: unknown): string | ArrayBuffer;
+}
+
+export const jsonEncoder: ResponseEncoder = {
+  contentType: "application/json",
+  encode: (data) => JSON.stringify(data),
+};
+
+// JSON with object keys sorted by UTF-16 code unit and no whitespace, so
+// equal data always encodes to the same bytes whatever order its keys were
+// set in. Numbers and strings are written as JSON.stringify writes them,
+// which together with the key order matches RFC 8785.
+export function canonicalJson(data: unknown): string | undefined {
+  const value =
+    typeof data === "object" &&
+    data !== null &&
+    typeof (data as { toJSON?: unknown }).toJSON === "function"
+      ? (data as { toJSON(): unknown }).toJSON()
+      : data;
+  if (Array.isArray(value)) {
+    const items = value.map((item) => canonicalJson(item) ?? "null");
+    return `[${items.join(",")}]`;
+  }
+  if (typeof value === "object" && value !== null) {
+    const object = value as Record<string, unknown>;
+    const members = Object.keys(object)
+      .sort()
+      .flatMap((key) => {
+        const member = canonicalJson(object[key]);
+        return member === undefined ? [] : [`${JSON.stringify(key)}:${member}`];
+      });
+    return `{${members.join(",")}}`;
+  }
+  // Like JSON.stringify, undefined for values JSON cannot hold, which are
+  // left out of objects and written as null in arrays.
+  return JSON.stringify(value) as string | undefined;
+}
+
+// For clients that hash or sign response bodies; see canonicalJson. Use it
+// in place of jsonEncoder with createApp({ canonicalJson: true }).
+export const canonicalJsonEncoder: ResponseEncoder = {
+  contentType: "application/json",
+  encode: (data) => canonicalJson(data) ?? "null",
+};
+
+// Wraps encoder so that the top-level keys named in keys are renamed before
+// encoding, e.g. { result: "value" } writes {"value":5} for clients that
+// expect that key. Other keys and nested objects are left alone.
+export function renameKeys(
+  encoder: ResponseEncoder,
+  keys: Readonly<Record<string, string>>
+): ResponseEncoder {
+  const rename = (key: string) => (Object.hasOwn(keys, key) ? keys[key] : key);
+  return {
+    contentType: encoder.contentType,
+    encode: (data) =>
+      encoder.encode(
+        typeof data === "object" && data !== null && !Array.isArray(data)
+          ? Object.fromEntries(
+              Object.entries(data).map(([key, value]) => [rename(key), value])
+            )
+          : data
+      ),
+  };
+}
+
+// Undoes renameKeys(encoder, keys) on a decoded body, for code that reads
+// the fields as the handler wrote them, such as the protobuf middleware.
+export function restoreKeys(
+  data: unknown,
+  keys: Readonly<Record<string, string>>
+): unknown {
+  if (typeof data !== "object" || data === null || Array.isArray(data)) {
+    return data;
+  }
+  const original = new Map(
+    Object.entries(keys).map(([key, renamed]) => [renamed, key])
+  );
+  return Object.fromEntries(
+    Object.entries(data).map(([key, value]) => [
+      original.get(key) ?? key,
+      value,
+    ])
+  );
+}
+
+// Most significant digits a double needs to round-trip.
+export const MAX_SIGNIFICANT_DIGITS = 17;
+
+// Wraps encoder so that a numeric top-level result, as in OperationResponse,
+// is written rounded to digits significant digits, so with 6 the sum of 0.1
+// and 0.2 is 0.3 rather than 0.30000000000000004. Other fields, such as
+// exact, are left alone.
+export function withSignificantDigits(
+  encoder: ResponseEncoder,
+  digits: number
+): ResponseEncoder {
+  if (
+    !Number.isInteger(digits) ||
+    digits < 1 ||
+    digits > MAX_SIGNIFICANT_DIGITS
+  ) {
+    throw new RangeError(
+      "significant digits must be an integer from 1 to " +
+        MAX_SIGNIFICANT_DIGITS
+    );
+  }
+  return {
+    contentType: encoder.contentType,
+    encode: (data) => {
+      const result = (data as { result?: unknown } | null)?.result;
+      if (typeof result !== "number" || !Number.isFinite(result)) {
+        return encoder.encode(data);
+      }
+      const rounded = Number(result.toPrecision(digits));
+      return encoder.encode({ ...(data as object), result: rounded });
+    },
+  };
+}
+
+export const messagePackEncoder: ResponseEncoder = {
+  contentType: "application/msgpack",
+  encode: encodeMessagePack,
+};
+
+function plainText(data: unknown): string {
+  if (typeof data === "object" && data !== null) {
+    const body = data as Record<string, unknown>;
+    if (typeof body.exact === "string") {
+      return body.exact;
+    }
+    if ("result" in body) {
+      return String(body.result);
+    }
+    if (typeof body.error === "string") {
+      return body.error;
+    }
+  }
+  return JSON.stringify(data);
+}
+
+// For shell scripts: just the result, e.g. "5", or the error message, so
+// curl output needs no JSON parsing. The exact result is preferred when
+// present. Bodies with neither, such as /divmod's, are written as JSON text.
+export const plainTextEncoder: ResponseEncoder = {
+  contentType: "text/plain",
+  encode: (data) => `${plainText(data)}\n`,
+};
+
+// JSON first, so it is used whenever the client has no preference.
+export const DEFAULT_ENCODERS: readonly ResponseEncoder[] = [
+  jsonEncoder,
+  messagePackEncoder,
+  plainTextEncoder,
+];
+
+interface AcceptedRange {
+  range: string;
+  q: number;
+}
+
+function parseAccept(header: string): AcceptedRange[] {
+  return header.split(",").map((part) => {
+    const [range, ...parameters] = part.split(";");
+    let q = 1;
+    for (const parameter of parameters) {
+      const [name, value] = parameter.split("=").map((s) => s.trim());
+      if (name.toLowerCase() === "q") {
+        const parsed = Number(value);
+        q = Number.isNaN(parsed) ? 0 : parsed;
+      }
+    }
+    return { range: range.trim().toLowerCase(), q };
+  });
+}
+
+// How well a media range accepts a content type: 3 for an exact match, 2 for
+// type/*, 1 for */*, 0 for none. Only the best match's q-value counts.
+function specificity(range: string, contentType: string): number {
+  if (range === contentType) {
+    return 3;
+  }
+  if (range === `${contentType.split("/")[0]}/*`) {
+    return 2;
+  }
+  return range === "*/*" ? 1 : 0;
+}
+
+// Picks the encoder the Accept header rates highest, preferring earlier
+// encoders on ties. Without a header, or when nothing acceptable is
+// registered, the first encoder is used rather than failing with 406.
+export function selectEncoder(
+  accept: string | undefined,
+  encoders: readonly ResponseEncoder[]
+): ResponseEncoder {
+  const [fallback] = encoders;
+  if (accept === undefined || accept.trim() === "") {
+    return fallback;
+  }
+
+  const ranges = parseAccept(accept);
+  let best = fallback;
+  let bestQ = 0;
+  for (const encoder of encoders) {
+    let matched = 0;
+    let q = 0;
+    for (const { range, q: rangeQ } of ranges) {
+      const match = specificity(range, encoder.contentType);
+      if (match > matched) {
+        matched = match;
+        q = rangeQ;
+      }
+    }
+    if (q > bestQ) {
+      best = encoder;
+      bestQ = q;
+    }
+  }
+  return best;
+}
@@ -0,0 +1,152 @@
+import {
+  DivisionByZeroError,
+  InvalidInputError,
+  validateFactorialOperand,
+  validateInputs,
+} from "./calculator";
+
+// Operands longer than this are rejected so that exact results stay within
+// float64 range for the approximate result and requests stay cheap.
+export const MAX_EXACT_DIGITS = 150;
+
+const INTEGER_LITERAL = /^-?(0|[1-9]\d*)$/;
+
+// Parses a JSON number literal as an exact integer. Fractions and exponents
+// are rejected rather than rounded.
+export function parseExactInteger(literal: string): bigint {
+  if (!INTEGER_LITERAL.test(literal)) {
+    throw new InvalidInputError(
+      "invalid input: exact mode requires integer operands"
+    );
+  }
+  if (literal.replace("-", "").length > MAX_EXACT_DIGITS) {
+    throw new InvalidInputError(
+      `invalid input: exact operands are limited to ${MAX_EXACT_DIGITS} digits`
+    );
+  }
+  return BigInt(literal);
+}
+
+// A fraction in lowest terms with a positive denominator.
+export class Rational {
+  readonly numerator: bigint;
+  readonly denominator: bigint;
+
+  constructor(numerator: bigint, denominator: bigint) {
+    if (denominator === 0n) {
+      throw new DivisionByZeroError();
+    }
+    const sign = denominator < 0n ? -1n : 1n;
+    const divisor = gcd(numerator, denominator);
+    this.numerator = (sign * numerator) / divisor;
+    this.denominator = (sign * denominator) / divisor;
+  }
+
+  // Nearest float64. Operands are bounded by MAX_EXACT_DIGITS, so both parts
+  // convert without overflowing.
+  toNumber(): number {
+    return Number(this.numerator) / Number(this.denominator);
+  }
+
+  equals(other: Rational): boolean {
+    return (
+      this.numerator === other.numerator &&
+      this.denominator === other.denominator
+    );
+  }
+
+  // "n/d", or just "n" when the value is an integer.
+  toString(): string {
+    return this.denominator === 1n
+      ? this.numerator.toString()
+      : `${this.numerator}/${this.denominator}`;
+  }
+}
+
+function gcd(a: bigint, b: bigint): bigint {
+  let x = a < 0n ? -a : a;
+  let y = b < 0n ? -b : b;
+  while (y !== 0n) {
+    [x, y] = [y, x % y];
+  }
+  return x;
+}
+
+// The exact value of a finite float64. Every one is an integer times a power
+// of two, so the denominator is a power of two.
+export function toRational(x: number): Rational {
+  if (!Number.isFinite(x)) {
+    throw new RangeError("only finite numbers have an exact value");
+  }
+  const view = new DataView(new ArrayBuffer(8));
+  view.setFloat64(0, x);
+  const bits = view.getBigUint64(0);
+  const sign = bits >> 63n === 1n ? -1n : 1n;
+  const exponent = Number((bits >> 52n) & 0x7ffn);
+  const fraction = bits & ((1n << 52n) - 1n);
+  // Subnormals lack the implicit leading bit and share the lowest exponent.
+  const significand = exponent === 0 ? fraction : fraction | (1n << 52n);
+  const power = Math.max(exponent, 1) - 1075;
+  return power >= 0
+    ? new Rational((sign * significand) << BigInt(power), 1n)
+    : new Rational(sign * significand, 1n << BigInt(-power));
+}
+
+// Whether quotient is exactly a / b, rather than a rounding of it. b must not
+// be zero.
+export function isExactQuotient(
+  a: number,
+  b: number,
+  quotient: number
+): boolean {
+  const x = toRational(a);
+  const y = toRational(b);
+  return new Rational(
+    x.numerator * y.denominator,
+    x.denominator * y.numerator
+  ).equals(toRational(quotient));
+}
+
+export function exactAdd(a: bigint, b: bigint): bigint {
+  return a + b;
+}
+
+export function exactSubtract(a: bigint, b: bigint): bigint {
+  return a - b;
+}
+
+export function exactMultiply(a: bigint, b: bigint): bigint {
+  return a * b;
+}
+
+export function exactDivide(a: bigint, b: bigint): Rational {
+  return new Rational(a, b);
+}
+
+// n! computed exactly; 0! is 1. n must be a non-negative integer no larger
+// than MAX_FACTORIAL_OPERAND.
+export function exactFactorial(n: number): bigint {
+  validateInputs(n);
+  validateFactorialOperand(n);
+  let product = 1n;
+  for (let i = 2n; i <= BigInt(n); i++) {
+    product *= i;
+  }
+  return product;
+}
+
+// n! both exactly, in decimal, and as the nearest float64, which is null
+// beyond 170! where float64 overflows.
+export interface Factorial {
+  exact: string;
+  result: number | null;
+}
+
+export function factorial(n: number): Factorial {
+  const exact = exactFactorial(n);
+  const result = Number(exact);
+  return {
+    exact: exact.toString(),
+    result: Number.isFinite(result) ? result : null,
+  };
+}
@@ -0,0 +1,274 @@
+import { calculatorService } from "./operations";
+import type { DivMod, RoundingMode, Summary } from "./calculator";
+import type { Factorial } from "./exact";
+import type {
+  Awaitable,
+  CalculatorService,
+  OperationResult,
+} from "./operations";
+
+export type ServiceOperation = keyof CalculatorService;
+
+export interface RecordedCall {
+  operation: ServiceOperation;
+  // Operands in parameter order; list operations record the list.
+  operands: number[];
+  signal?: AbortSignal;
+}
+
+type Behavior = (operands: number[]) => Awaitable<OperationResult>;
+
+// FakeCalculator is a CalculatorService for testing code built on the
+// service, e.g. createApp({ service: fake }). Every call is recorded in
+// calls. Operations compute the real result unless returns() or throws()
+// programs them otherwise.
+export class FakeCalculator implements CalculatorService {
+  readonly calls: RecordedCall[] = [];
+  private readonly behaviors = new Map<ServiceOperation, Behavior>();
+
+  // Makes operation return value, or the result of calling it with the
+  // operands when it is a function. value should be of the type operation
+  // returns, e.g. an object with quotient and remainder for divmod.
+  returns(
+    operation: ServiceOperation,
+    value: OperationResult | Behavior
+  ): this {
+    this.behaviors.set(
+      operation,
+      typeof value === "function" ? value : () => value
+    );
+    return this;
+  }
+
+  // Makes operation throw error, e.g. an InvalidInputError to exercise a
+  // handler's 400 path.
+  throws(operation: ServiceOperation, error: unknown): this {
+    this.behaviors.set(operation, () => {
+      throw error;
+    });
+    return this;
+  }
+
+  // Operands of each recorded call to operation, oldest first.
+  callsTo(operation: ServiceOperation): number[][] {
+    return this.calls
+      .filter((call) => call.operation === operation)
+      .map((call) => call.operands);
+  }
+
+  // Forgets recorded calls and programmed behavior.
+  reset(): void {
+    this.calls.length = 0;
+    this.behaviors.clear();
+  }
+
+  private invoke<T extends OperationResult>(
+    operation: ServiceOperation,
+    operands: number[],
+    signal: AbortSignal | undefined,
+    real: () => Awaitable<T>
+  ): Awaitable<T> {
+    this.calls.push({ operation, operands: [...operands], signal });
+    const behavior = this.behaviors.get(operation);
+    return behavior === undefined
+      ? real()
+      : (behavior(operands) as Awaitable<T>);
+  }
+
+  add(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("add", [a, b], signal, () =>
+      calculatorService.add(a, b)
+    );
+  }
+
+  subtract(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("subtract", [a, b], signal, () =>
+      calculatorService.subtract(a, b)
+    );
+  }
+
+  multiply(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("multiply", [a, b], signal, () =>
+      calculatorService.multiply(a, b)
+    );
+  }
+
+  divide(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("divide", [a, b], signal, () =>
+      calculatorService.divide(a, b)
+    );
+  }
+
+  divmod(a: number, b: number, signal?: AbortSignal): Awaitable<DivMod> {
+    return this.invoke("divmod", [a, b], signal, () =>
+      calculatorService.divmod(a, b)
+    );
+  }
+
+  hypot(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("hypot", [a, b], signal, () =>
+      calculatorService.hypot(a, b)
+    );
+  }
+
+  diff(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("diff", [a, b], signal, () =>
+      calculatorService.diff(a, b)
+    );
+  }
+
+  gcd(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("gcd", [a, b], signal, () =>
+      calculatorService.gcd(a, b)
+    );
+  }
+
+  lcm(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("lcm", [a, b], signal, () =>
+      calculatorService.lcm(a, b)
+    );
+  }
+
+  percentChange(
+    a: number,
+    b: number,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("percentChange", [a, b], signal, () =>
+      calculatorService.percentChange(a, b)
+    );
+  }
+
+  addMany(numbers: number[], signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("addMany", numbers, signal, () =>
+      calculatorService.addMany(numbers)
+    );
+  }
+
+  kahanSum(numbers: number[], signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("kahanSum", numbers, signal, () =>
+      calculatorService.kahanSum(numbers)
+    );
+  }
+
+  multiplyMany(numbers: number[], signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("multiplyMany", numbers, signal, () =>
+      calculatorService.multiplyMany(numbers)
+    );
+  }
+
+  summary(numbers: number[], signal?: AbortSignal): Awaitable<Summary> {
+    return this.invoke("summary", numbers, signal, () =>
+      calculatorService.summary(numbers)
+    );
+  }
+
+  weightedSum(
+    a: number,
+    wa: number,
+    b: number,
+    wb: number,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("weightedSum", [a, wa, b, wb], signal, () =>
+      calculatorService.weightedSum(a, wa, b, wb)
+    );
+  }
+
+  evalPolynomial(
+    coefficients: number[],
+    x: number,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("evalPolynomial", [...coefficients, x], signal, () =>
+      calculatorService.evalPolynomial(coefficients, x)
+    );
+  }
+
+  convert(
+    value: number,
+    scale: number,
+    offset: number,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("convert", [value, scale, offset], signal, () =>
+      calculatorService.convert(value, scale, offset)
+    );
+  }
+
+  fma(
+    a: number,
+    b: number,
+    c: number,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("fma", [a, b, c], signal, () =>
+      calculatorService.fma(a, b, c)
+    );
+  }
+
+  clamp(
+    value: number,
+    min: number,
+    max: number,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("clamp", [value, min, max], signal, () =>
+      calculatorService.clamp(value, min, max)
+    );
+  }
+
+  compare(a: number, b: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("compare", [a, b], signal, () =>
+      calculatorService.compare(a, b)
+    );
+  }
+
+  approxEqual(
+    a: number,
+    b: number,
+    epsilon: number,
+    signal?: AbortSignal
+  ): Awaitable<boolean> {
+    return this.invoke("approxEqual", [a, b, epsilon], signal, () =>
+      calculatorService.approxEqual(a, b, epsilon)
+    );
+  }
+
+  round(
+    value: number,
+    places: number,
+    mode: RoundingMode,
+    signal?: AbortSignal
+  ): Awaitable<number> {
+    return this.invoke("round", [value, places], signal, () =>
+      calculatorService.round(value, places, mode)
+    );
+  }
+
+  sin(a: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("sin", [a], signal, () => calculatorService.sin(a));
+  }
+
+  cos(a: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("cos", [a], signal, () => calculatorService.cos(a));
+  }
+
+  tan(a: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("tan", [a], signal, () => calculatorService.tan(a));
+  }
+
+  log(a: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("log", [a], signal, () => calculatorService.log(a));
+  }
+
+  ln(a: number, signal?: AbortSignal): Awaitable<number> {
+    return this.invoke("ln", [a], signal, () => calculatorService.ln(a));
+  }
+
+  factorial(a: number, signal?: AbortSignal): Awaitable<Factorial> {
+    return this.invoke("factorial", [a], signal, () =>
+      calculatorService.factorial(a)
+    );
+  }
+}
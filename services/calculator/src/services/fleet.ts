@@ -0,0 +1,41 @@
+import type { CalculatorClient } from "../client";
+import { runReadinessChecks } from "./readiness";
+
+export const DEFAULT_FLEET_TIMEOUT_MS = 2000;
+
+export interface FleetOptions {
+  // The calculators checked by GET /health/fleet.
+  nodes: CalculatorClient[];
+  // Milliseconds a node has to answer its /health before it counts as down.
+  timeoutMs?: number;
+}
+
+export interface FleetNodeHealth {
+  // The node's base URL.
+  url: string;
+  status: "ok" | "down";
+  latencyMs: number;
+  // Why the node counts as down; absent when it is up.
+  error?: string;
+}
+
+// Checks the /health of every node concurrently. A node is down when its
+// check fails, answers with an error status or takes longer than timeoutMs.
+export async function checkFleet(
+  nodes: readonly CalculatorClient[],
+  timeoutMs: number
+): Promise<FleetNodeHealth[]> {
+  const results = await runReadinessChecks(
+    nodes.map((node) => ({
+      name: node.baseUrl,
+      check: (signal: AbortSignal) => node.health(signal),
+    })),
+    AbortSignal.timeout(timeoutMs)
+  );
+  return results.map(({ name, status, latencyMs, error }) => ({
+    url: name,
+    status: status === "ok" ? "ok" : "down",
+    latencyMs,
+    ...(error === undefined ? {} : { error }),
+  }));
+}
@@ -0,0 +1,158 @@
+import type { Clock } from "./clock";
+import type {
+  Awaitable,
+  CalculatorService,
+  OperationResult,
+} from "./operations";
+
+export const DEFAULT_HISTORY_SIZE = 100;
+
+export interface HistoryEntry {
+  operation: string;
+  operands: number[];
+  // Exactly one of result and error is present.
+  result?: OperationResult;
+  error?: string;
+  timestamp: string;
+}
+
+// Fixed-size ring buffer of the most recent operations, for debugging. Once
+// full, each new entry overwrites the oldest. JavaScript runs one callback at
+// a time, so no locking is needed.
+export class History {
+  private readonly clock: Clock;
+  private readonly buffer: HistoryEntry[] = [];
+  private next = 0;
+  readonly size: number;
+
+  constructor(clock: Clock, size: number = DEFAULT_HISTORY_SIZE) {
+    if (!Number.isInteger(size) || size < 1) {
+      throw new RangeError("history size must be a positive integer");
+    }
+    this.clock = clock;
+    this.size = size;
+  }
+
+  record(entry: Omit<HistoryEntry, "timestamp">): void {
+    this.buffer[this.next] = {
+      ...entry,
+      // Copied so later changes to the caller's array do not rewrite history.
+      operands: [...entry.operands],
+      timestamp: this.clock.now().toISOString(),
+    };
+    this.next = (this.next + 1) % this.size;
+  }
+
+  // Recorded entries, newest first.
+  entries(): HistoryEntry[] {
+    const oldestFirst = [
+      ...this.buffer.slice(this.next),
+      ...this.buffer.slice(0, this.next),
+    ];
+    return oldestFirst.reverse();
+  }
+}
+
+export interface HistoryOptions {
+  // Entries kept. Defaults to DEFAULT_HISTORY_SIZE.
+  size?: number;
+  // Also record operations the service rejected, e.g. on overflow.
+  includeErrors?: boolean;
+}
+
+// Wraps a service so that every call is recorded in history. Requests
+// rejected before reaching the service, such as malformed bodies, are not
+// operations and are not recorded.
+export function recordHistory(
+  service: CalculatorService,
+  history: History,
+  includeErrors = false
+): CalculatorService {
+  const track = async <T extends OperationResult>(
+    operation: string,
+    operands: number[],
+    run: () => Awaitable<T>
+  ): Promise<T> => {
+    try {
+      const result = await run();
+      history.record({ operation, operands, result });
+      return result;
+    } catch (error) {
+      if (includeErrors) {
+        const message = error instanceof Error ? error.message : String(error);
+        history.record({ operation, operands, error: message });
+      }
+      throw error;
+    }
+  };
+
+  return {
+    add: (a, b, signal) =>
+      track("add", [a, b], () => service.add(a, b, signal)),
+    subtract: (a, b, signal) =>
+      track("subtract", [a, b], () => service.subtract(a, b, signal)),
+    multiply: (a, b, signal) =>
+      track("multiply", [a, b], () => service.multiply(a, b, signal)),
+    divide: (a, b, signal) =>
+      track("divide", [a, b], () => service.divide(a, b, signal)),
+    divmod: (a, b, signal) =>
+      track("divmod", [a, b], () => service.divmod(a, b, signal)),
+    hypot: (a, b, signal) =>
+      track("hypot", [a, b], () => service.hypot(a, b, signal)),
+    diff: (a, b, signal) =>
+      track("diff", [a, b], () => service.diff(a, b, signal)),
+    gcd: (a, b, signal) =>
+      track("gcd", [a, b], () => service.gcd(a, b, signal)),
+    lcm: (a, b, signal) =>
+      track("lcm", [a, b], () => service.lcm(a, b, signal)),
+    percentChange: (a, b, signal) =>
+      track("percentChange", [a, b], () =>
+        service.percentChange(a, b, signal)
+      ),
+    addMany: (numbers, signal) =>
+      track("addMany", numbers, () => service.addMany(numbers, signal)),
+    kahanSum: (numbers, signal) =>
+      track("kahanSum", numbers, () => service.kahanSum(numbers, signal)),
+    multiplyMany: (numbers, signal) =>
+      track("multiplyMany", numbers, () =>
+        service.multiplyMany(numbers, signal)
+      ),
+    summary: (numbers, signal) =>
+      track("summary", numbers, () => service.summary(numbers, signal)),
+    weightedSum: (a, wa, b, wb, signal) =>
+      track("weightedSum", [a, wa, b, wb], () =>
+        service.weightedSum(a, wa, b, wb, signal)
+      ),
+    evalPolynomial: (coefficients, x, signal) =>
+      track("evalPolynomial", [...coefficients, x], () =>
+        service.evalPolynomial(coefficients, x, signal)
+      ),
+    convert: (value, scale, offset, signal) =>
+      track("convert", [value, scale, offset], () =>
+        service.convert(value, scale, offset, signal)
+      ),
+    fma: (a, b, c, signal) =>
+      track("fma", [a, b, c], () => service.fma(a, b, c, signal)),
+    clamp: (value, min, max, signal) =>
+      track("clamp", [value, min, max], () =>
+        service.clamp(value, min, max, signal)
+      ),
+    compare: (a, b, signal) =>
+      track("compare", [a, b], () => service.compare(a, b, signal)),
+    approxEqual: (a, b, epsilon, signal) =>
+      track("approxEqual", [a, b, epsilon], () =>
+        service.approxEqual(a, b, epsilon, signal)
+      ),
+    round: (value, places, mode, signal) =>
+      track("round", [value, places], () =>
+        service.round(value, places, mode, signal)
+      ),
+    sin: (a, signal) => track("sin", [a], () => service.sin(a, signal)),
+    cos: (a, signal) => track("cos", [a], () => service.cos(a, signal)),
+    tan: (a, signal) => track("tan", [a], () => service.tan(a, signal)),
+    log: (a, signal) => track("log", [a], () => service.log(a, signal)),
+    ln: (a, signal) => track("ln", [a], () => service.ln(a, signal)),
+    factorial: (a, signal) =>
+      track("factorial", [a], () => service.factorial(a, signal)),
+  };
+}
@@ -0,0 +1,55 @@
+// IEEE 754 semantics for clients that want NaN and Infinity as results
+// rather than errors. JSON has no literals for them, so they travel as the
+// strings "NaN", "Infinity" and "-Infinity", in operands and results alike.
+
+export type IeeeNumber = number | "NaN" | "Infinity" | "-Infinity";
+
+// The operations with plain floating-point definitions, computed without the
+// finiteness, overflow and domain checks of the strict versions, so 1/0 is
+// Infinity and ln(-1) is NaN.
+export const IEEE_OPERATIONS = {
+  add: (a: number, b: number) => a + b,
+  subtract: (a: number, b: number) => a - b,
+  multiply: (a: number, b: number) => a * b,
+  divide: (a: number, b: number) => a / b,
+  hypot: (a: number, b: number) => Math.hypot(a, b),
+  diff: (a: number, b: number) => Math.abs(a - b),
+  sin: (a: number) => Math.sin(a),
+  cos: (a: number) => Math.cos(a),
+  tan: (a: number) => Math.tan(a),
+  log: (a: number) => Math.log10(a),
+  ln: (a: number) => Math.log(a),
+} as const;
+
+export type IeeeOperationName = keyof typeof IEEE_OPERATIONS;
+
+export function isIeeeOperationName(name: string): name is IeeeOperationName {
+  return Object.hasOwn(IEEE_OPERATIONS, name);
+}
+
+// Writes a number as it is sent in JSON: finite values as themselves and the
+// rest as strings. Negative zero becomes 0, as in strict responses.
+export function toIeeeJson(value: number): IeeeNumber {
+  if (Number.isNaN(value)) {
+    return "NaN";
+  }
+  if (value === Infinity) {
+    return "Infinity";
+  }
+  if (value === -Infinity) {
+    return "-Infinity";
+  }
+  return value === 0 ? 0 : value;
+}
+
+// Reads an operand sent as a JSON number or one of the three strings, or
+// returns undefined for anything else.
+export function fromIeeeJson(value: unknown): number | undefined {
+  if (typeof value === "number") {
+    return value;
+  }
+  if (value === "NaN" || value === "Infinity" || value === "-Infinity") {
+    return Number(value);
+  }
+  return undefined;
+}
@@ -0,0 +1,67 @@
+import { untilAborted } from "./deadline";
+
+// Milliseconds a shutdown hook may run before it is abandoned.
+export const DEFAULT_SHUTDOWN_TIMEOUT_MS = 5000;
+
+// A named step of shutting down, such as flushing a buffer or closing a
+// connection. run should stop waiting when signal aborts at its timeout.
+export interface ShutdownHook {
+  name: string;
+  run: (signal: AbortSignal) => unknown;
+  // Defaults to DEFAULT_SHUTDOWN_TIMEOUT_MS.
+  timeoutMs?: number;
+}
+
+export interface ShutdownHookResult {
+  name: string;
+  status: "ok" | "failed";
+  // Why the hook failed, e.g. that it timed out; absent when it succeeded.
+  error?: string;
+}
+
+// Resources to release when the host stops serving. A Worker isolate is
+// evicted without notice, so the Worker entry point never drains this; it is
+// for hosts that do get a shutdown signal, such as a Node server embedding
+// createApp, which should call shutdown() on SIGTERM before exiting.
+export class Lifecycle {
+  private readonly hooks: ShutdownHook[] = [];
+  private drained: Promise<ShutdownHookResult[]> | undefined;
+
+  onShutdown(hook: ShutdownHook): this {
+    if (this.drained !== undefined) {
+      throw new Error("cannot register a shutdown hook after shutdown");
+    }
+    this.hooks.push(hook);
+    return this;
+  }
+
+  // Runs the hooks one at a time in reverse registration order, so a
+  // resource is released before those it was built on, as with nested
+  // cleanups. Each runs under its own timeout, and one that fails or times
+  // out does not stop the rest. Later calls return the first call's results
+  // without running the hooks again.
+  shutdown(): Promise<ShutdownHookResult[]> {
+    this.drained ??= this.drain();
+    return this.drained;
+  }
+
+  private async drain(): Promise<ShutdownHookResult[]> {
+    const results: ShutdownHookResult[] = [];
+    for (const { name, run, timeoutMs } of [...this.hooks].reverse()) {
+      const signal = AbortSignal.timeout(
+        timeoutMs ?? DEFAULT_SHUTDOWN_TIMEOUT_MS
+      );
+      try {
+        await untilAborted(Promise.resolve().then(() => run(signal)), signal);
+        results.push({ name, status: "ok" });
+      } catch (error) {
+        results.push({
+          name,
+          status: "failed",
+          error: error instanceof Error ? error.message : String(error),
+        });
+      }
+    }
+    return results;
+  }
+}
@@ -0,0 +1,372 @@
+import {
+  MAX_FACTORIAL_OPERAND,
+  MAX_PLACES,
+  ROUNDING_MODES,
+} from "./calculator";
+
+// What a client needs to call an operation without reading the docs. Every
+// operation also requires finite operands; constraints lists only the rules
+// beyond that.
+export interface OperationMetadata {
+  name: string;
+  // Endpoint that computes it, e.g. "/divide".
+  path: string;
+  description: string;
+  // Number of operands, or "variadic" for those taking a list.
+  arity: number | "variadic";
+  // Request body fields holding the operands, in order.
+  operands: string[];
+  constraints: string[];
+  example: {
+    request: Record<string, unknown>;
+    response: Record<string, unknown>;
+  };
+}
+
+// Maps operation names to their metadata, served at
+// GET /operations/{name}/describe. Registering a name again replaces it.
+export class OperationRegistry {
+  private operations = new Map<string, OperationMetadata>();
+
+  register(metadata: OperationMetadata): this {
+    this.operations.set(metadata.name, metadata);
+    return this;
+  }
+
+  describe(name: string): OperationMetadata | undefined {
+    return this.operations.get(name);
+  }
+
+  names(): string[] {
+    return [...this.operations.keys()];
+  }
+}
+
+const binary = (
+  name: string,
+  path: string,
+  description: string,
+  constraints: string[],
+  example: OperationMetadata["example"]
+): OperationMetadata => ({
+  name,
+  path,
+  description,
+  arity: 2,
+  operands: ["a", "b"],
+  constraints,
+  example,
+});
+
+const unary = (
+  name: string,
+  description: string,
+  constraints: string[],
+  example: OperationMetadata["example"]
+): OperationMetadata => ({
+  name,
+  path: `/${name}`,
+  description,
+  arity: 1,
+  operands: ["a"],
+  constraints,
+  example,
+});
+
+const list = (
+  name: string,
+  path: string,
+  description: string,
+  constraints: string[],
+  example: OperationMetadata["example"]
+): OperationMetadata => ({
+  name,
+  path,
+  description,
+  arity: "variadic",
+  operands: ["numbers"],
+  constraints,
+  example,
+});
+
+// Returns a registry describing the built-in operations, ready to be
+// extended. The examples are real requests and responses; the tests replay
+// them to keep this in step with the routes.
+export function createDefaultOperationRegistry(): OperationRegistry {
+  const overflow = "the result must not overflow";
+  return new OperationRegistry()
+    .register(
+      binary("add", "/add", "Sum, a + b", [overflow], {
+        request: { a: 2, b: 3 },
+        response: { result: 5 },
+      })
+    )
+    .register(
+      binary("subtract", "/subtract", "Difference, a - b", [overflow], {
+        request: { a: 5, b: 3 },
+        response: { result: 2 },
+      })
+    )
+    .register(
+      binary("multiply", "/multiply", "Product, a * b", [overflow], {
+        request: { a: 4, b: 5 },
+        response: { result: 20 },
+      })
+    )
+    .register(
+      binary(
+        "divide",
+        "/divide",
+        "Quotient, a / b",
+        ["b must not be zero (division by zero)"],
+        { request: { a: 10, b: 4 }, response: { result: 2.5 } }
+      )
+    )
+    .register(
+      binary(
+        "divmod",
+        "/divmod",
+        "Truncating quotient and remainder of a / b",
+        ["b must not be zero (division by zero)"],
+        {
+          request: { a: -7, b: 2 },
+          response: { quotient: -3, remainder: -1 },
+        }
+      )
+    )
+    .register(
+      binary("hypot", "/hypot", "Hypotenuse, sqrt(a² + b²)", [overflow], {
+        request: { a: 3, b: 4 },
+        response: { result: 5 },
+      })
+    )
+    .register(
+      binary("diff", "/diff", "Absolute difference, |a - b|", [overflow], {
+        request: { a: 3, b: 7 },
+        response: { result: 4 },
+      })
+    )
+    .register(
+      binary(
+        "gcd",
+        "/gcd",
+        "Greatest common divisor",
+        ["a and b must be integers"],
+        { request: { a: 12, b: 18 }, response: { result: 6 } }
+      )
+    )
+    .register(
+      binary(
+        "lcm",
+        "/lcm",
+        "Least common multiple",
+        ["a and b must be integers", "the result must not exceed 2^53 - 1"],
+        { request: { a: 4, b: 6 }, response: { result: 12 } }
+      )
+    )
+    .register(
+      binary(
+        "percentChange",
+        "/percent-change",
+        "Percentage change from a to b, ((b - a) / a) * 100",
+        ["a must not be zero (division by zero)", overflow],
+        { request: { a: 50, b: 75 }, response: { result: 50 } }
+      )
+    )
+    .register(
+      binary(
+        "compare",
+        "/compare",
+        "-1, 0 or 1 as a is less than, equal to or greater than b",
+        [],
+        { request: { a: 1, b: 2 }, response: { comparison: -1 } }
+      )
+    )
+    .register(
+      list("addMany", "/add/many", "Sum of a list", [overflow], {
+        request: { numbers: [1, 2, 3] },
+        response: { result: 6 },
+      })
+    )
+    .register(
+      list(
+        "kahanSum",
+        "/sum/kahan",
+        "Sum of a list with compensated summation",
+        [overflow],
+        { request: { numbers: [1, 2, 3] }, response: { result: 6 } }
+      )
+    )
+    .register(
+      list("multiplyMany", "/multiply/many", "Product of a list", [overflow], {
+        request: { numbers: [2, 3, 4] },
+        response: { result: 24 },
+      })
+    )
+    .register(
+      list(
+        "summary",
+        "/stats/summary",
+        "Count, mean, population variance, standard deviation and range",
+        ["numbers must not be empty"],
+        {
+          request: { numbers: [2, 4, 4, 4, 5, 5, 7, 9] },
+          response: {
+            count: 8,
+            mean: 5,
+            variance: 4,
+            stddev: 2,
+            min: 2,
+            max: 9,
+          },
+        }
+      )
+    )
+    .register({
+      name: "reversePolish",
+      path: "/calc/reverse-polish",
+      description: "Value of a Reverse Polish Notation expression",
+      arity: "variadic",
+      operands: ["tokens"],
+      constraints: [
+        "tokens are numbers or one of + - * /",
+        "every operator needs two operands",
+        "exactly one operand must remain",
+      ],
+      example: {
+        request: { tokens: ["3", "4", "+", "2", "*"] },
+        response: { result: 14 },
+      },
+    })
+    .register({
+      name: "weightedSum",
+      path: "/weighted-sum",
+      description: "Weighted sum, a*wa + b*wb",
+      arity: 4,
+      operands: ["a", "wa", "b", "wb"],
+      constraints: [overflow],
+      example: {
+        request: { a: 1, wa: 0.25, b: 3, wb: 0.75 },
+        response: { result: 2.5 },
+      },
+    })
+    .register({
+      name: "polynomial",
+      path: "/polynomial",
+      description:
+        "Polynomial with coefficients highest degree first, evaluated at x",
+      // Two fields, a list and a number.
+      arity: 2,
+      operands: ["coefficients", "x"],
+      constraints: ["coefficients must not be empty", overflow],
+      example: {
+        request: { coefficients: [2, 3, 1], x: 2 },
+        response: { result: 15 },
+      },
+    })
+    .register({
+      name: "convert",
+      path: "/convert",
+      description: "Linear unit conversion, value*scale + offset",
+      arity: 3,
+      operands: ["value", "scale", "offset"],
+      constraints: [overflow],
+      example: {
+        request: { value: 100, scale: 1.8, offset: 32 },
+        response: { result: 212 },
+      },
+    })
+    .register({
+      name: "fma",
+      path: "/fma",
+      description: "a*b + c with a single rounding",
+      arity: 3,
+      operands: ["a", "b", "c"],
+      constraints: [overflow],
+      example: { request: { a: 2, b: 3, c: 1 }, response: { result: 7 } },
+    })
+    .register({
+      name: "clamp",
+      path: "/clamp",
+      description: "value bounded to [min, max]",
+      arity: 3,
+      operands: ["value", "min", "max"],
+      constraints: ["min must not exceed max"],
+      example: {
+        request: { value: 15, min: 0, max: 10 },
+        response: { result: 10 },
+      },
+    })
+    .register({
+      name: "approxEqual",
+      path: "/approx-equal",
+      description:
+        "Whether a and b differ by at most epsilon, absolutely or relative " +
+        "to the larger of |a| and |b|",
+      arity: 3,
+      operands: ["a", "b", "epsilon"],
+      constraints: ["epsilon must not be negative"],
+      example: {
+        request: { a: 0.1 + 0.2, b: 0.3, epsilon: 1e-9 },
+        response: { equal: true },
+      },
+    })
+    .register({
+      name: "round",
+      path: "/round",
+      description:
+        "value rounded to places decimal places; optional mode, " +
+        "half_even by default",
+      arity: 2,
+      operands: ["value", "places"],
+      constraints: [
+        `places must be an integer from 0 to ${MAX_PLACES}`,
+        `mode must be one of ${ROUNDING_MODES.join(", ")}`,
+      ],
+      example: {
+        request: { value: 2.5, places: 0 },
+        response: { result: 2 },
+      },
+    })
+    .register(
+      unary(
+        "factorial",
+        "a!, computed exactly",
+        [
+          "a must be a non-negative integer",
+          `a must not exceed ${MAX_FACTORIAL_OPERAND}`,
+        ],
+        { request: { a: 5 }, response: { exact: "120", result: 120 } }
+      )
+    )
+    .register(
+      unary("sin", "Sine of a in radians", [], {
+        request: { a: 0 },
+        response: { result: 0 },
+      })
+    )
+    .register(
+      unary("cos", "Cosine of a in radians", [], {
+        request: { a: 0 },
+        response: { result: 1 },
+      })
+    )
+    .register(
+      unary("tan", "Tangent of a in radians", [], {
+        request: { a: 0 },
+        response: { result: 0 },
+      })
+    )
+    .register(
+      unary("log", "Base-10 logarithm", ["a must be positive"], {
+        request: { a: 100 },
+        response: { result: 2 },
+      })
+    )
+    .register(
+      unary("ln", "Natural logarithm", ["a must be positive"], {
+        request: { a: 1 },
+        response: { result: 0 },
+      })
+    );
+}
@@ -0,0 +1,162 @@
+// Upper bounds, in seconds, of the default request latency buckets.
+export const DEFAULT_LATENCY_BUCKETS = [
+  0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5,
+];
+
+// Cumulative histogram rendered in the Prometheus text exposition format.
+export class Histogram {
+  readonly name: string;
+  readonly help: string;
+  private readonly bounds: number[];
+  private readonly counts: number[];
+  private sum = 0;
+  private count = 0;
+
+  constructor(
+    name: string,
+    help: string,
+    buckets: number[] = DEFAULT_LATENCY_BUCKETS
+  ) {
+    this.name = name;
+    this.help = help;
+    this.bounds = [...buckets].sort((x, y) => x - y);
+    this.counts = this.bounds.map(() => 0);
+  }
+
+  observe(value: number): void {
+    this.sum += value;
+    this.count++;
+    for (const [index, bound] of this.bounds.entries()) {
+      if (value <= bound) {
+        this.counts[index]++;
+      }
+    }
+  }
+
+  render(): string {
+    const lines = [
+      `# HELP ${this.name} ${this.help}`,
+      `# TYPE ${this.name} histogram`,
+      ...this.bounds.map(
+        (bound, index) =>
+          `${this.name}_bucket{le="${bound}"} ${this.counts[index]}`
+      ),
+      `${this.name}_bucket{le="+Inf"} ${this.count}`,
+      `${this.name}_sum ${this.sum}`,
+      `${this.name}_count ${this.count}`,
+    ];
+    return lines.join("\n") + "\n";
+  }
+}
+
+// Monotonic counter rendered in the Prometheus text exposition format.
+export class Counter {
+  readonly name: string;
+  readonly help: string;
+  private count = 0;
+
+  constructor(name: string, help: string) {
+    this.name = name;
+    this.help = help;
+  }
+
+  get value(): number {
+    return this.count;
+  }
+
+  inc(): void {
+    this.count++;
+  }
+
+  render(): string {
+    const lines = [
+      `# HELP ${this.name} ${this.help}`,
+      `# TYPE ${this.name} counter`,
+      `${this.name} ${this.count}`,
+    ];
+    return lines.join("\n") + "\n";
+  }
+}
+
+export type OperationStatus = "success" | "error";
+
+// Counts operations by name and outcome, rendered with operation and status
+// labels. Status has only two values, and both are rendered for every
+// operation seen so an error-rate alert always has a series to divide.
+export class OperationCounter {
+  readonly name: string;
+  readonly help: string;
+  private readonly counts = new Map<string, Record<OperationStatus, number>>();
+
+  constructor(name: string, help: string) {
+    this.name = name;
+    this.help = help;
+  }
+
+  value(operation: string, status: OperationStatus): number {
+    return this.counts.get(operation)?.[status] ?? 0;
+  }
+
+  inc(operation: string, status: OperationStatus): void {
+    const counts = this.counts.get(operation) ?? { success: 0, error: 0 };
+    counts[status]++;
+    this.counts.set(operation, counts);
+  }
+
+  render(): string {
+    const lines = [
+      `# HELP ${this.name} ${this.help}`,
+      `# TYPE ${this.name} counter`,
+    ];
+    for (const [operation, counts] of this.counts) {
+      for (const status of ["success", "error"] as const) {
+        const labels = `operation="${operation}",status="${status}"`;
+        lines.push(`${this.name}{${labels}} ${counts[status]}`);
+      }
+    }
+    return lines.join("\n") + "\n";
+  }
+}
+
+// Metrics holds every collector exposed at /metrics.
+export class Metrics {
+  readonly requestDuration: Histogram;
+  readonly requestsCancelled: Counter;
+  readonly operations: OperationCounter;
+  readonly cacheHits: Counter;
+  readonly cacheMisses: Counter;
+
+  constructor(latencyBuckets: number[] = DEFAULT_LATENCY_BUCKETS) {
+    this.requestDuration = new Histogram(
+      "calculator_request_duration_seconds",
+      "Time spent handling requests, from middleware entry to response.",
+      latencyBuckets
+    );
+    this.requestsCancelled = new Counter(
+      "calculator_requests_cancelled_total",
+      "Requests whose client disconnected before a response was ready."
+    );
+    this.operations = new OperationCounter(
+      "calculator_operations_total",
+      "Operations attempted, by operation and whether they succeeded."
+    );
+    this.cacheHits = new Counter(
+      "calculator_cache_hits_total",
+      "Operations answered from the result cache."
+    );
+    this.cacheMisses = new Counter(
+      "calculator_cache_misses_total",
+      "Operations the result cache had to compute."
+    );
+  }
+
+  render(): string {
+    return (
+      this.requestDuration.render() +
+      this.requestsCancelled.render() +
+      this.operations.render() +
+      this.cacheHits.render() +
+      this.cacheMisses.render()
+    );
+  }
+}
@@ -0,0 +1,304 @@
+// Minimal MessagePack codec (https://msgpack.org) covering the JSON data
+// model: null, booleans, numbers, strings, arrays and plain objects. Integers
+// in the safe range use the smallest integer format; other numbers are
+// float64. As with JSON, object properties whose value is undefined are
+// skipped.
+
+export class MessagePackError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = "MessagePackError";
+  }
+}
+
+const textEncoder = new TextEncoder();
+const textDecoder = new TextDecoder();
+
+function pushUint(bytes: number[], value: number, size: 1 | 2 | 4 | 8): void {
+  for (let shift = (size - 1) * 8; shift >= 0; shift -= 8) {
+    // Division rather than >>> keeps values above 2^32 exact.
+    bytes.push(Math.floor(value / 2 ** shift) % 256);
+  }
+}
+
+function pushFloat64(bytes: number[], value: number): void {
+  const view = new DataView(new ArrayBuffer(8));
+  view.setFloat64(0, value);
+  for (let i = 0; i < 8; i++) {
+    bytes.push(view.getUint8(i));
+  }
+}
+
+function pushInteger(bytes: number[], value: number): void {
+  if (value >= 0) {
+    if (value < 0x80) {
+      bytes.push(value);
+    } else if (value < 0x100) {
+      bytes.push(0xcc, value);
+    } else if (value < 0x10000) {
+      bytes.push(0xcd);
+      pushUint(bytes, value, 2);
+    } else if (value < 0x100000000) {
+      bytes.push(0xce);
+      pushUint(bytes, value, 4);
+    } else {
+      bytes.push(0xcf);
+      pushUint(bytes, value, 8);
+    }
+  } else if (value >= -32) {
+    bytes.push(value + 0x100);
+  } else if (value >= -0x80) {
+    bytes.push(0xd0, value + 0x100);
+  } else if (value >= -0x8000) {
+    bytes.push(0xd1);
+    pushUint(bytes, value + 0x10000, 2);
+  } else if (value >= -0x80000000) {
+    bytes.push(0xd2);
+    pushUint(bytes, value + 0x100000000, 4);
+  } else {
+    // Two's complement of a negative safe integer in 64 bits.
+    bytes.push(0xd3);
+    pushUint(bytes, Math.floor(value / 2 ** 32) + 2 ** 32, 4);
+    pushUint(bytes, ((value % 2 ** 32) + 2 ** 32) % 2 ** 32, 4);
+  }
+}
+
+// Writes a length-prefixed header using the fix, 8-bit (if given), 16-bit or
+// 32-bit format of a family.
+function pushHeader(
+  bytes: number[],
+  length: number,
+  fix: { base: number; max: number },
+  codes: { u8?: number; u16: number; u32: number }
+): void {
+  if (length <= fix.max) {
+    bytes.push(fix.base + length);
+  } else if (codes.u8 !== undefined && length < 0x100) {
+    bytes.push(codes.u8, length);
+  } else if (length < 0x10000) {
+    bytes.push(codes.u16);
+    pushUint(bytes, length, 2);
+  } else {
+    bytes.push(codes.u32);
+    pushUint(bytes, length, 4);
+  }
+}
+
+function encodeValue(bytes: number[], value: unknown): void {
+  if (value === null || value === undefined) {
+    bytes.push(0xc0);
+  } else if (typeof value === "boolean") {
+    bytes.push(value ? 0xc3 : 0xc2);
+  } else if (typeof value === "number") {
+    if (Number.isSafeInteger(value) && !Object.is(value, -0)) {
+      pushInteger(bytes, value);
+    } else {
+      bytes.push(0xcb);
+      pushFloat64(bytes, value);
+    }
+  } else if (typeof value === "string") {
+    const utf8 = textEncoder.encode(value);
+    pushHeader(
+      bytes,
+      utf8.length,
+      { base: 0xa0, max: 31 },
+      { u8: 0xd9, u16: 0xda, u32: 0xdb }
+    );
+    for (const byte of utf8) {
+      bytes.push(byte);
+    }
+  } else if (Array.isArray(value)) {
+    pushHeader(
+      bytes,
+      value.length,
+      { base: 0x90, max: 15 },
+      { u16: 0xdc, u32: 0xdd }
+    );
+    for (const element of value) {
+      encodeValue(bytes, element);
+    }
+  } else if (typeof value === "object") {
+    const entries = Object.entries(value).filter(([, v]) => v !== undefined);
+    pushHeader(
+      bytes,
+      entries.length,
+      { base: 0x80, max: 15 },
+      { u16: 0xde, u32: 0xdf }
+    );
+    for (const [key, element] of entries) {
+      encodeValue(bytes, key);
+      encodeValue(bytes, element);
+    }
+  } else {
+    throw new MessagePackError(`cannot encode ${typeof value}`);
+  }
+}
+
+export function encodeMessagePack(value: unknown): ArrayBuffer {
+  const bytes: number[] = [];
+  encodeValue(bytes, value);
+  const buffer = new ArrayBuffer(bytes.length);
+  new Uint8Array(buffer).set(bytes);
+  return buffer;
+}
+
+class Reader {
+  private readonly view: DataView;
+  private offset = 0;
+
+  constructor(view: DataView) {
+    this.view = view;
+  }
+
+  get done(): boolean {
+    return this.offset === this.view.byteLength;
+  }
+
+  private take(size: number): number {
+    if (this.offset + size > this.view.byteLength) {
+      throw new MessagePackError("unexpected end of input");
+    }
+    const start = this.offset;
+    this.offset += size;
+    return start;
+  }
+
+  uint(size: 1 | 2 | 4 | 8): number {
+    const start = this.take(size);
+    let value = 0;
+    for (let i = 0; i < size; i++) {
+      value = value * 256 + this.view.getUint8(start + i);
+    }
+    return value;
+  }
+
+  int(size: 1 | 2 | 4 | 8): number {
+    if (size === 8) {
+      // Combined from two words so that safe integers stay exact.
+      const high = this.int(4);
+      return high * 2 ** 32 + this.uint(4);
+    }
+    const unsigned = this.uint(size);
+    const range = 2 ** (size * 8);
+    return unsigned >= range / 2 ? unsigned - range : unsigned;
+  }
+
+  float(size: 4 | 8): number {
+    const start = this.take(size);
+    return size === 4
+      ? this.view.getFloat32(start)
+      : this.view.getFloat64(start);
+  }
+
+  string(length: number): string {
+    const start = this.take(length);
+    return textDecoder.decode(
+      new Uint8Array(this.view.buffer, this.view.byteOffset + start, length)
+    );
+  }
+}
+
+function decodeValue(reader: Reader): unknown {
+  const code = reader.uint(1);
+  // Fixed formats carry their value or length in the type byte itself.
+  if (code < 0x80) {
+    return code;
+  }
+  if (code < 0x90) {
+    return decodeMap(reader, code - 0x80);
+  }
+  if (code < 0xa0) {
+    return decodeArray(reader, code - 0x90);
+  }
+  if (code < 0xc0) {
+    return reader.string(code - 0xa0);
+  }
+  if (code >= 0xe0) {
+    return code - 0x100;
+  }
+  switch (code) {
+    case 0xc0:
+      return null;
+    case 0xc2:
+      return false;
+    case 0xc3:
+      return true;
+    case 0xca:
+      return reader.float(4);
+    case 0xcb:
+      return reader.float(8);
+    case 0xcc:
+      return reader.uint(1);
+    case 0xcd:
+      return reader.uint(2);
+    case 0xce:
+      return reader.uint(4);
+    case 0xcf:
+      return reader.uint(8);
+    case 0xd0:
+      return reader.int(1);
+    case 0xd1:
+      return reader.int(2);
+    case 0xd2:
+      return reader.int(4);
+    case 0xd3:
+      return reader.int(8);
+    case 0xd9:
+      return reader.string(reader.uint(1));
+    case 0xda:
+      return reader.string(reader.uint(2));
+    case 0xdb:
+      return reader.string(reader.uint(4));
+    case 0xdc:
+      return decodeArray(reader, reader.uint(2));
+    case 0xdd:
+      return decodeArray(reader, reader.uint(4));
+    case 0xde:
+      return decodeMap(reader, reader.uint(2));
+    case 0xdf:
+      return decodeMap(reader, reader.uint(4));
+    default:
+      throw new MessagePackError(`unsupported format 0x${code.toString(16)}`);
+  }
+}
+
+function decodeArray(reader: Reader, length: number): unknown[] {
+  const array: unknown[] = [];
+  for (let i = 0; i < length; i++) {
+    array.push(decodeValue(reader));
+  }
+  return array;
+}
+
+function decodeMap(reader: Reader, length: number): Record<string, unknown> {
+  const map: Record<string, unknown> = {};
+  for (let i = 0; i < length; i++) {
+    const key = decodeValue(reader);
+    if (typeof key !== "string") {
+      throw new MessagePackError("map keys must be strings");
+    }
+    // Defined rather than assigned so a "__proto__" key stays a plain key.
+    Object.defineProperty(map, key, {
+      value: decodeValue(reader),
+      enumerable: true,
+      writable: true,
+      configurable: true,
+    });
+  }
+  return map;
+}
+
+// Decodes a single value occupying the whole input. Integers beyond the safe
+// range lose precision, as they would in JSON.
+export function decodeMessagePack(input: ArrayBuffer | Uint8Array): unknown {
+  const view =
+    input instanceof Uint8Array
+      ? new DataView(input.buffer, input.byteOffset, input.byteLength)
+      : new DataView(input);
+  const reader = new Reader(view);
+  const value = decodeValue(reader);
+  if (!reader.done) {
+    throw new MessagePackError("trailing bytes after value");
+  }
+  return value;
+}
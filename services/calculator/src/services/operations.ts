@@ -0,0 +1,205 @@
+import {
+  add,
+  subtract,
+  multiply,
+  divide,
+  divmod,
+  round,
+  summarize,
+  approxEqual,
+  hypot,
+  absDiff,
+  gcd,
+  lcm,
+  percentChange,
+  addMany,
+  kahanSum,
+  multiplyMany,
+  weightedSum,
+  evalPolynomial,
+  convert,
+  fma,
+  clamp,
+  compare,
+  sin,
+  cos,
+  tan,
+  log,
+  ln,
+} from "./calculator";
+import type { DivMod, RoundingMode, Summary } from "./calculator";
+import { factorial } from "./exact";
+import type { Factorial, Rational } from "./exact";
+
+export type Awaitable<T> = T | Promise<T>;
+
+// What a service operation returns: a number for most, an object of figures
+// for those with several, such as divmod, or a boolean for approxEqual.
+// Decorators pass results through untouched, and history and the audit log
+// write them as JSON.
+export type OperationResult = number | boolean | DivMod | Factorial | Summary;
+
+export type ExactOperation = (a: bigint, b: bigint) => bigint | Rational;
+
+// Post-processes an operation's result, e.g. to convert units, before it is
+// returned to the client.
+export type ResultTransform = (operation: string, result: number) => number;
+
+// CalculatorService is the computation boundary used by the HTTP handlers.
+// Methods may return a promise so decorators and test fakes can be async.
+// Each takes an optional signal that aborts when the request's deadline
+// passes; long-running implementations should stop work when it fires.
+export interface CalculatorService {
+  add(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  subtract(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  multiply(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  divide(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  // Truncating quotient and the remainder; b must not be zero.
+  divmod(a: number, b: number, signal?: AbortSignal): Awaitable<DivMod>;
+  hypot(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  diff(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  gcd(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  lcm(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  // ((b - a) / a) * 100; a must not be zero.
+  percentChange(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  addMany(numbers: number[], signal?: AbortSignal): Awaitable<number>;
+  // Compensated sum, accurate where addMany loses small operands.
+  kahanSum(numbers: number[], signal?: AbortSignal): Awaitable<number>;
+  multiplyMany(numbers: number[], signal?: AbortSignal): Awaitable<number>;
+  // Count, mean, population variance, stddev and range of a non-empty list.
+  summary(numbers: number[], signal?: AbortSignal): Awaitable<Summary>;
+  weightedSum(
+    a: number,
+    wa: number,
+    b: number,
+    wb: number,
+    signal?: AbortSignal
+  ): Awaitable<number>;
+  // The polynomial with coefficients highest degree first, evaluated at x.
+  evalPolynomial(
+    coefficients: number[],
+    x: number,
+    signal?: AbortSignal
+  ): Awaitable<number>;
+  convert(
+    value: number,
+    scale: number,
+    offset: number,
+    signal?: AbortSignal
+  ): Awaitable<number>;
+  // a*b + c with a single rounding.
+  fma(a: number, b: number, c: number, signal?: AbortSignal): Awaitable<number>;
+  // value bounded to [min, max]; min must not exceed max.
+  clamp(
+    value: number,
+    min: number,
+    max: number,
+    signal?: AbortSignal
+  ): Awaitable<number>;
+  // -1, 0 or 1 as a is less than, equal to or greater than b.
+  compare(a: number, b: number, signal?: AbortSignal): Awaitable<number>;
+  // Whether a and b are equal within epsilon, absolutely or relatively.
+  approxEqual(
+    a: number,
+    b: number,
+    epsilon: number,
+    signal?: AbortSignal
+  ): Awaitable<boolean>;
+  // value rounded to places decimal places, ties settled by mode.
+  round(
+    value: number,
+    places: number,
+    mode: RoundingMode,
+    signal?: AbortSignal
+  ): Awaitable<number>;
+  sin(a: number, signal?: AbortSignal): Awaitable<number>;
+  cos(a: number, signal?: AbortSignal): Awaitable<number>;
+  tan(a: number, signal?: AbortSignal): Awaitable<number>;
+  log(a: number, signal?: AbortSignal): Awaitable<number>;
+  ln(a: number, signal?: AbortSignal): Awaitable<number>;
+  // a! exactly and as a float; a must be a non-negative integer no larger
+  // than MAX_FACTORIAL_OPERAND.
+  factorial(a: number, signal?: AbortSignal): Awaitable<Factorial>;
+}
+
+// The built-in operations finish instantly, so they ignore the signal.
+export const calculatorService: CalculatorService = {
+  add,
+  subtract,
+  multiply,
+  divide,
+  divmod,
+  hypot,
+  diff: absDiff,
+  gcd,
+  lcm,
+  percentChange,
+  addMany,
+  kahanSum,
+  multiplyMany,
+  summary: summarize,
+  weightedSum,
+  evalPolynomial,
+  convert,
+  fma,
+  clamp,
+  compare,
+  approxEqual,
+  round,
+  sin,
+  cos,
+  tan,
+  log,
+  ln,
+  factorial,
+};
+
+export type BinaryOperationName =
+  | "add"
+  | "subtract"
+  | "multiply"
+  | "divide"
+  | "hypot"
+  | "diff"
+  | "gcd"
+  | "lcm"
+  | "percentChange";
+export type UnaryOperationName = "sin" | "cos" | "tan" | "log" | "ln";
+
+const binaryOperationNames: ReadonlySet<string> = new Set<BinaryOperationName>(
+  [
+    "add",
+    "subtract",
+    "multiply",
+    "divide",
+    "hypot",
+    "diff",
+    "gcd",
+    "lcm",
+    "percentChange",
+  ]
+);
+const unaryOperationNames: ReadonlySet<string> = new Set<UnaryOperationName>([
+  "sin",
+  "cos",
+  "tan",
+  "log",
+  "ln",
+]);
+
+// Every operation addressable by name, binary operations first.
+export const OPERATION_NAMES: readonly string[] = [
+  ...binaryOperationNames,
+  ...unaryOperationNames,
+];
+
+// Operations addressable by name, e.g. from WebSocket messages.
+export function isBinaryOperationName(
+  name: string
+): name is BinaryOperationName {
+  return binaryOperationNames.has(name);
+}
+
+export function isUnaryOperationName(name: string): name is UnaryOperationName {
+  return unaryOperationNames.has(name);
+}
@@ -0,0 +1,55 @@
+export const DEFAULT_BATCH_CONCURRENCY = 8;
+export const DEFAULT_MAX_BATCH_WORKERS = 64;
+
+// Counts permits shared by every request of an app, such as batch workers,
+// so that overlapping requests together stay under one limit. It never
+// waits: callers take what is free and shed load when nothing is.
+export class Semaphore {
+  private inUse = 0;
+  readonly permits: number;
+
+  constructor(permits: number) {
+    if (!Number.isInteger(permits) || permits < 1) {
+      throw new RangeError("permits must be a positive integer");
+    }
+    this.permits = permits;
+  }
+
+  get available(): number {
+    return this.permits - this.inUse;
+  }
+
+  // Takes up to wanted permits and returns how many were taken, 0 when all
+  // are in use. Each taken permit must be released.
+  tryAcquire(wanted: number): number {
+    const taken = Math.max(0, Math.min(wanted, this.available));
+    this.inUse += taken;
+    return taken;
+  }
+
+  release(count: number): void {
+    this.inUse = Math.max(0, this.inUse - count);
+  }
+}
+
+// Calls fn on every item with at most limit calls in flight, and resolves
+// with the results in item order however the calls complete. Once signal
+// aborts no further items are started.
+export async function mapConcurrent<T, R>(
+  items: readonly T[],
+  limit: number,
+  fn: (item: T, index: number) => Promise<R>,
+  signal?: AbortSignal
+): Promise<R[]> {
+  const results = new Array<R>(items.length);
+  let next = 0;
+  const worker = async () => {
+    while (next < items.length && !signal?.aborted) {
+      const index = next++;
+      results[index] = await fn(items[index], index);
+    }
+  };
+  const workers = Math.max(1, Math.min(limit, items.length));
+  await Promise.all(Array.from({ length: workers }, worker));
+  return results;
+}
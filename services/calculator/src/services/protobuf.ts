@@ -0,0 +1,239 @@
+// Minimal Protocol Buffers (https://protobuf.dev) codec for the messages in
+// calculator.proto. Only the wire types those messages use are written;
+// fields of any wire type are skipped when unknown, so newer clients can
+// add fields.
+
+import type {
+  ErrorResponse,
+  OperationRequest,
+  OperationResponse,
+  ValidationErrorResponse,
+} from "../types";
+
+export class ProtobufError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = "ProtobufError";
+  }
+}
+
+const VARINT = 0;
+const FIXED64 = 1;
+const LENGTH_DELIMITED = 2;
+const FIXED32 = 5;
+
+const textEncoder = new TextEncoder();
+const textDecoder = new TextDecoder();
+
+class Writer {
+  private readonly bytes: number[] = [];
+
+  private varint(value: number): void {
+    let rest = value;
+    while (rest >= 0x80) {
+      this.bytes.push((rest % 0x80) | 0x80);
+      rest = Math.floor(rest / 0x80);
+    }
+    this.bytes.push(rest);
+  }
+
+  private tag(field: number, wireType: number): void {
+    this.varint(field * 8 + wireType);
+  }
+
+  double(field: number, value: number): void {
+    this.tag(field, FIXED64);
+    const view = new DataView(new ArrayBuffer(8));
+    view.setFloat64(0, value, true);
+    for (let i = 0; i < 8; i++) {
+      this.bytes.push(view.getUint8(i));
+    }
+  }
+
+  bytesField(field: number, value: Uint8Array): void {
+    this.tag(field, LENGTH_DELIMITED);
+    this.varint(value.length);
+    for (const byte of value) {
+      this.bytes.push(byte);
+    }
+  }
+
+  string(field: number, value: string): void {
+    this.bytesField(field, textEncoder.encode(value));
+  }
+
+  message(field: number, write: (writer: Writer) => void): void {
+    const nested = new Writer();
+    write(nested);
+    this.bytesField(field, nested.finish());
+  }
+
+  finish(): Uint8Array {
+    return Uint8Array.from(this.bytes);
+  }
+}
+
+interface Field {
+  number: number;
+  wireType: number;
+  // The 8 bytes of a fixed64 field, or the payload of a length-delimited
+  // one. Empty for other wire types, which no message here uses.
+  data: Uint8Array;
+}
+
+function* fields(bytes: Uint8Array): Generator<Field> {
+  let offset = 0;
+  const varint = (): number => {
+    let value = 0;
+    for (let shift = 0; ; shift += 7) {
+      if (offset >= bytes.length || shift > 63) {
+        throw new ProtobufError("truncated varint");
+      }
+      const byte = bytes[offset++];
+      value += (byte & 0x7f) * 2 ** shift;
+      if (byte < 0x80) {
+        return value;
+      }
+    }
+  };
+  const take = (length: number): Uint8Array => {
+    if (offset + length > bytes.length) {
+      throw new ProtobufError("unexpected end of input");
+    }
+    offset += length;
+    return bytes.subarray(offset - length, offset);
+  };
+
+  while (offset < bytes.length) {
+    const key = varint();
+    const number = Math.floor(key / 8);
+    const wireType = key % 8;
+    if (number === 0) {
+      throw new ProtobufError("invalid field number 0");
+    }
+    switch (wireType) {
+      case VARINT:
+        varint();
+        yield { number, wireType, data: new Uint8Array() };
+        break;
+      case FIXED64:
+        yield { number, wireType, data: take(8) };
+        break;
+      case LENGTH_DELIMITED:
+        yield { number, wireType, data: take(varint()) };
+        break;
+      case FIXED32:
+        take(4);
+        yield { number, wireType, data: new Uint8Array() };
+        break;
+      default:
+        throw new ProtobufError(`unsupported wire type ${wireType}`);
+    }
+  }
+}
+
+function readDouble(field: Field): number {
+  if (field.wireType !== FIXED64) {
+    throw new ProtobufError(`field ${field.number} must be a double`);
+  }
+  const { buffer, byteOffset } = field.data;
+  return new DataView(buffer, byteOffset, 8).getFloat64(0, true);
+}
+
+function readString(field: Field): string {
+  if (field.wireType !== LENGTH_DELIMITED) {
+    throw new ProtobufError(`field ${field.number} must be a string`);
+  }
+  return textDecoder.decode(field.data);
+}
+
+export function encodeOperationRequest(request: OperationRequest): Uint8Array {
+  const writer = new Writer();
+  writer.double(1, request.a);
+  writer.double(2, request.b);
+  return writer.finish();
+}
+
+// Operands absent from the message are 0, as proto3 leaves them out when
+// they are.
+export function decodeOperationRequest(bytes: Uint8Array): OperationRequest {
+  const request: OperationRequest = { a: 0, b: 0 };
+  for (const field of fields(bytes)) {
+    if (field.number === 1) {
+      request.a = readDouble(field);
+    } else if (field.number === 2) {
+      request.b = readDouble(field);
+    }
+  }
+  return request;
+}
+
+export function encodeOperationResponse(
+  response: OperationResponse
+): Uint8Array {
+  const writer = new Writer();
+  writer.double(1, response.result);
+  if (response.exact !== undefined) {
+    writer.string(2, response.exact);
+  }
+  return writer.finish();
+}
+
+export function decodeOperationResponse(bytes: Uint8Array): OperationResponse {
+  const response: OperationResponse = { result: 0 };
+  for (const field of fields(bytes)) {
+    if (field.number === 1) {
+      response.result = readDouble(field);
+    } else if (field.number === 2) {
+      response.exact = readString(field);
+    }
+  }
+  return response;
+}
+
+export function encodeErrorResponse(
+  response: ErrorResponse | ValidationErrorResponse
+): Uint8Array {
+  const writer = new Writer();
+  writer.string(1, response.error);
+  writer.string(2, response.code);
+  writer.string(3, response.timestamp);
+  for (const error of "errors" in response ? response.errors : []) {
+    writer.message(4, (nested) => {
+      nested.string(1, error.field);
+      nested.string(2, error.message);
+    });
+  }
+  return writer.finish();
+}
+
+export function decodeErrorResponse(
+  bytes: Uint8Array
+): ValidationErrorResponse {
+  const response: ValidationErrorResponse = {
+    error: "",
+    code: "internal_error",
+    timestamp: "",
+    errors: [],
+  };
+  for (const field of fields(bytes)) {
+    if (field.number === 1) {
+      response.error = readString(field);
+    } else if (field.number === 2) {
+      response.code = readString(field) as ErrorResponse["code"];
+    } else if (field.number === 3) {
+      response.timestamp = readString(field);
+    } else if (field.number === 4) {
+      const error = { field: "", message: "" };
+      for (const nested of fields(field.data)) {
+        if (nested.number === 1) {
+          error.field = readString(nested);
+        } else if (nested.number === 2) {
+          error.message = readString(nested);
+        }
+      }
+      response.errors.push(error);
+    }
+  }
+  return response;
+}
@@ -0,0 +1,44 @@
+import { CalculatorClientError } from "../client";
+import type { CalculatorClient } from "../client";
+import { CircuitOpenError } from "./breaker";
+import type { CircuitBreaker } from "./breaker";
+import type { CalculatorService } from "./operations";
+
+// Thrown when the upstream calculator fails or cannot be reached.
+export class UpstreamError extends Error {
+  constructor(cause: unknown) {
+    super("Upstream calculator failed", { cause });
+    this.name = "UpstreamError";
+  }
+}
+
+// Wraps a service so that add is computed by an upstream calculator instead of
+// locally. Other operations are unchanged. Any upstream failure other than the
+// request's own deadline passing becomes an UpstreamError. With a breaker,
+// calls go through it: upstream failures count against it, but rejections
+// of the operands (4xx) and the request's own deadline do not, and while it
+// is open add throws CircuitOpenError without calling the upstream.
+export function proxyAdd(
+  service: CalculatorService,
+  upstream: CalculatorClient,
+  breaker?: CircuitBreaker
+): CalculatorService {
+  return {
+    ...service,
+    add: async (a, b, signal) => {
+      const isFailure = (error: unknown) =>
+        !signal?.aborted &&
+        !(error instanceof CalculatorClientError && error.status < 500);
+      try {
+        return breaker
+          ? await breaker.call(() => upstream.add(a, b, signal), isFailure)
+          : await upstream.add(a, b, signal);
+      } catch (error) {
+        if (signal?.aborted || error instanceof CircuitOpenError) {
+          throw error;
+        }
+        throw new UpstreamError(error);
+      }
+    },
+  };
+}
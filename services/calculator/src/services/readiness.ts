@@ -0,0 +1,71 @@
+import { add } from "./calculator";
+import { untilAborted } from "./deadline";
+
+// A named probe of something the service depends on. check resolves when the
+// dependency is usable and throws or rejects when it is not. It should stop
+// waiting when signal aborts.
+export interface ReadinessCheck {
+  name: string;
+  check: (signal: AbortSignal) => unknown;
+}
+
+export interface ReadinessCheckResult {
+  name: string;
+  status: "ok" | "failing";
+  latencyMs: number;
+  // Why the check failed; absent when it passed.
+  error?: string;
+}
+
+// Simulated outage for exercising monitoring: while degraded, GET /readyz
+// reports "degraded" with 503 whatever its checks say. Toggled through the
+// /admin routes and held in isolate memory, so it affects one instance.
+export class DegradedMode {
+  private degraded = false;
+
+  get active(): boolean {
+    return this.degraded;
+  }
+
+  degrade(): void {
+    this.degraded = true;
+  }
+
+  recover(): void {
+    this.degraded = false;
+  }
+}
+
+// Confirms the arithmetic core gives a known answer.
+export const selfArithmeticCheck: ReadinessCheck = {
+  name: "arithmetic",
+  check: () => {
+    if (add(2, 2) !== 4) {
+      throw new Error("2 + 2 did not equal 4");
+    }
+  },
+};
+
+// Runs the checks concurrently and times each one. A check still running when
+// signal aborts fails with the abort reason.
+export function runReadinessChecks(
+  checks: readonly ReadinessCheck[],
+  signal: AbortSignal
+): Promise<ReadinessCheckResult[]> {
+  return Promise.all(
+    checks.map(async ({ name, check }): Promise<ReadinessCheckResult> => {
+      const start = performance.now();
+      try {
+        await untilAborted(Promise.resolve().then(() => check(signal)), signal);
+        return { name, status: "ok", latencyMs: performance.now() - start };
+      } catch (error) {
+        return {
+          name,
+          status: "failing",
+          latencyMs: performance.now() - start,
+          error: error instanceof Error ? error.message : String(error),
+        };
+      }
+    })
+  );
+}
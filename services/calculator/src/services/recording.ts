@@ -0,0 +1,156 @@
+export const DEFAULT_RECORDING_SIZE = 1000;
+
+// A request or response as recorded: headers by lower-case name, and the
+// body as text, or as base64 when it is binary such as MessagePack.
+export interface RecordedMessage {
+  headers: Record<string, string>;
+  body: string;
+  base64?: boolean;
+}
+
+export interface RecordedRequest extends RecordedMessage {
+  method: string;
+  // Path and query string, e.g. "/divide?places=2".
+  path: string;
+}
+
+export interface RecordedResponse extends RecordedMessage {
+  status: number;
+}
+
+export interface RecordedExchange {
+  request: RecordedRequest;
+  response: RecordedResponse;
+}
+
+// The JSON document served by GET /recording and read by replay.
+export interface RecordingFile {
+  exchanges: RecordedExchange[];
+}
+
+// The most recent request and response pairs, oldest first, for saving as a
+// RecordingFile and replaying later. Once full, the oldest is dropped.
+export class Recording {
+  private readonly recorded: RecordedExchange[] = [];
+  readonly size: number;
+
+  constructor(size: number = DEFAULT_RECORDING_SIZE) {
+    if (!Number.isInteger(size) || size < 1) {
+      throw new RangeError("recording size must be a positive integer");
+    }
+    this.size = size;
+  }
+
+  record(exchange: RecordedExchange): void {
+    this.recorded.push(exchange);
+    if (this.recorded.length > this.size) {
+      this.recorded.shift();
+    }
+  }
+
+  exchanges(): RecordedExchange[] {
+    return [...this.recorded];
+  }
+}
+
+function isText(contentType: string | undefined): boolean {
+  if (contentType === undefined) {
+    return true;
+  }
+  const type = contentType.split(";")[0].trim().toLowerCase();
+  return (
+    type.startsWith("text/") ||
+    type === "application/json" ||
+    type.endsWith("+json") ||
+    type === "application/javascript"
+  );
+}
+
+function toBase64(bytes: Uint8Array): string {
+  let binary = "";
+  for (const byte of bytes) {
+    binary += String.fromCharCode(byte);
+  }
+  return btoa(binary);
+}
+
+function fromBase64(text: string): Uint8Array {
+  return Uint8Array.from(atob(text), (char) => char.charCodeAt(0));
+}
+
+async function readMessage(
+  message: Request | Response
+): Promise<RecordedMessage> {
+  const headers = Object.fromEntries(message.headers);
+  const bytes = new Uint8Array(await message.arrayBuffer());
+  if (isText(message.headers.get("Content-Type") ?? undefined)) {
+    return { headers, body: new TextDecoder().decode(bytes) };
+  }
+  return { headers, body: toBase64(bytes), base64: true };
+}
+
+// The recorders clone what they read, so the original can still be used.
+export async function recordRequest(
+  request: Request
+): Promise<RecordedRequest> {
+  const url = new URL(request.url);
+  return {
+    method: request.method,
+    path: url.pathname + url.search,
+    ...(await readMessage(request.clone())),
+  };
+}
+
+export async function recordResponse(
+  response: Response
+): Promise<RecordedResponse> {
+  return { status: response.status, ...(await readMessage(response.clone())) };
+}
+
+function toRequest({ method, path, headers, body, base64 }: RecordedRequest) {
+  const hasBody = method !== "GET" && method !== "HEAD" && body !== "";
+  const replayed = new Headers(headers);
+  replayed.delete("Content-Length");
+  return new Request(new URL(path, "http://localhost"), {
+    method,
+    headers: replayed,
+    body: hasBody ? (base64 ? fromBase64(body) : body) : undefined,
+  });
+}
+
+export interface ReplayMismatch {
+  // Position of the exchange in the recording.
+  index: number;
+  // e.g. "POST /add".
+  request: string;
+  expected: RecordedResponse;
+  actual: RecordedResponse;
+}
+
+// Re-issues each recorded request in order to fetch, such as a fresh app's,
+// and returns the exchanges whose response differs in status, Content-Type
+// or body; an empty list means every response matched. Other headers, such
+// as X-Request-Id, vary by design and are not compared, so bodies carrying
+// the time need the same fixed clock on both sides.
+export async function replay(
+  recording: RecordingFile,
+  fetch: (request: Request) => Response | Promise<Response>
+): Promise<ReplayMismatch[]> {
+  const mismatches: ReplayMismatch[] = [];
+  for (const [index, { request, response }] of recording.exchanges.entries()) {
+    const actual = await recordResponse(await fetch(toRequest(request)));
+    const matches =
+      actual.status === response.status &&
+      actual.headers["content-type"] === response.headers["content-type"] &&
+      actual.body === response.body;
+    if (!matches) {
+      mismatches.push({
+        index,
+        request: `${request.method} ${request.path}`,
+        expected: response,
+        actual,
+      });
+    }
+  }
+  return mismatches;
+}
@@ -0,0 +1,56 @@
+import { InvalidInputError } from "./calculator";
+import type { Awaitable } from "./operations";
+
+export type RpnOperationName = "add" | "subtract" | "multiply" | "divide";
+
+const RPN_OPERATORS: Readonly<Record<string, RpnOperationName>> = {
+  "+": "add",
+  "-": "subtract",
+  "*": "multiply",
+  "/": "divide",
+};
+
+// Operand tokens use JSON's number syntax, so "-3" is a number and "-" alone
+// is subtraction.
+const NUMBER_TOKEN = /^-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?$/;
+
+// Evaluates a Reverse Polish Notation expression such as
+// ["3", "4", "+", "2", "*"] with a stack. Each operator pops its two operands
+// and pushes what apply returns for them, so the caller decides how the
+// operation is computed. A malformed expression throws InvalidInputError
+// naming the offending token.
+export async function evaluateRpn(
+  tokens: readonly string[],
+  apply: (name: RpnOperationName, a: number, b: number) => Awaitable<number>
+): Promise<number> {
+  const stack: number[] = [];
+  for (const [index, token] of tokens.entries()) {
+    if (Object.hasOwn(RPN_OPERATORS, token)) {
+      if (stack.length < 2) {
+        throw new InvalidInputError(
+          `invalid input: stack underflow at token ${index} ("${token}" ` +
+            `needs 2 operands, found ${stack.length})`
+        );
+      }
+      const b = stack.pop() as number;
+      const a = stack.pop() as number;
+      stack.push(await apply(RPN_OPERATORS[token], a, b));
+    } else if (NUMBER_TOKEN.test(token)) {
+      stack.push(Number(token));
+    } else {
+      throw new InvalidInputError(
+        `invalid input: unknown token "${token}" at token ${index}`
+      );
+    }
+  }
+  if (stack.length === 0) {
+    throw new InvalidInputError("invalid input: expression is empty");
+  }
+  if (stack.length > 1) {
+    throw new InvalidInputError(
+      `invalid input: ${stack.length} operands left on the stack, ` +
+        "expected 1"
+    );
+  }
+  return stack[0];
+}
@@ -0,0 +1,51 @@
+import type { Clock } from "./clock";
+
+export interface StatsSnapshot {
+  // Every operation attempted, including those that failed.
+  total: number;
+  errors: number;
+  operations: Record<string, number>;
+  uptimeSeconds: number;
+}
+
+// Lifetime operation counts for the /stats summary. Counts live in isolate
+// memory and JavaScript runs one callback at a time, so plain counters need
+// no locking.
+//
+// Uptime runs from the first record or snapshot rather than from
+// construction: the default app is built at Worker startup, when the clock
+// still reads the Unix epoch.
+export class Stats {
+  private readonly clock: Clock;
+  private startedAt?: number;
+  private total = 0;
+  private errors = 0;
+  private readonly operations = new Map<string, number>();
+
+  constructor(clock: Clock) {
+    this.clock = clock;
+  }
+
+  private start(): number {
+    return (this.startedAt ??= this.clock.now().getTime());
+  }
+
+  record(operation: string, succeeded: boolean): void {
+    this.start();
+    this.total++;
+    if (!succeeded) {
+      this.errors++;
+    }
+    this.operations.set(operation, (this.operations.get(operation) ?? 0) + 1);
+  }
+
+  snapshot(): StatsSnapshot {
+    const startedAt = this.start();
+    return {
+      total: this.total,
+      errors: this.errors,
+      operations: Object.fromEntries(this.operations),
+      uptimeSeconds: (this.clock.now().getTime() - startedAt) / 1000,
+    };
+  }
+}
@@ -0,0 +1,54 @@
+import {
+  validateCoefficients,
+  validateEpsilon,
+  validateInputList,
+  validateFactorialOperand,
+  validateIntegers,
+  validatePlaces,
+  validateNonZeroDivisor,
+  validatePositive,
+  validateProductMagnitude,
+} from "./calculator";
+
+// A validator throws InvalidInputError when the operands fall outside the
+// operation's domain.
+export type Validator = (operands: number[]) => void;
+
+// Maps operation names to their preconditions. Every operation is checked
+// against the shared NaN/Infinity rule first, then against any validators
+// registered for it, in registration order.
+export class ValidatorRegistry {
+  private validators = new Map<string, Validator[]>();
+
+  register(operation: string, validator: Validator): this {
+    const existing = this.validators.get(operation) ?? [];
+    this.validators.set(operation, [...existing, validator]);
+    return this;
+  }
+
+  validate(operation: string, operands: number[]): void {
+    validateInputList(operands);
+    for (const validator of this.validators.get(operation) ?? []) {
+      validator(operands);
+    }
+  }
+}
+
+// Returns a registry holding the built-in domain rules, ready to be extended.
+export function createDefaultValidators(): ValidatorRegistry {
+  return new ValidatorRegistry()
+    .register("divide", ([, b]) => validateNonZeroDivisor(b))
+    .register("divmod", ([, b]) => validateNonZeroDivisor(b))
+    .register("multiply", ([a, b]) => validateProductMagnitude(a, b))
+    .register("factorial", ([a]) => validateFactorialOperand(a))
+    .register("round", ([, places]) => validatePlaces(places))
+    .register("gcd", ([a, b]) => validateIntegers(a, b))
+    .register("lcm", ([a, b]) => validateIntegers(a, b))
+    .register("log", ([a]) => validatePositive(a))
+    .register("ln", ([a]) => validatePositive(a))
+    .register("approxEqual", ([, , epsilon]) => validateEpsilon(epsilon))
+    // The operands are the coefficients followed by x.
+    .register("polynomial", (operands) =>
+      validateCoefficients(operands.slice(0, -1))
+    );
+}
@@ -1,20 +1,399 @@
+import type { BenchmarkResult } from "../services/bench";
+import type { Clock } from "../services/clock";
+import type { ResponseEncoder } from "../services/encoders";
+import type { FleetNodeHealth, FleetOptions } from "../services/fleet";
+import type { History, HistoryEntry } from "../services/history";
+import type { RoundingMode, Summary } from "../services/calculator";
+import type { IeeeNumber } from "../services/ieee";
+import type {
+  OperationMetadata,
+  OperationRegistry,
+} from "../services/metadata";
+import type { Metrics } from "../services/metrics";
+import type { Semaphore } from "../services/pool";
+import type { Recording, RecordingFile } from "../services/recording";
+import type {
+  CalculatorService,
+  ResultTransform,
+} from "../services/operations";
+import type {
+  DegradedMode,
+  ReadinessCheck,
+  ReadinessCheckResult,
+} from "../services/readiness";
+import type { Stats, StatsSnapshot } from "../services/stats";
+import type { ValidatorRegistry } from "../services/validators";
+
+// Hono environment shared by the app, its routes and middleware.
+export interface AppEnv {
+  Bindings: {
+    // Shared secret for HMAC request signing. Unset disables the check.
+    SIGNING_SECRET?: string;
+    // Comma-separated keys accepted in X-API-Key. Unset disables the check.
+    API_KEYS?: string;
+    // "true" enables the GET /bench development endpoint.
+    ENABLE_BENCH?: string;
+    // "true" honours ?delay= on any request, for client timeout testing.
+    ENABLE_DELAY?: string;
+    // "true" enables the /admin/degrade and /admin/recover endpoints.
+    ENABLE_ADMIN?: string;
+    // "true" records requests and responses, served at GET /recording.
+    RECORD_REQUESTS?: string;
+    // "true" logs the start of each request body, for debugging.
+    LOG_REQUEST_BODIES?: string;
+  };
+  Variables: {
+    clock: Clock;
+    validators: ValidatorRegistry;
+    operations: OperationRegistry;
+    service: CalculatorService;
+    metrics: Metrics;
+    stats: Stats;
+    history: History;
+    // Milliseconds an operation may run before the request fails with 503.
+    requestTimeoutMs: number;
+    // Most operations of one POST /batch evaluated at the same time.
+    batchConcurrency: number;
+    // Workers shared by all POST /batch requests; see AppOptions.
+    batchWorkers: Semaphore;
+    resultTransform: ResultTransform;
+    // Whether operation responses carry isInteger; see AppOptions.
+    reportIsInteger: boolean;
+    // Whether /divide responses carry isExact; see AppOptions.
+    reportExactDivision: boolean;
+    // What becomes of unexpected errors; see AppOptions.
+    errorPolicy: ErrorPolicy;
+    readinessChecks: readonly ReadinessCheck[];
+    degradedMode: DegradedMode;
+    // Filled while RECORD_REQUESTS is "true".
+    recording: Recording;
+    // Calculators checked by GET /health/fleet; none unless configured.
+    fleet: Required<FleetOptions>;
+    // Response body encodings, chosen per request by Accept; the first is the
+    // default.
+    encoders: readonly ResponseEncoder[];
+    // Top-level keys renamed in JSON bodies; see AppOptions.
+    responseKeys: Readonly<Record<string, string>>;
+    // Set per request by the requestId middleware.
+    requestId: string;
+  };
+}
+
 export interface OperationRequest {
   a: number;
   b: number;
 }
 
+// Weighted sum a*wa + b*wb.
+export interface WeightedSumRequest {
+  a: number;
+  wa: number;
+  b: number;
+  wb: number;
+}
+
+// Linear conversion value*scale + offset.
+export interface ConvertRequest {
+  value: number;
+  scale: number;
+  offset: number;
+}
+
+// Fused multiply-add a*b + c.
+export interface FmaRequest {
+  a: number;
+  b: number;
+  c: number;
+}
+
+// Bounds value to [min, max].
+export interface ClampRequest {
+  value: number;
+  min: number;
+  max: number;
+}
+
+// Compares a and b with a tolerance of epsilon.
+export interface ApproxEqualRequest {
+  a: number;
+  b: number;
+  epsilon: number;
+}
+
+// Rounds value to places decimal places. mode defaults to half_even.
+export interface RoundRequest {
+  value: number;
+  places: number;
+  mode?: RoundingMode;
+}
+
+export interface UnaryOperationRequest {
+  a: number;
+}
+
+export interface NumberListRequest {
+  numbers: number[];
+}
+
+// The polynomial with coefficients highest degree first, e.g. [2, 3, 1] for
+// 2x² + 3x + 1, evaluated at x.
+export interface PolynomialRequest {
+  coefficients: number[];
+  x: number;
+}
+
+// A Reverse Polish Notation expression, e.g. ["3", "4", "+", "2", "*"].
+export interface RpnRequest {
+  tokens: string[];
+}
+
+export interface SumListRequest {
+  numbers: unknown[];
+}
+
+// A single operation addressed by name, as sent over the WebSocket endpoint.
+// Unary operations ignore b.
+export interface NamedOperationRequest {
+  operation: string;
+  a: number;
+  b?: number;
+}
+
+// Named operations evaluated independently by POST /batch.
+export interface BatchRequest {
+  operations: NamedOperationRequest[];
+}
+
+// Returned by POST /divmod.
+export interface DivModResponse {
+  quotient: number;
+  remainder: number;
+}
+
+// Returned by POST /factorial.
+export interface FactorialResponse {
+  // The factorial in decimal, exact at any size.
+  exact: string;
+  // Nearest float64, or null beyond 170! where float64 overflows.
+  result: number | null;
+}
+
+// Returned by GET /recording.
+export type RecordingResponse = RecordingFile;
+
+// Returned by POST /stats/summary.
+export type SummaryResponse = Summary;
+
+// Returned by POST /compare.
+export interface CompareResponse {
+  // -1 when a < b, 0 when a == b, 1 when a > b.
+  comparison: -1 | 0 | 1;
+}
+
+// Returned by POST /approx-equal.
+export interface ApproxEqualResponse {
+  equal: boolean;
+}
+
+// OperationResponse in IEEE mode (?ieee=true), where NaN and the infinities
+// are sent as strings.
+export interface IeeeOperationResponse {
+  result: IeeeNumber;
+}
+
 export interface OperationResponse {
   result: number;
+  // Exact result, present only when exact mode is requested: an integer in
+  // decimal, or a fraction in lowest terms such as "1/3" for division.
+  exact?: string;
+  // Whether result is a whole number, present only with
+  // AppOptions.reportIsInteger.
+  isInteger?: boolean;
+  // Whether a /divide result is exactly a / b rather than rounded, present
+  // only with AppOptions.reportExactDivision.
+  isExact?: boolean;
 }
 
+// "respond" reports an unexpected error as a bad request, like any other;
+// "panic" rethrows it to the app's error handler, which logs its stack and
+// answers 500.
+export type ErrorPolicy = "respond" | "panic";
+
+// Stable, machine-readable error identifiers. Clients should branch on these
+// rather than on the human-readable message.
+export type ErrorCode =
+  | "invalid_input"
+  | "invalid_request"
+  | "malformed_json"
+  | "empty_body"
+  | "malformed_protobuf"
+  | "method_not_allowed"
+  | "not_found"
+  | "internal_error"
+  | "invalid_idempotency_key"
+  | "idempotency_conflict"
+  | "unknown_operation"
+  | "upgrade_required"
+  | "invalid_callback"
+  | "health_method_not_allowed"
+  | "unsupported_media_type"
+  | "headers_too_large"
+  | "content_length_mismatch"
+  | "body_required"
+  | "overloaded"
+  | "timeout"
+  | "invalid_signature"
+  | "upstream_error"
+  | "request_cancelled"
+  | "missing_api_key"
+  | "invalid_api_key"
+  | "insecure_request"
+  | "circuit_open";
+
 export interface ErrorResponse {
   error: string;
+  code: ErrorCode;
+  // ISO 8601 time at which the error was produced.
+  timestamp: string;
+}
+
+export interface FieldError {
+  field: string;
+  message: string;
+}
+
+// Returned when the request body is well-formed JSON but fields are missing
+// or have the wrong type. Lists every offending field, not just the first.
+export interface ValidationErrorResponse extends ErrorResponse {
+  errors: FieldError[];
+}
+
+// One reply per requested operation, in request order.
+export interface BatchResponse {
+  results: (OperationResponse | ErrorResponse)[];
+}
+
+export type StatsResponse = StatsSnapshot;
+
+// Most recent operations, newest first.
+export interface HistoryResponse {
+  entries: HistoryEntry[];
+}
+
+export type BenchResponse = BenchmarkResult;
+
+// Returned by GET /operations/{name}/describe.
+export type OperationDescriptionResponse = OperationMetadata;
+
+// Returned by GET /ping.
+export interface PingResponse {
+  // The ?nonce= query parameter, or null when none was sent.
+  nonce: string | null;
+  // ISO 8601 time at which the ping was answered.
+  serverTime: string;
+  // Random id of the isolate that answered, stable for its lifetime.
+  instanceId: string;
+}
+
+// Returned by GET /schema: names usable as GET /schema/{name}.
+export interface SchemaListResponse {
+  schemas: string[];
 }
 
 export interface HealthResponse {
   status: string;
 }
 
+// checks is included only with ?verbose=true. "degraded" means an outage is
+// being simulated through /admin/degrade.
+export interface ReadinessResponse {
+  status: "ok" | "unavailable" | "degraded";
+  checks?: ReadinessCheckResult[];
+}
+
+// "degraded" means at least one node is down.
+export interface FleetHealthResponse {
+  status: "ok" | "degraded";
+  nodes: FleetNodeHealth[];
+}
+
+// Returned by POST /admin/degrade and POST /admin/recover.
+export interface DegradedModeResponse {
+  degraded: boolean;
+}
+
+export interface EnvelopeMeta {
+  // Milliseconds the request took to handle.
+  durationMs: number;
+  // The request's X-Request-Id; see the requestId middleware.
+  requestId: string;
+}
+
+// Body returned with ?envelope=true: the usual body under data on success,
+// or under error on failure, with request metadata alongside.
+export type Envelope<T = unknown, E = ErrorResponse> =
+  | { data: T; meta: EnvelopeMeta }
+  | { error: E; meta: EnvelopeMeta };
+
+// Treats anything but a plain object as having no fields.
+function fieldsOf(obj: unknown): Record<string, unknown> {
+  return typeof obj === "object" && obj !== null && !Array.isArray(obj)
+    ? (obj as Record<string, unknown>)
+    : {};
+}
+
+// Reports each of the given fields that is absent or not a number. A body
+// that is not an object is missing all of them.
+export function operandErrors(
+  obj: unknown,
+  fields: readonly string[]
+): FieldError[] {
+  const body = fieldsOf(obj);
+  const errors: FieldError[] = [];
+  for (const field of fields) {
+    if (!Object.hasOwn(body, field) || body[field] === undefined) {
+      errors.push({ field, message: "required" });
+    } else if (typeof body[field] !== "number") {
+      errors.push({ field, message: "must be a number" });
+    }
+  }
+  return errors;
+}
+
+// Reports a missing or non-array list, or each element not of the given type.
+function listErrors(
+  obj: unknown,
+  field: string,
+  type: "number" | "string"
+): FieldError[] {
+  const body = fieldsOf(obj);
+  const list = body[field];
+  if (!Object.hasOwn(body, field) || list === undefined) {
+    return [{ field, message: "required" }];
+  }
+  if (!Array.isArray(list)) {
+    return [{ field, message: "must be an array" }];
+  }
+  const errors: FieldError[] = [];
+  list.forEach((element, index) => {
+    if (typeof element !== type) {
+      errors.push({
+        field: `${field}[${index}]`,
+        message: `must be a ${type}`,
+      });
+    }
+  });
+  return errors;
+}
+
+export function numberListErrors(obj: unknown, field: string): FieldError[] {
+  return listErrors(obj, field, "number");
+}
+
+export function stringListErrors(obj: unknown, field: string): FieldError[] {
+  return listErrors(obj, field, "string");
+}
+
 export function isOperationRequest(obj: unknown): obj is OperationRequest {
   return (
     typeof obj === "object" &&
@@ -25,3 +404,36 @@ export function isOperationRequest(obj: unknown): obj is OperationRequest {
     typeof (obj as OperationRequest).b === "number"
   );
 }
+
+export function isUnaryOperationRequest(
+  obj: unknown
+): obj is UnaryOperationRequest {
+  return (
+    typeof obj === "object" &&
+    obj !== null &&
+    "a" in obj &&
+    typeof (obj as UnaryOperationRequest).a === "number"
+  );
+}
+
+// Elements are validated individually while streaming, so only the list
+// itself is checked here.
+export function isSumListRequest(obj: unknown): obj is SumListRequest {
+  return (
+    typeof obj === "object" &&
+    obj !== null &&
+    "numbers" in obj &&
+    Array.isArray((obj as SumListRequest).numbers)
+  );
+}
+
+export function isNamedOperationRequest(
+  obj: unknown
+): obj is NamedOperationRequest {
+  return (
+    typeof obj === "object" &&
+    obj !== null &&
+    "operation" in obj &&
+    typeof (obj as NamedOperationRequest).operation === "string"
+  );
+}
@@ -0,0 +1,80 @@
+import { describe, it, expect } from "vitest";
+import { CalculatorClient, CalculatorClientError } from "../src/client";
+import app from "../src/index";
+
+const client = new CalculatorClient("http://calculator/", (request) =>
+  app.fetch(request)
+);
+
+describe("CalculatorClient", () => {
+  it("returns the result of a remote operation", async () => {
+    expect(await client.add(2, 3)).toBe(5);
+    expect(await client.calculate("multiply", 4, 5)).toBe(20);
+  });
+
+  it("posts JSON operands to the operation's path", async () => {
+    let seen: Request | undefined;
+    const recording = new CalculatorClient("http://calculator/api", (r) => {
+      seen = r;
+      return Promise.resolve(Response.json({ result: 1 }));
+    });
+
+    await recording.calculate("subtract", 3, 2);
+
+    expect(seen?.method).toBe("POST");
+    expect(seen?.url).toBe("http://calculator/api/subtract");
+    expect(seen?.headers.get("Content-Type")).toBe("application/json");
+    expect(await seen?.json()).toEqual({ a: 3, b: 2 });
+  });
+
+  it("throws CalculatorClientError with the error code", async () => {
+    const error = await client.calculate("divide", 1, 0).catch((e) => e);
+
+    expect(error).toBeInstanceOf(CalculatorClientError);
+    expect(error.status).toBe(400);
+    expect(error.code).toBe("invalid_input");
+    expect(error.message).toBe("invalid input: division by zero");
+  });
+
+  it("reads the remote health check", async () => {
+    expect(await client.health()).toEqual({ status: "ok" });
+  });
+
+  it("throws CalculatorClientError for an unhealthy instance", async () => {
+    const unhealthy = new CalculatorClient("http://calculator", () =>
+      Promise.resolve(new Response("Bad Gateway", { status: 502 }))
+    );
+
+    const error = await unhealthy.health().catch((e) => e);
+
+    expect(error).toBeInstanceOf(CalculatorClientError);
+    expect(error.status).toBe(502);
+  });
+
+  it("reads the remote health check", async () => {
+    expect(await client.health()).toEqual({ status: "ok" });
+  });
+
+  it("throws CalculatorClientError for an unhealthy instance", async () => {
+    const unhealthy = new CalculatorClient("http://calculator", () =>
+      Promise.resolve(new Response("Bad Gateway", { status: 502 }))
+    );
+
+    const error = await unhealthy.health().catch((e) => e);
+
+    expect(error).toBeInstanceOf(CalculatorClientError);
+    expect(error.status).toBe(502);
+  });
+
+  it("throws CalculatorClientError for a non-JSON error body", async () => {
+    const failing = new CalculatorClient("http://calculator", () =>
+      Promise.resolve(new Response("Bad Gateway", { status: 502 }))
+    );
+
+    const error = await failing.add(1, 2).catch((e) => e);
+
+    expect(error).toBeInstanceOf(CalculatorClientError);
+    expect(error.status).toBe(502);
+    expect(error.code).toBeUndefined();
+  });
+});
@@ -0,0 +1,108 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+
+function post(app: ReturnType<typeof createApp>, key?: string, env?: object) {
+  const headers: Record<string, string> = {
+    "Content-Type": "application/json",
+  };
+  if (key !== undefined) {
+    headers["X-API-Key"] = key;
+  }
+  return app.fetch(
+    new Request("http://localhost/add", {
+      method: "POST",
+      headers,
+      body: JSON.stringify({ a: 2, b: 3 }),
+    }),
+    env
+  );
+}
+
+describe("requireApiKey middleware", () => {
+  const app = createApp({ apiKey: { keys: ["first-key", "second-key"] } });
+
+  it.each(["first-key", "second-key"])(
+    "passes a request with key %s to the handler",
+    async (key) => {
+      const response = await post(app, key);
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5 });
+    }
+  );
+
+  it("returns 401 when the header is missing", async () => {
+    const response = await post(app);
+
+    expect(response.status).toBe(401);
+    const json = await response.json();
+    expect(json).toEqual({
+      error: "Missing X-API-Key header",
+      code: "missing_api_key",
+      timestamp: expect.any(String),
+    });
+  });
+
+  it("returns 401 when the header is empty", async () => {
+    const response = await post(app, "");
+
+    expect(response.status).toBe(401);
+  });
+
+  it.each(["wrong-key", "first-ke", "first-key2"])(
+    "returns 403 for the unknown key %s",
+    async (key) => {
+      const response = await post(app, key);
+
+      expect(response.status).toBe(403);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Invalid API key",
+        code: "invalid_api_key",
+        timestamp: expect.any(String),
+      });
+    }
+  );
+
+  it.each(["/health", "/readyz"])("serves %s without a key", async (path) => {
+    const response = await app.request(path);
+
+    expect(response.status).toBe(200);
+  });
+
+  it("requires a key on health checks when no paths are exempt", async () => {
+    const strict = createApp({
+      apiKey: { keys: ["first-key"], exemptPaths: [] },
+    });
+
+    const response = await strict.request("/health");
+
+    expect(response.status).toBe(401);
+  });
+
+  it("exempts health checks under a prefix", async () => {
+    const prefixed = createApp({
+      prefix: "/api/v1",
+      apiKey: { keys: ["first-key"] },
+    });
+
+    expect((await prefixed.request("/api/v1/health")).status).toBe(200);
+    expect((await prefixed.request("/api/v1/stats")).status).toBe(401);
+  });
+
+  it("reads keys from the API_KEYS binding", async () => {
+    const bound = createApp();
+    const env = { API_KEYS: "first-key, second-key" };
+
+    expect((await post(bound, "second-key", env)).status).toBe(200);
+    expect((await post(bound, "other-key", env)).status).toBe(403);
+    expect((await post(bound, undefined, env)).status).toBe(401);
+  });
+
+  it("requires no key when none are configured", async () => {
+    const response = await post(createApp());
+
+    expect(response.status).toBe(200);
+  });
+});
@@ -0,0 +1,107 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import type { RequestBodyEntry } from "../../src/middleware/body-log";
+
+const enabled = { LOG_REQUEST_BODIES: "true" };
+
+function post(
+  app: ReturnType<typeof createApp>,
+  body: string,
+  env: Record<string, string> = enabled
+) {
+  const request = new Request("http://localhost/add", {
+    method: "POST",
+    headers: { "Content-Type": "application/json", "X-Request-Id": "req-1" },
+    body,
+  });
+  return app.fetch(request, env);
+}
+
+describe("logRequestBodies middleware", () => {
+  it("logs the body and leaves it readable by the handler", async () => {
+    const logged: RequestBodyEntry[] = [];
+    const app = createApp({
+      requestBodyLog: { log: (entry) => logged.push(entry) },
+    });
+
+    const response = await post(app, '{"a": 2, "b": 3}');
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+    expect(logged).toEqual([
+      {
+        level: "debug",
+        message: "request body",
+        method: "POST",
+        path: "/add",
+        requestId: "req-1",
+        body: '{"a": 2, "b": 3}',
+        truncated: false,
+      },
+    ]);
+  });
+
+  it("logs a malformed body before it is rejected", async () => {
+    const logged: RequestBodyEntry[] = [];
+    const app = createApp({
+      requestBodyLog: { log: (entry) => logged.push(entry) },
+    });
+
+    const response = await post(app, '{"a": 2,');
+
+    expect(response.status).toBe(400);
+    expect(logged.map((entry) => entry.body)).toEqual(['{"a": 2,']);
+  });
+
+  it("truncates bodies longer than maxBytes", async () => {
+    const logged: RequestBodyEntry[] = [];
+    const app = createApp({
+      requestBodyLog: { maxBytes: 8, log: (entry) => logged.push(entry) },
+    });
+    const body = JSON.stringify({ a: 2, b: 3, padding: "x".repeat(10_000) });
+
+    const response = await post(app, body);
+
+    expect(response.status).toBe(200);
+    expect(logged[0].body).toBe('{"a":2,"');
+    expect(logged[0].truncated).toBe(true);
+  });
+
+  it("does not truncate a body of exactly maxBytes", async () => {
+    const logged: RequestBodyEntry[] = [];
+    const body = '{"a":2,"b":3}';
+    const app = createApp({
+      requestBodyLog: {
+        maxBytes: body.length,
+        log: (entry) => logged.push(entry),
+      },
+    });
+
+    await post(app, body);
+
+    expect(logged[0]).toMatchObject({ body, truncated: false });
+  });
+
+  it("logs nothing unless LOG_REQUEST_BODIES is true", async () => {
+    const logged: RequestBodyEntry[] = [];
+    const app = createApp({
+      requestBodyLog: { log: (entry) => logged.push(entry) },
+    });
+
+    const response = await post(app, '{"a": 2, "b": 3}', {});
+
+    expect(response.status).toBe(200);
+    expect(logged).toEqual([]);
+  });
+
+  it("skips requests without a body", async () => {
+    const logged: RequestBodyEntry[] = [];
+    const app = createApp({
+      requestBodyLog: { log: (entry) => logged.push(entry) },
+    });
+
+    await app.fetch(new Request("http://localhost/health"), enabled);
+
+    expect(logged).toEqual([]);
+  });
+});
@@ -0,0 +1,78 @@
+import { describe, it, expect } from "vitest";
+import { Hono } from "hono";
+import type { MiddlewareHandler } from "hono";
+import { chain } from "../../src/middleware/chain";
+import type { AppEnv } from "../../src/types";
+
+function recording(name: string, calls: string[]): MiddlewareHandler<AppEnv> {
+  return async (_c, next) => {
+    calls.push(name);
+    await next();
+    calls.push(`${name}:after`);
+  };
+}
+
+describe("chain", () => {
+  it("runs middleware in the declared order", async () => {
+    const calls: string[] = [];
+    const app = new Hono<AppEnv>();
+    app.use(
+      "*",
+      chain(
+        recording("first", calls),
+        recording("second", calls),
+        recording("third", calls)
+      )
+    );
+    app.get("/", (c) => {
+      calls.push("handler");
+      return c.text("ok");
+    });
+
+    const response = await app.fetch(new Request("http://localhost/"));
+
+    expect(response.status).toBe(200);
+    expect(calls).toEqual([
+      "first",
+      "second",
+      "third",
+      "handler",
+      "third:after",
+      "second:after",
+      "first:after",
+    ]);
+  });
+
+  it("stops at a middleware that responds without calling next", async () => {
+    const calls: string[] = [];
+    const app = new Hono<AppEnv>();
+    app.use(
+      "*",
+      chain(
+        recording("outer", calls),
+        (c) => c.text("blocked", 403),
+        recording("inner", calls)
+      )
+    );
+    app.get("/", (c) => {
+      calls.push("handler");
+      return c.text("ok");
+    });
+
+    const response = await app.fetch(new Request("http://localhost/"));
+
+    expect(response.status).toBe(403);
+    expect(await response.text()).toBe("blocked");
+    expect(calls).toEqual(["outer", "outer:after"]);
+  });
+
+  it("passes straight through when empty", async () => {
+    const app = new Hono<AppEnv>();
+    app.use("*", chain());
+    app.get("/", (c) => c.text("ok"));
+
+    const response = await app.fetch(new Request("http://localhost/"));
+
+    expect(await response.text()).toBe("ok");
+  });
+});
@@ -0,0 +1,117 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+
+const body = JSON.stringify({ a: 2, b: 3 });
+
+function add(
+  target: ReturnType<typeof createApp>,
+  headers: Record<string, string>,
+  content: BodyInit = body
+) {
+  return target.request("/add", {
+    method: "POST",
+    headers,
+    body: content,
+    // Required by fetch to send a stream.
+    duplex: "half",
+  } as RequestInit);
+}
+
+// The body as a stream of two chunks, with no length known in advance.
+function chunked(text: string): ReadableStream<Uint8Array> {
+  const bytes = new TextEncoder().encode(text);
+  const middle = Math.floor(bytes.length / 2);
+  return new ReadableStream({
+    start(controller) {
+      controller.enqueue(bytes.subarray(0, middle));
+      controller.enqueue(bytes.subarray(middle));
+      controller.close();
+    },
+  });
+}
+
+describe("checkContentLength middleware", () => {
+  const app = createApp({ checkContentLength: true });
+
+  it("passes a body as long as Content-Length says", async () => {
+    const response = await add(app, {
+      "Content-Length": String(body.length),
+    });
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+  });
+
+  it("counts bytes rather than characters", async () => {
+    // 32 characters, but "é" is two bytes in UTF-8.
+    const text = '{"a": 2, "b": 3, "note": "café"}';
+
+    const response = await add(app, { "Content-Length": "33" }, text);
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+  });
+
+  it("rejects a body shorter than Content-Length with 400", async () => {
+    const response = await add(app, {
+      "Content-Length": String(body.length + 10),
+    });
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toEqual({
+      error: `Content-Length is ${body.length + 10} but the body is ${body.length} bytes`,
+      code: "content_length_mismatch",
+      timestamp: expect.any(String),
+    });
+  });
+
+  it("rejects a body longer than Content-Length with 400", async () => {
+    const response = await add(app, { "Content-Length": "5" });
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toMatchObject({
+      code: "content_length_mismatch",
+    });
+  });
+
+  it("rejects an invalid Content-Length with 400", async () => {
+    const response = await add(app, { "Content-Length": "about 15" });
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toMatchObject({
+      error: "Invalid Content-Length",
+      code: "content_length_mismatch",
+    });
+  });
+
+  it("skips a chunked body without Content-Length", async () => {
+    const response = await add(app, {}, chunked(body));
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+  });
+
+  it("skips the check when Transfer-Encoding is set", async () => {
+    const response = await add(
+      app,
+      { "Content-Length": "5", "Transfer-Encoding": "chunked" },
+      chunked(body)
+    );
+
+    expect(response.status).toBe(200);
+  });
+
+  it("accepts a zero Content-Length without a body", async () => {
+    const response = await app.request("/health", {
+      headers: { "Content-Length": "0" },
+    });
+
+    expect(response.status).toBe(200);
+  });
+
+  it("is off by default", async () => {
+    const response = await add(createApp(), { "Content-Length": "5" });
+
+    expect(response.status).toBe(200);
+  });
+});
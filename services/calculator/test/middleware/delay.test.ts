@@ -0,0 +1,94 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { MAX_DELAY_MS, parseDelay } from "../../src/middleware/delay";
+import type { StatsResponse } from "../../src/types";
+
+const env = { ENABLE_DELAY: "true" };
+
+function post(
+  app: ReturnType<typeof createApp>,
+  query: string,
+  bindings: Record<string, string> = env,
+  signal?: AbortSignal
+) {
+  return app.fetch(
+    new Request(`http://localhost/add${query}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ a: 1, b: 2 }),
+      signal,
+    }),
+    bindings
+  );
+}
+
+describe("parseDelay", () => {
+  it.each([
+    { text: "200ms", expected: 200 },
+    { text: "0ms", expected: 0 },
+    { text: "1.5s", expected: 1500 },
+    { text: "2s", expected: 2000 },
+  ])("parses $text", ({ text, expected }) => {
+    expect(parseDelay(text)).toBe(expected);
+  });
+
+  it.each(["200", "ms", "-5ms", "1m", "1e3ms", " 5ms"])(
+    "rejects %s",
+    (text) => {
+      expect(parseDelay(text)).toBeUndefined();
+    }
+  );
+});
+
+describe("responseDelay middleware", () => {
+  it("waits before handling the request", async () => {
+    const start = Date.now();
+
+    const response = await post(createApp(), "?delay=50ms");
+
+    expect(Date.now() - start).toBeGreaterThanOrEqual(45);
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 3 });
+  });
+
+  it("ignores the parameter unless ENABLE_DELAY is true", async () => {
+    const start = Date.now();
+
+    const response = await post(createApp(), "?delay=10s", {});
+
+    expect(Date.now() - start).toBeLessThan(1000);
+    expect(response.status).toBe(200);
+  });
+
+  it.each(["abc", `${MAX_DELAY_MS + 1}ms`])(
+    "rejects delay=%s with 400",
+    async (delay) => {
+      const response = await post(createApp(), `?delay=${delay}`);
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "delay" }],
+      });
+    }
+  );
+
+  it("stops waiting when the client cancels", async () => {
+    const app = createApp();
+    const controller = new AbortController();
+    const start = Date.now();
+
+    const pending = post(app, "?delay=10s", env, controller.signal);
+    setTimeout(() => controller.abort(), 20);
+    const response = await pending;
+
+    expect(Date.now() - start).toBeLessThan(1000);
+    expect(response.status).toBe(503);
+    const json = await response.json();
+    expect(json).toMatchObject({ code: "request_cancelled" });
+    // The handler never ran.
+    const stats = await app.request("/stats");
+    expect((await stats.json<StatsResponse>()).total).toBe(0);
+  });
+});
@@ -0,0 +1,97 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import { calculatorService } from "../../src/services/operations";
+import type { CalculatorService } from "../../src/services/operations";
+
+function post(app: ReturnType<typeof createApp>, path: string, body: unknown) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        "X-Request-Id": "req-123",
+      },
+      body: JSON.stringify(body),
+    })
+  );
+}
+
+describe("envelope middleware", () => {
+  const app = createApp();
+
+  it("wraps a successful result in data and meta", async () => {
+    const response = await post(app, "/add?envelope=true", { a: 2, b: 3 });
+
+    expect(response.status).toBe(200);
+    const json = await response.json();
+    expect(json).toEqual({
+      data: { result: 5 },
+      meta: { durationMs: expect.any(Number), requestId: "req-123" },
+    });
+  });
+
+  it("wraps an error in error and meta", async () => {
+    const response = await post(app, "/divide?envelope=true", { a: 1, b: 0 });
+
+    expect(response.status).toBe(400);
+    const json = await response.json();
+    expect(json).toEqual({
+      error: {
+        error: "invalid input: division by zero",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      },
+      meta: { durationMs: expect.any(Number), requestId: "req-123" },
+    });
+  });
+
+  it("wraps routing errors such as 404", async () => {
+    const response = await app.request("/nowhere?envelope=true");
+
+    expect(response.status).toBe(404);
+    const json = await response.json();
+    expect(json).toMatchObject({ error: { code: "not_found" } });
+  });
+
+  it("measures the duration with the app clock", async () => {
+    const clock = new FakeClock();
+    const slow: CalculatorService = {
+      ...calculatorService,
+      add: (a, b) => {
+        clock.advance(25);
+        return a + b;
+      },
+    };
+    const timed = createApp({ clock, service: slow });
+
+    const response = await post(timed, "/add?envelope=true", { a: 1, b: 1 });
+
+    const json = await response.json<{ meta: { durationMs: number } }>();
+    expect(json.meta.durationMs).toBe(25);
+  });
+
+  it("generates a request id when none is sent", async () => {
+    const response = await app.request("/health?envelope=true");
+
+    const json = await response.json<{ meta: { requestId: string } }>();
+    expect(json.meta.requestId).toMatch(
+      /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/
+    );
+  });
+
+  it("leaves the flat response unchanged by default", async () => {
+    const response = await post(app, "/add", { a: 2, b: 3 });
+
+    const json = await response.json();
+    expect(json).toEqual({ result: 5 });
+  });
+
+  it("leaves non-JSON responses unchanged", async () => {
+    const response = await app.request(
+      "/add/jsonp?a=1&b=2&callback=cb&envelope=true"
+    );
+
+    expect(await response.text()).toBe('/**/cb({"result":3});');
+  });
+});
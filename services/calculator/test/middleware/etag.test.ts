@@ -0,0 +1,105 @@
+import { describe, it, expect } from "vitest";
+import app from "../../src/index";
+import { sha256Hex } from "../../src/middleware/digest";
+import { matchesEtag } from "../../src/middleware/etag";
+
+function add(body: unknown, headers: Record<string, string> = {}) {
+  return app.fetch(
+    new Request("http://localhost/add", {
+      method: "POST",
+      headers: { "Content-Type": "application/json", ...headers },
+      body: JSON.stringify(body),
+    })
+  );
+}
+
+describe("ETag middleware", () => {
+  it("tags a successful result", async () => {
+    const response = await add({ a: 2, b: 3 });
+
+    expect(response.status).toBe(200);
+    expect(response.headers.get("etag")).toMatch(/^"[0-9a-f]{64}"$/);
+  });
+
+  it("returns 304 with an empty body for a matching If-None-Match", async () => {
+    const first = await add({ a: 2, b: 3 });
+    const tag = first.headers.get("etag")!;
+
+    const second = await add({ a: 2, b: 3 }, { "If-None-Match": tag });
+
+    expect(second.status).toBe(304);
+    expect(second.headers.get("etag")).toBe(tag);
+    expect(await second.text()).toBe("");
+  });
+
+  it("gives the same request the same tag", async () => {
+    const first = await add({ a: 2, b: 3 });
+    const second = await add({ a: 2, b: 3 });
+
+    expect(second.headers.get("etag")).toBe(first.headers.get("etag"));
+  });
+
+  it("gives different operands different tags", async () => {
+    const first = await add({ a: 2, b: 3 });
+    const second = await add({ a: 3, b: 2 });
+
+    expect(second.headers.get("etag")).not.toBe(first.headers.get("etag"));
+  });
+
+  it("returns 200 when If-None-Match does not match", async () => {
+    const response = await add({ a: 2, b: 3 }, { "If-None-Match": '"stale"' });
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+  });
+
+  it("hashes the method, path, request and result", async () => {
+    const response = await add({ a: 2, b: 3 });
+
+    const expected = await sha256Hex('POST /add\n{"a":2,"b":3}\n{"result":5}');
+    expect(response.headers.get("etag")).toBe(`"${expected}"`);
+  });
+
+  it("tags a replayed response as the original", async () => {
+    const headers = { "Idempotency-Key": "etag-replay" };
+    const first = await add({ a: 2, b: 3 }, headers);
+
+    const replayed = await add({ a: 2, b: 3 }, headers);
+
+    expect(replayed.headers.get("Idempotent-Replayed")).toBe("true");
+    expect(replayed.headers.get("etag")).toBe(first.headers.get("etag"));
+  });
+
+  it("does not tag errors", async () => {
+    const response = await add({ a: 2 });
+
+    expect(response.status).toBe(400);
+    expect(response.headers.get("etag")).toBeNull();
+  });
+});
+
+describe("sha256Hex", () => {
+  it("hex-encodes the digest of the UTF-8 text", async () => {
+    expect(await sha256Hex("abc")).toBe(
+      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+    );
+    expect(await sha256Hex("é")).toBe(
+      "4a99557e4033c3539de2eb65472017cad5f9557f7a0625a09f1c3f6e2ba69c4c"
+    );
+  });
+});
+
+describe("matchesEtag", () => {
+  it.each([
+    { header: '"abc"', name: "exact tag" },
+    { header: 'W/"abc"', name: "weak tag" },
+    { header: '"x", "abc"', name: "tag in a list" },
+    { header: "*", name: "wildcard" },
+  ])("matches $name", ({ header }) => {
+    expect(matchesEtag(header, '"abc"')).toBe(true);
+  });
+
+  it("does not match a different tag", () => {
+    expect(matchesEtag('"abd"', '"abc"')).toBe(false);
+  });
+});
@@ -0,0 +1,68 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import {
+  DEFAULT_MAX_HEADER_BYTES,
+  headerBytes,
+} from "../../src/middleware/headers";
+
+function add(app: ReturnType<typeof createApp>, headers: HeadersInit) {
+  return app.request("/add", {
+    method: "POST",
+    headers: { "Content-Type": "application/json", ...headers },
+    body: JSON.stringify({ a: 2, b: 3 }),
+  });
+}
+
+describe("headerBytes", () => {
+  it("counts each header as a name: value line", () => {
+    // "x-a: 1\r\n" and "x-bb: é\r\n", é being two bytes.
+    const headers = new Headers({ "X-A": "1", "X-Bb": "é" });
+
+    expect(headerBytes(headers)).toBe(8 + 10);
+  });
+});
+
+describe("maxHeaderBytes middleware", () => {
+  it("rejects headers over the configured maximum with 431", async () => {
+    const app = createApp({ maxHeaderBytes: 1024 });
+
+    const response = await add(app, { "X-Padding": "x".repeat(1024) });
+
+    expect(response.status).toBe(431);
+    const json = await response.json();
+    expect(json).toMatchObject({
+      code: "headers_too_large",
+      error: "Request headers exceed 1024 bytes",
+    });
+  });
+
+  it("accepts headers within the maximum", async () => {
+    const app = createApp({ maxHeaderBytes: 1024 });
+
+    const response = await add(app, { "X-Padding": "x".repeat(512) });
+
+    expect(response.status).toBe(200);
+  });
+
+  it("applies the default maximum", async () => {
+    const app = createApp();
+
+    const within = await add(app, { "X-Padding": "x".repeat(1024) });
+    const over = await add(app, {
+      "X-Padding": "x".repeat(DEFAULT_MAX_HEADER_BYTES),
+    });
+
+    expect(within.status).toBe(200);
+    expect(over.status).toBe(431);
+  });
+
+  it("rejects before the request is counted", async () => {
+    const app = createApp({ maxHeaderBytes: 1024 });
+
+    await add(app, { "X-Padding": "x".repeat(2048) });
+
+    const response = await app.request("/stats");
+    const json = await response.json();
+    expect(json).toMatchObject({ operations: {} });
+  });
+});
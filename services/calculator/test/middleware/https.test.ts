@@ -0,0 +1,77 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { DEFAULT_HSTS } from "../../src/middleware/https";
+
+function add(target: ReturnType<typeof createApp>, proto?: string) {
+  return target.request("/add?x=1", {
+    method: "POST",
+    headers: proto === undefined ? {} : { "X-Forwarded-Proto": proto },
+    body: JSON.stringify({ a: 2, b: 3 }),
+  });
+}
+
+describe("requireHttps middleware", () => {
+  const app = createApp({ requireHttps: {} });
+
+  it("serves an https request with Strict-Transport-Security", async () => {
+    const response = await add(app, "https");
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+    expect(response.headers.get("Strict-Transport-Security")).toBe(
+      DEFAULT_HSTS
+    );
+  });
+
+  it("reads the first of several forwarded schemes", async () => {
+    const response = await add(app, "HTTPS, http");
+
+    expect(response.status).toBe(200);
+  });
+
+  it.each([["http"], [undefined]])(
+    "rejects X-Forwarded-Proto %s with 400",
+    async (proto) => {
+      const response = await add(app, proto);
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        code: "insecure_request",
+        error: "HTTPS required",
+      });
+      expect(response.headers.get("Strict-Transport-Security")).toBeNull();
+    }
+  );
+
+  it("redirects an http request to https when configured", async () => {
+    const redirecting = createApp({ requireHttps: { insecure: "redirect" } });
+
+    const response = await add(redirecting, "http");
+
+    expect(response.status).toBe(308);
+    expect(response.headers.get("Location")).toBe("https://localhost/add?x=1");
+  });
+
+  it("serves health checks over http", async () => {
+    const response = await app.request("/health");
+
+    expect(response.status).toBe(200);
+  });
+
+  it("honours a custom Strict-Transport-Security value", async () => {
+    const custom = createApp({ requireHttps: { hsts: "max-age=60" } });
+
+    const response = await add(custom, "https");
+
+    expect(response.headers.get("Strict-Transport-Security")).toBe(
+      "max-age=60"
+    );
+  });
+
+  it("is off by default", async () => {
+    const response = await add(createApp(), "http");
+
+    expect(response.status).toBe(200);
+    expect(response.headers.get("Strict-Transport-Security")).toBeNull();
+  });
+});
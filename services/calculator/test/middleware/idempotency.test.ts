@@ -0,0 +1,213 @@
+import { describe, it, expect } from "vitest";
+import { Hono } from "hono";
+import app from "../../src/index";
+import { withVariables } from "../../src/middleware/variables";
+import {
+  idempotency,
+  IdempotencyStore,
+} from "../../src/middleware/idempotency";
+import { FakeClock } from "../../src/services/clock";
+import type { IdempotencyOptions } from "../../src/middleware/idempotency";
+import type { AppEnv } from "../../src/types";
+
+function post(path: string, body: unknown, key?: string) {
+  const headers: Record<string, string> = {
+    "Content-Type": "application/json",
+  };
+  if (key !== undefined) {
+    headers["Idempotency-Key"] = key;
+  }
+  return new Request(`http://localhost${path}`, {
+    method: "POST",
+    headers,
+    body: JSON.stringify(body),
+  });
+}
+
+function countingApp(
+  options: IdempotencyOptions = {},
+  clock: FakeClock = new FakeClock()
+) {
+  let calls = 0;
+  const counting = new Hono<AppEnv>();
+  counting.use("*", withVariables({ clock }));
+  counting.use("*", idempotency(options));
+  counting.post("/count", (c) => {
+    calls++;
+    return c.json({ calls });
+  });
+  counting.post("/fail", (c) => {
+    calls++;
+    return c.json({ error: "boom" }, 503);
+  });
+  return { app: counting, calls: () => calls };
+}
+
+describe("idempotency middleware", () => {
+  it("replays the cached response for the same key and body", async () => {
+    const { app: counting, calls } = countingApp();
+
+    const first = await counting.fetch(post("/count", { a: 1 }, "key-1"));
+    const second = await counting.fetch(post("/count", { a: 1 }, "key-1"));
+
+    expect(first.status).toBe(200);
+    expect(second.status).toBe(200);
+    expect(await first.json()).toEqual({ calls: 1 });
+    expect(await second.json()).toEqual({ calls: 1 });
+    expect(second.headers.get("Idempotent-Replayed")).toBe("true");
+    expect(calls()).toBe(1);
+  });
+
+  it("returns 409 when the key is reused with a different body", async () => {
+    const { app: counting, calls } = countingApp();
+
+    await counting.fetch(post("/count", { a: 1 }, "key-2"));
+    const conflict = await counting.fetch(post("/count", { a: 2 }, "key-2"));
+
+    expect(conflict.status).toBe(409);
+    const json = await conflict.json();
+    expect(json).toEqual({
+      error: "Idempotency-Key reused with a different request",
+      code: "idempotency_conflict",
+      timestamp: expect.any(String),
+    });
+    expect(calls()).toBe(1);
+  });
+
+  it("returns 409 when the key is reused on a different path", async () => {
+    const { app: counting } = countingApp();
+
+    await counting.fetch(post("/count", { a: 1 }, "key-3"));
+    const conflict = await counting.fetch(post("/fail", { a: 1 }, "key-3"));
+
+    expect(conflict.status).toBe(409);
+  });
+
+  it("returns 409 when the key is reused with a different query", async () => {
+    const { app: counting, calls } = countingApp();
+
+    await counting.fetch(post("/count", { a: 1 }, "key-query"));
+    const conflict = await counting.fetch(
+      post("/count?envelope=true", { a: 1 }, "key-query")
+    );
+
+    expect(conflict.status).toBe(409);
+    expect(calls()).toBe(1);
+  });
+
+  it("executes concurrent retries only once", async () => {
+    const { app: counting, calls } = countingApp();
+
+    const responses = await Promise.all([
+      counting.fetch(post("/count", { a: 1 }, "key-4")),
+      counting.fetch(post("/count", { a: 1 }, "key-4")),
+      counting.fetch(post("/count", { a: 1 }, "key-4")),
+    ]);
+
+    for (const response of responses) {
+      expect(await response.json()).toEqual({ calls: 1 });
+    }
+    expect(calls()).toBe(1);
+  });
+
+  it("executes the handler again once the key has expired", async () => {
+    const clock = new FakeClock();
+    const { app: counting, calls } = countingApp({ ttlMs: 1000 }, clock);
+
+    await counting.fetch(post("/count", { a: 1 }, "key-ttl"));
+    clock.advance(999);
+    const replayed = await counting.fetch(post("/count", { a: 1 }, "key-ttl"));
+    clock.advance(1);
+    const fresh = await counting.fetch(post("/count", { a: 1 }, "key-ttl"));
+
+    expect(await replayed.json()).toEqual({ calls: 1 });
+    expect(await fresh.json()).toEqual({ calls: 2 });
+    expect(calls()).toBe(2);
+  });
+
+  it("sweeps expired entries for other keys", async () => {
+    const clock = new FakeClock();
+    const store = new IdempotencyStore();
+    const { app: counting } = countingApp({ ttlMs: 1000, store }, clock);
+
+    await counting.fetch(post("/count", { a: 1 }, "key-old-1"));
+    await counting.fetch(post("/count", { a: 1 }, "key-old-2"));
+    clock.advance(500);
+    await counting.fetch(post("/count", { a: 1 }, "key-live"));
+    clock.advance(500);
+    await counting.fetch(post("/count", { a: 1 }, "key-new"));
+
+    expect(store.size).toBe(2);
+  });
+
+  it("evicts the oldest entry beyond maxEntries", async () => {
+    const { app: counting, calls } = countingApp({ maxEntries: 2 });
+
+    await counting.fetch(post("/count", { a: 1 }, "key-1"));
+    await counting.fetch(post("/count", { a: 1 }, "key-2"));
+    await counting.fetch(post("/count", { a: 1 }, "key-3"));
+    const replayed = await counting.fetch(post("/count", { a: 1 }, "key-3"));
+    const evicted = await counting.fetch(post("/count", { a: 1 }, "key-1"));
+
+    expect(await replayed.json()).toEqual({ calls: 3 });
+    expect(await evicted.json()).toEqual({ calls: 4 });
+    expect(calls()).toBe(4);
+  });
+
+  it.each([0, -1, 1.5])("rejects a maxEntries of %s", (maxEntries) => {
+    expect(() => new IdempotencyStore(maxEntries)).toThrow(RangeError);
+  });
+
+  it("does not remember server errors", async () => {
+    const store = new IdempotencyStore();
+    const { app: counting, calls } = countingApp({ store });
+
+    await counting.fetch(post("/fail", {}, "key-5"));
+    await counting.fetch(post("/fail", {}, "key-5"));
+
+    expect(calls()).toBe(2);
+    expect(store.size).toBe(0);
+  });
+
+  it("passes requests without a key through", async () => {
+    const { app: counting, calls } = countingApp();
+
+    await counting.fetch(post("/count", { a: 1 }));
+    await counting.fetch(post("/count", { a: 1 }));
+
+    expect(calls()).toBe(2);
+  });
+
+  it("returns 400 for an oversized key", async () => {
+    const { app: counting } = countingApp();
+
+    const response = await counting.fetch(
+      post("/count", { a: 1 }, "k".repeat(256))
+    );
+
+    expect(response.status).toBe(400);
+  });
+
+  it("replays calculator results", async () => {
+    const first = await app.fetch(post("/add", { a: 2, b: 3 }, "add-key"));
+    const second = await app.fetch(post("/add", { a: 2, b: 3 }, "add-key"));
+
+    expect(await first.json()).toEqual({ result: 5 });
+    expect(await second.json()).toEqual({ result: 5 });
+    expect(second.headers.get("Idempotent-Replayed")).toBe("true");
+    expect(second.headers.get("content-type")).toContain("application/json");
+  });
+
+  it("forgets every key once cleared", async () => {
+    const store = new IdempotencyStore();
+    const { app: counting } = countingApp({ store });
+    await counting.fetch(post("/count", {}, "key-1"));
+
+    store.clear();
+    const response = await counting.fetch(post("/count", {}, "key-1"));
+
+    expect(store.size).toBe(1);
+    expect(response.headers.get("Idempotent-Replayed")).toBeNull();
+    expect(await response.json()).toEqual({ calls: 2 });
+  });
+});
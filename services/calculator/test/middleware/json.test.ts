@@ -0,0 +1,96 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { parseMediaType } from "../../src/middleware/json";
+
+function add(app: ReturnType<typeof createApp>, contentType?: string) {
+  const headers: Record<string, string> = {};
+  if (contentType !== undefined) {
+    headers["Content-Type"] = contentType;
+  }
+  return app.fetch(
+    new Request("http://localhost/add", {
+      method: "POST",
+      headers,
+      body: JSON.stringify({ a: 2, b: 3 }),
+    })
+  );
+}
+
+describe("strict content type", () => {
+  const strict = createApp({ strictContentType: true });
+
+  it.each([
+    "application/json",
+    "application/json; charset=utf-8",
+    "application/json;charset=UTF-8",
+    'application/json; charset="utf-8"',
+    "Application/JSON",
+  ])("accepts %s", async (contentType) => {
+    const response = await add(strict, contentType);
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+  });
+
+  it.each(["text/plain", "text/plain; charset=utf-8", "application/jsonx"])(
+    "rejects %s",
+    async (contentType) => {
+      const response = await add(strict, contentType);
+
+      expect(response.status).toBe(415);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "Content-Type must be application/json",
+        code: "unsupported_media_type",
+      });
+    }
+  );
+
+  it("rejects a missing Content-Type", async () => {
+    const response = await add(strict);
+
+    expect(response.status).toBe(415);
+  });
+
+  it("does not check GET requests", async () => {
+    const response = await strict.fetch(new Request("http://localhost/health"));
+
+    expect(response.status).toBe(200);
+  });
+
+  it("is off by default", async () => {
+    const response = await add(createApp(), "text/plain");
+
+    expect(response.status).toBe(200);
+  });
+});
+
+describe("parseMediaType", () => {
+  it("lower-cases the type and parameter names", () => {
+    expect(parseMediaType("Application/JSON; Charset=UTF-8")).toEqual({
+      type: "application/json",
+      parameters: { charset: "UTF-8" },
+    });
+  });
+
+  it("unquotes parameter values", () => {
+    expect(parseMediaType('text/plain; charset="utf-8"')).toEqual({
+      type: "text/plain",
+      parameters: { charset: "utf-8" },
+    });
+  });
+
+  it("ignores empty parameters", () => {
+    expect(parseMediaType("application/json;")).toEqual({
+      type: "application/json",
+      parameters: {},
+    });
+  });
+
+  it.each(["", "application", "application/json/x", "a b/c", "text/plain; x"])(
+    "rejects %j",
+    (value) => {
+      expect(parseMediaType(value)).toBeUndefined();
+    }
+  );
+});
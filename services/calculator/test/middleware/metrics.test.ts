@@ -0,0 +1,186 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import { FakeCalculator } from "../../src/services/fake";
+import { calculatorService } from "../../src/services/operations";
+
+function add(a: number, b: number) {
+  return new Request("http://localhost/add", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ a, b }),
+  });
+}
+
+async function scrape(app: ReturnType<typeof createApp>) {
+  const response = await app.fetch(new Request("http://localhost/metrics"));
+  return response.text();
+}
+
+describe("request latency middleware", () => {
+  it("records handler time in the matching bucket", async () => {
+    const clock = new FakeClock();
+    const app = createApp({
+      clock,
+      latencyBuckets: [0.05, 0.1, 0.25],
+      service: {
+        ...calculatorService,
+        add: (a, b) => {
+          clock.advance(120);
+          return a + b;
+        },
+      },
+    });
+
+    const response = await app.fetch(add(2, 3));
+    expect(await response.json()).toEqual({ result: 5 });
+
+    const text = await scrape(app);
+    expect(text).toContain(
+      'calculator_request_duration_seconds_bucket{le="0.05"} 0'
+    );
+    expect(text).toContain(
+      'calculator_request_duration_seconds_bucket{le="0.1"} 0'
+    );
+    expect(text).toContain(
+      'calculator_request_duration_seconds_bucket{le="0.25"} 1'
+    );
+    expect(text).toContain("calculator_request_duration_seconds_sum 0.12");
+    expect(text).toContain("calculator_request_duration_seconds_count 1");
+  });
+
+  it("records error responses", async () => {
+    const app = createApp({ clock: new FakeClock() });
+
+    await app.fetch(new Request("http://localhost/add"));
+
+    expect(await scrape(app)).toContain(
+      "calculator_request_duration_seconds_count 1"
+    );
+  });
+
+  it("keeps separate histograms per app", async () => {
+    const first = createApp({ clock: new FakeClock() });
+    const second = createApp({ clock: new FakeClock() });
+
+    await first.fetch(add(1, 1));
+
+    expect(await scrape(second)).toContain(
+      "calculator_request_duration_seconds_count 0"
+    );
+  });
+});
+
+describe("request cancellation middleware", () => {
+  // A service whose add never finishes on its own, so the request is still
+  // in flight when the client goes away.
+  function blockedApp() {
+    const never = () => new Promise<number>(() => {});
+    const fake = new FakeCalculator().returns("add", never);
+    return createApp({ service: fake });
+  }
+
+  it("counts a client that disconnects mid-request", async () => {
+    const app = blockedApp();
+    const controller = new AbortController();
+    const request = new Request(add(1, 2), { signal: controller.signal });
+
+    const pending = app.fetch(request);
+    setTimeout(() => controller.abort(), 10);
+    const response = await pending;
+
+    expect(response.status).toBe(503);
+    const json = await response.json();
+    expect(json).toMatchObject({ code: "request_cancelled" });
+    expect(await scrape(app)).toContain(
+      "calculator_requests_cancelled_total 1"
+    );
+  });
+
+  it("does not count completed requests", async () => {
+    const app = createApp();
+
+    await app.fetch(add(1, 2));
+
+    expect(await scrape(app)).toContain(
+      "calculator_requests_cancelled_total 0"
+    );
+  });
+});
+
+describe("GET /metrics", () => {
+  it("serves the Prometheus text format", async () => {
+    const app = createApp();
+
+    const response = await app.fetch(new Request("http://localhost/metrics"));
+
+    expect(response.status).toBe(200);
+    expect(response.headers.get("content-type")).toContain("text/plain");
+    expect(await response.text()).toContain(
+      "# TYPE calculator_request_duration_seconds histogram"
+    );
+  });
+
+  it("counts operations by status", async () => {
+    const app = createApp();
+
+    await app.fetch(add(2, 3));
+    // NaN serializes as null, which /add rejects.
+    await app.fetch(add(2, NaN));
+
+    const text = await scrape(app);
+    expect(text).toContain(
+      'calculator_operations_total{operation="add",status="success"} 1'
+    );
+    expect(text).toContain(
+      'calculator_operations_total{operation="add",status="error"} 1'
+    );
+  });
+
+  it("counts result cache hits and misses", async () => {
+    const clock = new FakeClock();
+    const app = createApp({ clock, cache: { ttlMs: 1000 } });
+
+    await app.fetch(add(2, 3));
+    await app.fetch(add(2, 3));
+    clock.advance(1000);
+    await app.fetch(add(2, 3));
+
+    const text = await scrape(app);
+    expect(text).toContain("calculator_cache_hits_total 1");
+    expect(text).toContain("calculator_cache_misses_total 2");
+  });
+
+  it("counts unknown batch operations under one label", async () => {
+    const app = createApp();
+
+    await app.fetch(
+      new Request("http://localhost/batch", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({
+          operations: [
+            { operation: "sqrt", a: 4 },
+            { operation: "cbrt", a: 8 },
+          ],
+        }),
+      })
+    );
+
+    const text = await scrape(app);
+    expect(text).toContain(
+      'calculator_operations_total{operation="unknown",status="error"} 2'
+    );
+    expect(text).not.toContain('operation="sqrt"');
+  });
+
+  it("returns 405 for POST method", async () => {
+    const app = createApp();
+
+    const response = await app.fetch(
+      new Request("http://localhost/metrics", { method: "POST" })
+    );
+
+    expect(response.status).toBe(405);
+  });
+});
@@ -0,0 +1,146 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { signBody } from "../../src/middleware/signature";
+import {
+  decodeErrorResponse,
+  decodeOperationResponse,
+  encodeOperationRequest,
+} from "../../src/services/protobuf";
+
+const app = createApp();
+
+function post(path: string, body: Uint8Array) {
+  return app.request(path, {
+    method: "POST",
+    headers: { "Content-Type": "application/x-protobuf" },
+    body,
+  });
+}
+
+async function bytesOf(response: Response) {
+  return new Uint8Array(await response.arrayBuffer());
+}
+
+describe("protobuf middleware", () => {
+  it("computes /add from a protobuf body", async () => {
+    const response = await post("/add", encodeOperationRequest({ a: 2, b: 3 }));
+
+    expect(response.status).toBe(200);
+    expect(response.headers.get("Content-Type")).toBe(
+      "application/x-protobuf"
+    );
+    expect(decodeOperationResponse(await bytesOf(response))).toEqual({
+      result: 5,
+    });
+  });
+
+  it("encodes the exact result", async () => {
+    const response = await post(
+      "/multiply?exact=true",
+      encodeOperationRequest({ a: 3, b: 4 })
+    );
+
+    expect(decodeOperationResponse(await bytesOf(response))).toEqual({
+      result: 12,
+      exact: "12",
+    });
+  });
+
+  it("encodes errors as ErrorResponse", async () => {
+    const response = await post(
+      "/divide",
+      encodeOperationRequest({ a: 1, b: 0 })
+    );
+
+    expect(response.status).toBe(400);
+    const error = decodeErrorResponse(await bytesOf(response));
+    expect(error.code).toBe("invalid_input");
+    expect(error.error).toBe("invalid input: division by zero");
+  });
+
+  it("rejects a malformed body with 400", async () => {
+    const response = await post("/add", Uint8Array.from([0x09, 0, 0]));
+
+    expect(response.status).toBe(400);
+    const error = decodeErrorResponse(await bytesOf(response));
+    expect(error.code).toBe("malformed_protobuf");
+  });
+
+  it("leaves replies without a protobuf message as JSON", async () => {
+    const response = await post(
+      "/divmod",
+      encodeOperationRequest({ a: 7, b: 2 })
+    );
+
+    expect(response.headers.get("Content-Type")).toContain(
+      "application/json"
+    );
+    const json = await response.json();
+    expect(json).toEqual({ quotient: 3, remainder: 1 });
+  });
+
+  it("keeps JSON the default", async () => {
+    const response = await app.request("/add", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ a: 2, b: 3 }),
+    });
+
+    const json = await response.json();
+    expect(json).toEqual({ result: 5 });
+  });
+
+  it("is accepted with strict content type checking", async () => {
+    const strict = createApp({ strictContentType: true });
+
+    const response = await strict.request("/add", {
+      method: "POST",
+      headers: { "Content-Type": "application/x-protobuf" },
+      body: encodeOperationRequest({ a: 2, b: 3 }),
+    });
+
+    expect(response.status).toBe(200);
+  });
+
+  it("encodes a result renamed by responseKeys", async () => {
+    const renamed = createApp({ responseKeys: { result: "value" } });
+
+    const response = await renamed.request("/add", {
+      method: "POST",
+      headers: { "Content-Type": "application/x-protobuf" },
+      body: encodeOperationRequest({ a: 2, b: 3 }),
+    });
+
+    expect(response.headers.get("Content-Type")).toBe(
+      "application/x-protobuf"
+    );
+    expect(decodeOperationResponse(await bytesOf(response))).toEqual({
+      result: 5,
+    });
+  });
+
+  it("computes a signed request from the bytes as sent", async () => {
+    const secret = "test-secret";
+    // 0.1 and 0.2 as doubles are not valid UTF-8, so a signature checked
+    // against the body decoded as text would not match.
+    const body = encodeOperationRequest({ a: 0.1, b: 0.2 });
+
+    const response = await app.request(
+      "/add",
+      {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/x-protobuf",
+          "X-Signature": await signBody(secret, body),
+        },
+        body,
+      },
+      { SIGNING_SECRET: secret }
+    );
+
+    expect(response.status).toBe(200);
+    expect(decodeOperationResponse(await bytesOf(response))).toEqual({
+      result: 0.1 + 0.2,
+    });
+  });
+});
@@ -0,0 +1,114 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import { replay } from "../../src/services/recording";
+import type { RecordingFile } from "../../src/services/recording";
+
+const env = { RECORD_REQUESTS: "true" };
+const epoch = new Date("2024-01-01T00:00:00.000Z");
+
+function post(
+  app: ReturnType<typeof createApp>,
+  path: string,
+  body: unknown,
+  bindings: Record<string, string> = env
+) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+    }),
+    bindings
+  );
+}
+
+function download(
+  app: ReturnType<typeof createApp>,
+  bindings: Record<string, string> = env
+) {
+  return app.fetch(new Request("http://localhost/recording"), bindings);
+}
+
+describe("recordExchanges middleware", () => {
+  it("records requests and responses in order", async () => {
+    const app = createApp({ clock: new FakeClock(epoch) });
+    await post(app, "/add", { a: 2, b: 3 });
+    await post(app, "/divide", { a: 1, b: 0 });
+
+    const response = await download(app);
+
+    expect(response.status).toBe(200);
+    const json = await response.json<RecordingFile>();
+    expect(json.exchanges).toMatchObject([
+      {
+        request: {
+          method: "POST",
+          path: "/add",
+          headers: { "content-type": "application/json" },
+          body: '{"a":2,"b":3}',
+        },
+        response: { status: 200, body: '{"result":5}' },
+      },
+      {
+        request: { method: "POST", path: "/divide" },
+        response: {
+          status: 400,
+          headers: { "content-type": "application/json" },
+          body: JSON.stringify({
+            error: "invalid input: division by zero",
+            code: "invalid_input",
+            timestamp: "2024-01-01T00:00:00.000Z",
+          }),
+        },
+      },
+    ]);
+  });
+
+  it("replays a recording against a fresh app", async () => {
+    const recorded = createApp({ clock: new FakeClock(epoch) });
+    await post(recorded, "/add", { a: 2, b: 3 });
+    await post(recorded, "/multiply?exact=true", { a: 2 ** 53, b: 3 });
+    await post(recorded, "/divide", { a: 1, b: 0 });
+    // Saved and loaded as a file would be.
+    const text = await (await download(recorded)).text();
+    const file: RecordingFile = JSON.parse(text);
+
+    const fresh = createApp({ clock: new FakeClock(epoch) });
+    const mismatches = await replay(file, (request) => fresh.fetch(request));
+
+    expect(file.exchanges).toHaveLength(3);
+    expect(mismatches).toEqual([]);
+  });
+
+  it("reports responses that changed", async () => {
+    const recorded = createApp({ clock: new FakeClock(epoch) });
+    await post(recorded, "/add", { a: 2, b: 3 });
+    await post(recorded, "/subtract", { a: 2, b: 3 });
+    const file = await (await download(recorded)).json<RecordingFile>();
+
+    const changed = createApp({
+      clock: new FakeClock(epoch),
+      resultTransform: (operation, result) =>
+        operation === "subtract" ? -result : result,
+    });
+    const mismatches = await replay(file, (request) => changed.fetch(request));
+
+    expect(mismatches).toHaveLength(1);
+    expect(mismatches[0]).toMatchObject({
+      index: 1,
+      request: "POST /subtract",
+      expected: { body: '{"result":-1}' },
+      actual: { body: '{"result":1}' },
+    });
+  });
+
+  it("records nothing and hides the recording unless enabled", async () => {
+    const app = createApp();
+    await post(app, "/add", { a: 2, b: 3 }, {});
+
+    expect((await download(app, {})).status).toBe(404);
+    const json = await (await download(app)).json<RecordingFile>();
+    expect(json.exchanges).toEqual([]);
+  });
+});
@@ -0,0 +1,62 @@
+import { describe, it, expect } from "vitest";
+import app from "../../src/index";
+import { isValidRequestId } from "../../src/middleware/request-id";
+
+const UUID = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/;
+
+function get(path: string, requestId?: string) {
+  const headers: Record<string, string> = {};
+  if (requestId !== undefined) {
+    headers["X-Request-Id"] = requestId;
+  }
+  return app.fetch(new Request(`http://localhost${path}`, { headers }));
+}
+
+describe("requestId middleware", () => {
+  it("reuses a valid incoming X-Request-Id", async () => {
+    const response = await get("/health", "gateway-7f3a:42");
+
+    expect(response.headers.get("X-Request-Id")).toBe("gateway-7f3a:42");
+  });
+
+  it.each([
+    { name: "too long", id: "a".repeat(129) },
+    { name: "with spaces", id: "two words" },
+    { name: "with quotes", id: 'say"hi"' },
+    { name: "empty", id: "" },
+  ])("replaces an incoming id that is $name", async ({ id }) => {
+    const response = await get("/health", id);
+
+    expect(response.headers.get("X-Request-Id")).toMatch(UUID);
+  });
+
+  it("generates an id when none is sent", async () => {
+    const first = await get("/health");
+    const second = await get("/health");
+
+    const id = first.headers.get("X-Request-Id");
+    expect(id).toMatch(UUID);
+    expect(second.headers.get("X-Request-Id")).not.toBe(id);
+  });
+
+  it("echoes the id on error responses", async () => {
+    const response = await get("/nowhere", "req-404");
+
+    expect(response.status).toBe(404);
+    expect(response.headers.get("X-Request-Id")).toBe("req-404");
+  });
+
+  it("puts the same id in the response envelope", async () => {
+    const response = await get("/health?envelope=true");
+
+    const json = await response.json<{ meta: { requestId: string } }>();
+    expect(json.meta.requestId).toBe(response.headers.get("X-Request-Id"));
+  });
+});
+
+describe("isValidRequestId", () => {
+  it("accepts ids up to 128 characters", () => {
+    expect(isValidRequestId("a".repeat(128))).toBe(true);
+    expect(isValidRequestId("a".repeat(129))).toBe(false);
+  });
+});
@@ -0,0 +1,152 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { signBody } from "../../src/middleware/signature";
+
+function post(
+  target: ReturnType<typeof createApp>,
+  path: string,
+  body?: string,
+  contentType = "application/json"
+) {
+  return target.request(path, {
+    method: "POST",
+    headers: { "Content-Type": contentType },
+    body,
+  });
+}
+
+describe("requireBody middleware", () => {
+  const app = createApp({ strictBody: true });
+
+  it.each([
+    { name: "no body", body: undefined },
+    { name: "a blank body", body: " \r\n\t" },
+    { name: "null", body: "null" },
+    { name: "an empty object", body: "{}" },
+  ])("rejects $name with 400", async ({ body }) => {
+    const response = await post(app, "/add", body);
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toEqual({
+      error: "request body required",
+      code: "body_required",
+      timestamp: expect.any(String),
+    });
+  });
+
+  it("passes a complete body", async () => {
+    const response = await post(app, "/add", '{"a": 2, "b": 3}');
+
+    expect(response.status).toBe(200);
+    expect(await response.json()).toEqual({ result: 5 });
+  });
+
+  it("leaves malformed JSON to the handler", async () => {
+    const response = await post(app, "/add", "{not json");
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toMatchObject({ code: "malformed_json" });
+  });
+
+  it("leaves missing operands to the handler", async () => {
+    const response = await post(app, "/add", '{"a": 2}');
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toMatchObject({
+      code: "invalid_request",
+      errors: [{ field: "b", message: "required" }],
+    });
+  });
+
+  it.each(["/add/many", "/stats/summary", "/polynomial", "/sin", "/batch"])(
+    "applies to %s",
+    async (path) => {
+      const response = await post(app, path);
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "body_required" });
+    }
+  );
+
+  it("leaves CSV bodies alone", async () => {
+    const response = await post(app, "/add/csv", "", "text/csv");
+
+    expect(response.status).toBe(200);
+    expect(await response.text()).toBe("a,b,result,error\r\n");
+  });
+
+  it("leaves GET requests alone", async () => {
+    const response = await app.request("/add?a=2&b=3");
+
+    expect(response.status).toBe(200);
+  });
+
+  describe("with signed requests", () => {
+    const secret = "test-secret";
+
+    async function signed(body: string) {
+      return app.request(
+        "/add",
+        {
+          method: "POST",
+          headers: {
+            "Content-Type": "application/json",
+            "X-Signature": await signBody(secret, body),
+          },
+          body,
+        },
+        { SIGNING_SECRET: secret }
+      );
+    }
+
+    it("passes a complete body", async () => {
+      const response = await signed('{"a": 2, "b": 3}');
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({ result: 5 });
+    });
+
+    it("rejects an empty object with 400", async () => {
+      const response = await signed("{}");
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "body_required" });
+    });
+  });
+
+  it("applies under the prefix", async () => {
+    const prefixed = createApp({ prefix: "/api/v1", strictBody: true });
+
+    const response = await post(prefixed, "/api/v1/add");
+
+    expect(response.status).toBe(400);
+    expect(await response.json()).toMatchObject({ code: "body_required" });
+  });
+
+  describe("when off", () => {
+    const lenient = createApp();
+
+    it("reports a missing body as empty_body", async () => {
+      const response = await post(lenient, "/add");
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        error: "Request body is empty",
+        code: "empty_body",
+      });
+    });
+
+    it("reports each operand of an empty object as missing", async () => {
+      const response = await post(lenient, "/add", "{}");
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "a", message: "required" },
+          { field: "b", message: "required" },
+        ],
+      });
+    });
+  });
+});
@@ -0,0 +1,85 @@
+import { describe, it, expect } from "vitest";
+import { Hono } from "hono";
+import app, { createApp } from "../../src/index";
+import { securityHeaders } from "../../src/middleware/security";
+import type { AppEnv } from "../../src/types";
+
+function add(target: typeof app) {
+  return target.fetch(
+    new Request("http://localhost/add", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ a: 2, b: 3 }),
+    })
+  );
+}
+
+describe("securityHeaders middleware", () => {
+  it("adds the default headers to an /add response", async () => {
+    const response = await add(app);
+
+    expect(response.status).toBe(200);
+    expect(response.headers.get("X-Content-Type-Options")).toBe("nosniff");
+    expect(response.headers.get("X-Frame-Options")).toBe("DENY");
+    expect(response.headers.get("Cache-Control")).toBeNull();
+  });
+
+  it("keeps the JSON Content-Type", async () => {
+    const response = await add(app);
+
+    expect(response.headers.get("Content-Type")).toContain("application/json");
+  });
+
+  it("adds Cache-Control: no-store to error responses", async () => {
+    const response = await app.request("/add", { method: "PUT" });
+
+    expect(response.status).toBe(405);
+    expect(response.headers.get("Cache-Control")).toBe("no-store");
+    expect(response.headers.get("X-Frame-Options")).toBe("DENY");
+  });
+
+  it("adds the headers to 404 responses", async () => {
+    const response = await app.request("/nowhere");
+
+    expect(response.status).toBe(404);
+    expect(response.headers.get("X-Content-Type-Options")).toBe("nosniff");
+    expect(response.headers.get("Cache-Control")).toBe("no-store");
+  });
+
+  it("sets only the configured headers", async () => {
+    const configured = createApp({
+      securityHeaders: {
+        headers: { "Referrer-Policy": "no-referrer" },
+        errorHeaders: {},
+      },
+    });
+
+    const ok = await add(configured);
+    const error = await configured.request("/add", { method: "PUT" });
+
+    expect(ok.headers.get("Referrer-Policy")).toBe("no-referrer");
+    expect(ok.headers.get("X-Frame-Options")).toBeNull();
+    expect(error.headers.get("Cache-Control")).toBeNull();
+  });
+
+  it("does not overwrite a header the handler set", async () => {
+    const custom = new Hono<AppEnv>();
+    custom.use(
+      "*",
+      securityHeaders({
+        headers: {
+          "Content-Type": "text/plain",
+          "Cache-Control": "no-store",
+        },
+      })
+    );
+    custom.get("/cached", (c) =>
+      c.json({ ok: true }, 200, { "Cache-Control": "max-age=60" })
+    );
+
+    const response = await custom.request("/cached");
+
+    expect(response.headers.get("Content-Type")).toContain("application/json");
+    expect(response.headers.get("Cache-Control")).toBe("max-age=60");
+  });
+});
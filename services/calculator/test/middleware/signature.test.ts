@@ -0,0 +1,109 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { signBody } from "../../src/middleware/signature";
+
+const SECRET = "test-secret";
+const env = { SIGNING_SECRET: SECRET };
+const app = createApp();
+
+function post(body: string, signature?: string) {
+  const headers: Record<string, string> = {
+    "Content-Type": "application/json",
+  };
+  if (signature !== undefined) {
+    headers["X-Signature"] = signature;
+  }
+  return new Request("http://localhost/add", {
+    method: "POST",
+    headers,
+    body,
+  });
+}
+
+describe("verifySignature middleware", () => {
+  const body = JSON.stringify({ a: 2, b: 3 });
+
+  it("passes a correctly signed request to the handler", async () => {
+    const signature = await signBody(SECRET, body);
+
+    const response = await app.fetch(post(body, signature), env);
+
+    expect(response.status).toBe(200);
+    const json = await response.json();
+    expect(json).toEqual({ result: 5 });
+  });
+
+  it("accepts an upper-case hex signature", async () => {
+    const signature = (await signBody(SECRET, body)).toUpperCase();
+
+    const response = await app.fetch(post(body, signature), env);
+
+    expect(response.status).toBe(200);
+  });
+
+  it("returns 401 for a signature made with another secret", async () => {
+    const signature = await signBody("other-secret", body);
+
+    const response = await app.fetch(post(body, signature), env);
+
+    expect(response.status).toBe(401);
+    const json = await response.json();
+    expect(json).toEqual({
+      error: "Invalid signature",
+      code: "invalid_signature",
+      timestamp: expect.any(String),
+    });
+  });
+
+  it("returns 401 when the body was changed after signing", async () => {
+    const signature = await signBody(SECRET, body);
+    const tampered = JSON.stringify({ a: 2, b: 4 });
+
+    const response = await app.fetch(post(tampered, signature), env);
+
+    expect(response.status).toBe(401);
+  });
+
+  it("returns 401 for a signature that is not hex", async () => {
+    const response = await app.fetch(post(body, "not-a-signature"), env);
+
+    expect(response.status).toBe(401);
+  });
+
+  it("returns 401 when the header is missing", async () => {
+    const response = await app.fetch(post(body), env);
+
+    expect(response.status).toBe(401);
+    const json = await response.json();
+    expect(json).toEqual({
+      error: "Missing X-Signature header",
+      code: "invalid_signature",
+      timestamp: expect.any(String),
+    });
+  });
+
+  it("does not check requests when no secret is bound", async () => {
+    const response = await app.fetch(post(body));
+
+    expect(response.status).toBe(200);
+  });
+
+  it("does not check GET requests", async () => {
+    const response = await app.fetch(
+      new Request("http://localhost/health"),
+      env
+    );
+
+    expect(response.status).toBe(200);
+  });
+});
+
+describe("signBody", () => {
+  it("matches the RFC 4231 HMAC-SHA256 test vector", async () => {
+    const signature = await signBody("Jefe", "what do ya want for nothing?");
+
+    expect(signature).toBe(
+      "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+    );
+  });
+});
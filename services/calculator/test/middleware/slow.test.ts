@@ -0,0 +1,72 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import { FakeCalculator } from "../../src/services/fake";
+import type { SlowRequestEntry } from "../../src/middleware/slow";
+
+function post(app: ReturnType<typeof createApp>, path: string) {
+  return app.request(path, {
+    method: "POST",
+    headers: { "Content-Type": "application/json", "X-Request-Id": "req-1" },
+    body: JSON.stringify({ a: 2, b: 3 }),
+  });
+}
+
+describe("logSlowRequests middleware", () => {
+  const clock = new FakeClock();
+  // add takes 250ms by the clock; subtract is instant.
+  const service = new FakeCalculator().returns("add", ([a, b]) => {
+    clock.advance(250);
+    return a + b;
+  });
+
+  it("logs a request slower than the threshold", async () => {
+    const logged: SlowRequestEntry[] = [];
+    const app = createApp({
+      clock,
+      service,
+      slowRequests: { thresholdMs: 100, log: (entry) => logged.push(entry) },
+    });
+
+    const response = await post(app, "/add");
+
+    expect(response.status).toBe(200);
+    expect(logged).toEqual([
+      {
+        level: "warn",
+        message: "slow request",
+        method: "POST",
+        path: "/add",
+        status: 200,
+        durationMs: 250,
+        requestId: "req-1",
+      },
+    ]);
+  });
+
+  it("does not log a fast request", async () => {
+    const logged: SlowRequestEntry[] = [];
+    const app = createApp({
+      clock,
+      service,
+      slowRequests: { thresholdMs: 100, log: (entry) => logged.push(entry) },
+    });
+
+    await post(app, "/subtract");
+
+    expect(logged).toEqual([]);
+  });
+
+  it("does not log a request at the threshold", async () => {
+    const logged: SlowRequestEntry[] = [];
+    const app = createApp({
+      clock,
+      service,
+      slowRequests: { thresholdMs: 250, log: (entry) => logged.push(entry) },
+    });
+
+    await post(app, "/add");
+
+    expect(logged).toEqual([]);
+  });
+});
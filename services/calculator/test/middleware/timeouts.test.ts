@@ -0,0 +1,108 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeCalculator } from "../../src/services/fake";
+
+// Every operation takes 50ms, blocking until then whatever the deadline.
+function slowCalculator() {
+  const fake = new FakeCalculator();
+  const slow = (operands: number[]) =>
+    new Promise<number>((resolve) =>
+      setTimeout(() => resolve(operands.length), 50)
+    );
+  for (const operation of ["add", "addMany", "multiply", "divide"] as const) {
+    fake.returns(operation, slow);
+  }
+  return fake;
+}
+
+function post(target: ReturnType<typeof createApp>, path: string) {
+  const body = path.endsWith("/many") ? { numbers: [1, 2, 3] } : { a: 2, b: 3 };
+  return target.request(path, {
+    method: "POST",
+    body: JSON.stringify(body),
+  });
+}
+
+describe("routeTimeouts middleware", () => {
+  it("gives routes their own timeouts", async () => {
+    const app = createApp({
+      service: slowCalculator(),
+      routeTimeouts: { "/add": 10, "/multiply": 1000 },
+    });
+
+    const short = await post(app, "/add");
+    const long = await post(app, "/multiply");
+
+    expect(short.status).toBe(503);
+    expect(await short.json()).toMatchObject({ code: "timeout" });
+    expect(long.status).toBe(200);
+    expect(await long.json()).toEqual({ result: 2 });
+  });
+
+  it("keeps the global timeout for other routes", async () => {
+    const app = createApp({
+      service: slowCalculator(),
+      requestTimeoutMs: 10,
+      routeTimeouts: { "/multiply": 1000 },
+    });
+
+    expect((await post(app, "/multiply")).status).toBe(200);
+    expect((await post(app, "/divide")).status).toBe(503);
+  });
+
+  it("applies the most specific path", async () => {
+    const app = createApp({
+      service: slowCalculator(),
+      routeTimeouts: { "/add": 10, "/add/many": 1000 },
+    });
+
+    expect((await post(app, "/add")).status).toBe(503);
+    expect((await post(app, "/add/many")).status).toBe(200);
+  });
+
+  it("covers the paths below a key", async () => {
+    const app = createApp({
+      service: slowCalculator(),
+      routeTimeouts: { "/add": 10 },
+    });
+
+    expect((await post(app, "/add/many")).status).toBe(503);
+  });
+
+  it("matches whole path segments", async () => {
+    const app = createApp({
+      service: slowCalculator(),
+      requestTimeoutMs: 1000,
+      routeTimeouts: { "/mult": 10 },
+    });
+
+    expect((await post(app, "/multiply")).status).toBe(200);
+  });
+
+  it("resolves paths under the prefix", async () => {
+    const app = createApp({
+      prefix: "/api/v1",
+      service: slowCalculator(),
+      routeTimeouts: { "/add": 10 },
+    });
+
+    expect((await post(app, "/api/v1/add")).status).toBe(503);
+    expect((await post(app, "/api/v1/multiply")).status).toBe(200);
+  });
+
+  it("applies to aliases as to their targets", async () => {
+    const app = createApp({
+      aliases: { "/sum": "/add" },
+      service: slowCalculator(),
+      routeTimeouts: { "/add": 10 },
+    });
+
+    expect((await post(app, "/sum")).status).toBe(503);
+  });
+
+  it.each([0, -1, NaN, Infinity])("rejects a timeout of %s", (timeoutMs) => {
+    expect(() => createApp({ routeTimeouts: { "/add": timeoutMs } })).toThrow(
+      RangeError
+    );
+  });
+});
@@ -0,0 +1,130 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import {
+  routeMatcher,
+  trimTrailingSlash,
+} from "../../src/middleware/trailing-slash";
+
+function post(app: ReturnType<typeof createApp>, path: string) {
+  return app.request(path, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ a: 2, b: 3 }),
+  });
+}
+
+describe("trimTrailingSlash", () => {
+  it.each([
+    { path: "/add/", expected: "/add" },
+    { path: "/add//", expected: "/add" },
+    { path: "/add", expected: "/add" },
+    { path: "/", expected: "/" },
+  ])("trims $path to $expected", ({ path, expected }) => {
+    expect(trimTrailingSlash(path)).toBe(expected);
+  });
+});
+
+describe("routeMatcher", () => {
+  const matches = routeMatcher([
+    { path: "/*" },
+    { path: "/add" },
+    { path: "/:op/csv" },
+  ]);
+
+  it.each(["/add", "/divide/csv"])("matches %s", (path) => {
+    expect(matches(path)).toBe(true);
+  });
+
+  it.each(["/nope", "/add/many", "/csv"])("does not match %s", (path) => {
+    expect(matches(path)).toBe(false);
+  });
+});
+
+describe("Trailing slashes", () => {
+  describe("rewrite (default)", () => {
+    const app = createApp();
+
+    it("serves /add/ with the add handler", async () => {
+      const response = await post(app, "/add/");
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5 });
+    });
+
+    it("keeps the query string", async () => {
+      const response = await post(app, "/add/?exact=true");
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 5, exact: "5" });
+    });
+
+    it("still returns 404 for an unknown path", async () => {
+      const response = await post(app, "/nope/");
+
+      expect(response.status).toBe(404);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "not_found" });
+    });
+
+    it("resolves aliases with a trailing slash", async () => {
+      const aliased = createApp({ aliases: { "/sum": "/add" } });
+
+      const response = await post(aliased, "/sum/");
+
+      expect(response.status).toBe(200);
+    });
+  });
+
+  describe("redirect", () => {
+    const app = createApp({ trailingSlash: "redirect" });
+
+    it("redirects /add/ to /add with 308", async () => {
+      const response = await post(app, "/add/?exact=true");
+
+      expect(response.status).toBe(308);
+      expect(response.headers.get("Location")).toBe("/add?exact=true");
+    });
+
+    it("redirects to routes with parameters", async () => {
+      const response = await post(app, "/divide/csv/");
+
+      expect(response.status).toBe(308);
+      expect(response.headers.get("Location")).toBe("/divide/csv");
+    });
+
+    it("redirects under the prefix", async () => {
+      const prefixed = createApp({
+        prefix: "/api/v1",
+        trailingSlash: "redirect",
+      });
+
+      const response = await post(prefixed, "/api/v1/add/");
+
+      expect(response.status).toBe(308);
+      expect(response.headers.get("Location")).toBe("/api/v1/add");
+    });
+
+    it("still returns 404 for an unknown path", async () => {
+      const response = await post(app, "/nope/");
+
+      expect(response.status).toBe(404);
+    });
+
+    it("leaves paths without a trailing slash alone", async () => {
+      const response = await post(app, "/add");
+
+      expect(response.status).toBe(200);
+    });
+  });
+
+  describe("strict", () => {
+    it("does not find /add/", async () => {
+      const app = createApp({ trailingSlash: "strict" });
+
+      const response = await post(app, "/add/");
+
+      expect(response.status).toBe(404);
+    });
+  });
+});
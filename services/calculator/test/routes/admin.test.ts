@@ -0,0 +1,87 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+
+const env = { ENABLE_ADMIN: "true" };
+
+function request(
+  app: ReturnType<typeof createApp>,
+  path: string,
+  method = "POST",
+  bindings: Record<string, string> = env
+) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, { method }),
+    bindings
+  );
+}
+
+describe("Admin Routes", () => {
+  it("fails readiness while degraded and recovers", async () => {
+    const app = createApp();
+
+    expect((await request(app, "/readyz", "GET")).status).toBe(200);
+
+    const degrade = await request(app, "/admin/degrade");
+    expect(degrade.status).toBe(200);
+    expect(await degrade.json()).toEqual({ degraded: true });
+
+    const degraded = await request(app, "/readyz", "GET");
+    expect(degraded.status).toBe(503);
+    expect(await degraded.json()).toEqual({ status: "degraded" });
+
+    const recover = await request(app, "/admin/recover");
+    expect(recover.status).toBe(200);
+    expect(await recover.json()).toEqual({ degraded: false });
+
+    const recovered = await request(app, "/readyz", "GET");
+    expect(recovered.status).toBe(200);
+    expect(await recovered.json()).toEqual({ status: "ok" });
+  });
+
+  it("still lists the checks while degraded", async () => {
+    const app = createApp();
+    await request(app, "/admin/degrade");
+
+    const response = await request(app, "/readyz?verbose=true", "GET");
+
+    expect(await response.json()).toEqual({
+      status: "degraded",
+      checks: [
+        { name: "arithmetic", status: "ok", latencyMs: expect.any(Number) },
+      ],
+    });
+  });
+
+  it("leaves /health up while degraded", async () => {
+    const app = createApp();
+    await request(app, "/admin/degrade");
+
+    expect((await request(app, "/health", "GET")).status).toBe(200);
+  });
+
+  it("keeps degradation to one app", async () => {
+    const degradedApp = createApp();
+    const other = createApp();
+    await request(degradedApp, "/admin/degrade");
+
+    expect((await request(other, "/readyz", "GET")).status).toBe(200);
+  });
+
+  it.each(["/admin/degrade", "/admin/recover"])(
+    "returns 404 for %s unless ENABLE_ADMIN is true",
+    async (path) => {
+      const app = createApp();
+
+      const response = await request(app, path, "POST", {});
+
+      expect(response.status).toBe(404);
+      expect((await request(app, "/readyz", "GET", {})).status).toBe(200);
+    }
+  );
+
+  it("returns 405 for GET method", async () => {
+    const response = await request(createApp(), "/admin/degrade", "GET");
+
+    expect(response.status).toBe(405);
+  });
+});
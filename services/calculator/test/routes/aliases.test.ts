@@ -0,0 +1,93 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { checkAliases } from "../../src/routes/aliases";
+import type { StatsResponse } from "../../src/types";
+
+function post(app: ReturnType<typeof createApp>, path: string, body: string) {
+  return app.request(path, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body,
+  });
+}
+
+describe("Route aliases", () => {
+  const app = createApp({ aliases: { "/sum": "/add", "/plus": "/add" } });
+
+  it.each(["/sum", "/plus"])("computes addition at %s", async (path) => {
+    const response = await post(app, path, '{"a": 2, "b": 3}');
+
+    expect(response.status).toBe(200);
+    const json = await response.json();
+    expect(json).toEqual({ result: 5 });
+  });
+
+  it("keeps the query string", async () => {
+    const response = await post(
+      app,
+      "/sum?exact=true",
+      '{"a": 9007199254740993, "b": 0}'
+    );
+
+    const json = await response.json();
+    expect(json).toMatchObject({ exact: "9007199254740993" });
+  });
+
+  it("answers other methods like the target", async () => {
+    const response = await app.request("/sum", { method: "PUT" });
+
+    expect(response.status).toBe(405);
+  });
+
+  it("counts aliased requests under the target operation", async () => {
+    const counted = createApp({ aliases: { "/sum": "/add" } });
+
+    await post(counted, "/sum", '{"a": 1, "b": 2}');
+
+    const response = await counted.request("/stats");
+    const json = await response.json<StatsResponse>();
+    expect(json.operations).toEqual({ add: 1 });
+  });
+
+  it("resolves aliases under the prefix", async () => {
+    const prefixed = createApp({
+      prefix: "/api/v1",
+      aliases: { "/sum": "/add" },
+    });
+
+    const response = await post(prefixed, "/api/v1/sum", '{"a": 2, "b": 3}');
+
+    expect(response.status).toBe(200);
+    expect((await post(prefixed, "/sum", '{"a": 2, "b": 3}')).status).toBe(
+      404
+    );
+  });
+
+  it("rejects an alias that shadows a built-in route", () => {
+    expect(() => createApp({ aliases: { "/subtract": "/add" } })).toThrow(
+      "Alias /subtract would shadow an existing route"
+    );
+  });
+
+  it("rejects an alias to an unknown route", () => {
+    expect(() => createApp({ aliases: { "/sum": "/addition" } })).toThrow(
+      "Alias /sum refers to unknown route /addition"
+    );
+  });
+});
+
+describe("checkAliases", () => {
+  const routes = [{ path: "/add" }, { path: "/subtract" }];
+
+  it("accepts aliases to registered routes", () => {
+    const aliases = new Map([["/sum", "/add"]]);
+
+    expect(() => checkAliases(routes, aliases)).not.toThrow();
+  });
+
+  it("rejects an alias that is a registered route", () => {
+    const aliases = new Map([["/add", "/subtract"]]);
+
+    expect(() => checkAliases(routes, aliases)).toThrow("would shadow");
+  });
+});
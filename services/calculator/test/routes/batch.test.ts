@@ -0,0 +1,292 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeCalculator } from "../../src/services/fake";
+import type { BatchResponse, StatsResponse } from "../../src/types";
+
+function post(app: ReturnType<typeof createApp>, body: string) {
+  return app.fetch(
+    new Request("http://localhost/batch", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body,
+    })
+  );
+}
+
+function postOperations(app: ReturnType<typeof createApp>, ops: unknown[]) {
+  return post(app, JSON.stringify({ operations: ops }));
+}
+
+describe("Batch Routes", () => {
+  describe("POST /batch", () => {
+    const app = createApp();
+
+    it("returns 200 when every operation succeeds", async () => {
+      const response = await postOperations(app, [
+        { operation: "add", a: 1, b: 2 },
+        { operation: "multiply", a: 3, b: 4 },
+        { operation: "ln", a: 1 },
+      ]);
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({
+        results: [{ result: 3 }, { result: 12 }, { result: 0 }],
+      });
+    });
+
+    it("passes each result through resultTransform", async () => {
+      const transformed = createApp({
+        resultTransform: (operation, result) =>
+          operation === "add" ? result * 100 : result,
+      });
+
+      const response = await postOperations(transformed, [
+        { operation: "add", a: 1, b: 2 },
+        { operation: "multiply", a: 3, b: 4 },
+      ]);
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({
+        results: [{ result: 300 }, { result: 12 }],
+      });
+    });
+
+    it("reports isInteger on each result when enabled", async () => {
+      const reporting = createApp({ reportIsInteger: true });
+
+      const response = await postOperations(reporting, [
+        { operation: "add", a: 2.5, b: 2.5 },
+        { operation: "divide", a: 1, b: 4 },
+        { operation: "divide", a: 1, b: 0 },
+      ]);
+
+      expect(response.status).toBe(207);
+      expect((await response.json<BatchResponse>()).results).toEqual([
+        { result: 5, isInteger: true },
+        { result: 0.25, isInteger: false },
+        {
+          error: "invalid input: division by zero",
+          code: "invalid_input",
+          timestamp: expect.any(String),
+        },
+      ]);
+    });
+
+    it("returns 207 when some operations fail", async () => {
+      const response = await postOperations(app, [
+        { operation: "add", a: 1, b: 2 },
+        { operation: "divide", a: 1, b: 0 },
+        { operation: "sqrt", a: 4 },
+        { operation: "subtract", a: 1 },
+        null,
+      ]);
+
+      expect(response.status).toBe(207);
+      const json = await response.json<BatchResponse>();
+      expect(json.results).toEqual([
+        { result: 3 },
+        {
+          error: "invalid input: division by zero",
+          code: "invalid_input",
+          timestamp: expect.any(String),
+        },
+        {
+          error: "Unknown operation: sqrt",
+          code: "unknown_operation",
+          timestamp: expect.any(String),
+        },
+        {
+          error: "Invalid request",
+          code: "invalid_request",
+          timestamp: expect.any(String),
+        },
+        {
+          error: "Invalid request",
+          code: "invalid_request",
+          timestamp: expect.any(String),
+        },
+      ]);
+    });
+
+    it("returns 422 when every operation fails", async () => {
+      const response = await postOperations(app, [
+        { operation: "log", a: -1 },
+        { operation: "sqrt", a: 4 },
+      ]);
+
+      expect(response.status).toBe(422);
+      const json = await response.json<BatchResponse>();
+      expect(json.results).toEqual([
+        {
+          error: "invalid input: logarithm requires a positive operand",
+          code: "invalid_input",
+          timestamp: expect.any(String),
+        },
+        {
+          error: "Unknown operation: sqrt",
+          code: "unknown_operation",
+          timestamp: expect.any(String),
+        },
+      ]);
+    });
+
+    it("returns 200 with no results for an empty batch", async () => {
+      const response = await postOperations(app, []);
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ results: [] });
+    });
+
+    it("returns 400 for malformed JSON", async () => {
+      const response = await post(app, "{");
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "malformed_json" });
+    });
+
+    it("returns 400 for an empty body", async () => {
+      const response = await post(app, "");
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "empty_body" });
+    });
+
+    it.each([
+      { body: {}, message: "required" },
+      { body: [], message: "required" },
+      { body: { operations: "add" }, message: "must be an array" },
+      {
+        body: { operations: Array(1001).fill({ operation: "add" }) },
+        message: "must have at most 1000 items",
+      },
+    ])("returns 400 when operations is $message", async ({ body, message }) => {
+      const response = await post(app, JSON.stringify(body));
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "operations", message }],
+      });
+    });
+
+    it("counts each operation in /stats", async () => {
+      const counted = createApp();
+
+      await postOperations(counted, [
+        { operation: "add", a: 1, b: 2 },
+        { operation: "add", a: 1, b: Infinity },
+      ]);
+
+      const response = await counted.request("/stats");
+      const json = await response.json<StatsResponse>();
+      expect(json.total).toBe(2);
+      expect(json.errors).toBe(1);
+      expect(json.operations).toEqual({ add: 2 });
+    });
+
+    it("keeps request order when operations finish out of order", async () => {
+      // Each add takes a milliseconds, so later operations finish first.
+      const finished: number[] = [];
+      const service = new FakeCalculator().returns("add", async ([a, b]) => {
+        await new Promise((resolve) => setTimeout(resolve, a));
+        finished.push(a);
+        return a + b;
+      });
+      const concurrent = createApp({ service, batchConcurrency: 4 });
+
+      const response = await postOperations(concurrent, [
+        { operation: "add", a: 40, b: 1 },
+        { operation: "add", a: 10, b: 1 },
+        { operation: "add", a: 30, b: 1 },
+        { operation: "add", a: 20, b: 1 },
+      ]);
+
+      expect(response.status).toBe(200);
+      expect(finished).toEqual([10, 20, 30, 40]);
+      const json = await response.json();
+      expect(json).toEqual({
+        results: [
+          { result: 41 },
+          { result: 11 },
+          { result: 31 },
+          { result: 21 },
+        ],
+      });
+    });
+
+    it("evaluates at most batchConcurrency operations at once", async () => {
+      let inFlight = 0;
+      let peak = 0;
+      const service = new FakeCalculator().returns("add", async ([a, b]) => {
+        inFlight++;
+        peak = Math.max(peak, inFlight);
+        await new Promise((resolve) => setTimeout(resolve, 5));
+        inFlight--;
+        return a + b;
+      });
+      const limited = createApp({ service, batchConcurrency: 2 });
+
+      await postOperations(
+        limited,
+        Array.from({ length: 6 }, () => ({ operation: "add", a: 1, b: 2 }))
+      );
+
+      expect(peak).toBe(2);
+    });
+
+    it("shares maxBatchWorkers across overlapping batches", async () => {
+      let inFlight = 0;
+      let peak = 0;
+      let open!: () => void;
+      const gate = new Promise<void>((resolve) => (open = resolve));
+      const service = new FakeCalculator().returns("add", async ([a, b]) => {
+        inFlight++;
+        peak = Math.max(peak, inFlight);
+        await gate;
+        inFlight--;
+        return a + b;
+      });
+      const limited = createApp({
+        service,
+        batchConcurrency: 2,
+        maxBatchWorkers: 3,
+      });
+      const ops = Array.from({ length: 4 }, () => ({
+        operation: "add",
+        a: 1,
+        b: 2,
+      }));
+
+      const first = postOperations(limited, ops);
+      const second = postOperations(limited, ops);
+      const rejected = await postOperations(limited, ops);
+
+      expect(rejected.status).toBe(503);
+      expect(rejected.headers.get("Retry-After")).toBe("1");
+      const json = await rejected.json();
+      expect(json).toMatchObject({
+        error: "Batch capacity exhausted",
+        code: "overloaded",
+      });
+
+      open();
+      expect((await first).status).toBe(200);
+      expect((await second).status).toBe(200);
+      expect(peak).toBe(3);
+
+      const retried = await postOperations(limited, ops);
+      expect(retried.status).toBe(200);
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await app.request("/batch");
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
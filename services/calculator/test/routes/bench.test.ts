@@ -0,0 +1,90 @@
+import { describe, it, expect } from "vitest";
+import app from "../../src/index";
+import type { BenchResponse, StatsResponse } from "../../src/types";
+
+const env = { ENABLE_BENCH: "true" };
+
+function get(query: string, bindings: Record<string, string> = env) {
+  return app.fetch(new Request(`http://localhost/bench${query}`), bindings);
+}
+
+describe("Bench Routes", () => {
+  describe("GET /bench", () => {
+    it("returns a timing summary", async () => {
+      const response = await get("?operation=multiply&iterations=1000");
+
+      expect(response.status).toBe(200);
+      const json = await response.json<BenchResponse>();
+      expect(json).toEqual({
+        operation: "multiply",
+        iterations: 1000,
+        durationMs: expect.any(Number),
+        opsPerSecond: json.durationMs > 0 ? expect.any(Number) : null,
+      });
+    });
+
+    it("defaults to add", async () => {
+      const response = await get("?iterations=10");
+
+      const json = await response.json<BenchResponse>();
+      expect(json.operation).toBe("add");
+      expect(json.iterations).toBe(10);
+    });
+
+    it("returns 404 unless ENABLE_BENCH is true", async () => {
+      const disabled = await get("", {});
+      const off = await get("", { ENABLE_BENCH: "false" });
+
+      expect(disabled.status).toBe(404);
+      expect(off.status).toBe(404);
+      const json = await disabled.json();
+      expect(json).toMatchObject({ code: "not_found" });
+    });
+
+    it("returns 400 for an unknown operation", async () => {
+      const response = await get("?operation=sqrt");
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "operation", message: "unknown operation" }],
+      });
+    });
+
+    it.each(["0", "-5", "1.5", "ten", "10000001"])(
+      "returns 400 for iterations=%s",
+      async (iterations) => {
+        const response = await get(`?iterations=${iterations}`);
+
+        expect(response.status).toBe(400);
+        const json = await response.json();
+        expect(json).toMatchObject({
+          errors: [
+            {
+              field: "iterations",
+              message: "must be an integer from 1 to 10000000",
+            },
+          ],
+        });
+      }
+    );
+
+    it("does not count toward /stats", async () => {
+      await get("?iterations=5");
+
+      const response = await app.fetch(new Request("http://localhost/stats"));
+      const json = await response.json<StatsResponse>();
+      expect(json.total).toBe(0);
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.fetch(
+        new Request("http://localhost/bench", { method: "POST" }),
+        env
+      );
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
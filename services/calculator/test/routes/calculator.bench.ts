@@ -0,0 +1,36 @@
+import { bench, describe } from "vitest";
+import app from "../../src/index";
+import { sha256Hex } from "../../src/middleware/digest";
+
+// Tag of the POST /add response below, so that it is answered with 304.
+const ETAG = `"${await sha256Hex(
+  'POST /add\n{"a":1.5,"b":2.5}\n{"result":4}'
+)}"`;
+
+// End-to-end cost of one operation through routing, middleware and JSON
+// parsing, for comparison with the bare service benchmarks.
+describe("Calculator Routes", () => {
+  bench("POST /add", async () => {
+    await app.fetch(
+      new Request("http://localhost/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1.5, b: 2.5 }),
+      })
+    );
+  });
+
+  // The whole response is still computed and tagged before the 304.
+  bench("POST /add revalidated with If-None-Match", async () => {
+    await app.fetch(
+      new Request("http://localhost/add", {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          "If-None-Match": ETAG,
+        },
+        body: JSON.stringify({ a: 1.5, b: 2.5 }),
+      })
+    );
+  });
+});
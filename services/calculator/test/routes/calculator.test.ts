@@ -1,11 +1,41 @@
 import { describe, it, expect } from "vitest";
-import app from "../../src/index";
+import { CalculatorClient } from "../../src/client";
+import app, { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import { InvalidInputError } from "../../src/services/calculator";
+import { decodeMessagePack } from "../../src/services/msgpack";
+import { calculatorService } from "../../src/services/operations";
+import {
+  createDefaultValidators,
+  ValidatorRegistry,
+} from "../../src/services/validators";
+import type { AuditEntry } from "../../src/services/audit";
+import type { FactorialResponse, StatsResponse } from "../../src/types";
 
 async function makeRequest(path: string, options?: RequestInit) {
   const request = new Request(`http://localhost${path}`, options);
   return app.fetch(request);
 }
 
+interface ServerSentEvent {
+  event?: string;
+  data: string;
+}
+
+function parseEvents(text: string): ServerSentEvent[] {
+  return text
+    .split("\n\n")
+    .filter((block) => block.trim() !== "")
+    .map((block) => {
+      const event: ServerSentEvent = { data: "" };
+      for (const line of block.split("\n")) {
+        if (line.startsWith("event: ")) event.event = line.slice(7);
+        if (line.startsWith("data: ")) event.data += line.slice(6);
+      }
+      return event;
+    });
+}
+
 describe("Calculator Routes", () => {
   describe("POST /add", () => {
     it("returns correct sum for valid inputs", async () => {
@@ -44,18 +74,16 @@ describe("Calculator Routes", () => {
       expect(json).toEqual({ result: 4 });
     });
 
-    it("returns 405 for GET method", async () => {
-      const response = await makeRequest("/add", { method: "GET" });
-
-      expect(response.status).toBe(405);
-      const json = await response.json();
-      expect(json).toEqual({ error: "Method not allowed" });
-    });
-
     it("returns 405 for PUT method", async () => {
       const response = await makeRequest("/add", { method: "PUT" });
 
       expect(response.status).toBe(405);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Method not allowed",
+        code: "method_not_allowed",
+        timestamp: expect.any(String),
+      });
     });
 
     it("returns 405 for DELETE method", async () => {
@@ -105,6 +133,99 @@ describe("Calculator Routes", () => {
     });
   });
 
+  describe("Overflow", () => {
+    it("returns 400 when the sum of two large values overflows", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: Number.MAX_VALUE, b: Number.MAX_VALUE }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "invalid input: result overflowed",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("still returns large results that do not overflow", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1e307, b: 1e307 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 2e307 });
+    });
+  });
+
+  describe("GET /add", () => {
+    it("adds the query parameters", async () => {
+      const response = await makeRequest("/add?a=2&b=3.5", { method: "GET" });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5.5 });
+    });
+
+    it.each(["1e3", "-2", "+.5", "7."])("parses %s", async (a) => {
+      const path = `/add?a=${encodeURIComponent(a)}&b=0`;
+      const response = await makeRequest(path, { method: "GET" });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: Number(a) });
+    });
+
+    it("returns 400 for a missing parameter", async () => {
+      const response = await makeRequest("/add?a=2", { method: "GET" });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "b", message: "required" }],
+      });
+    });
+
+    it.each(["two", "", "0x10", "Infinity", "1,5"])(
+      "returns 400 for the non-numeric parameter %j",
+      async (b) => {
+        const response = await makeRequest(
+          `/add?a=2&b=${encodeURIComponent(b)}`,
+          { method: "GET" }
+        );
+
+        expect(response.status).toBe(400);
+        const json = await response.json();
+        expect(json).toMatchObject({
+          code: "invalid_request",
+          errors: [{ field: "b", message: "must be a number" }],
+        });
+      }
+    );
+
+    it("returns 400 when the sum overflows", async () => {
+      const response = await makeRequest("/add?a=1e308&b=1e308", {
+        method: "GET",
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("tags the response with an ETag", async () => {
+      const response = await makeRequest("/add?a=2&b=3", { method: "GET" });
+
+      expect(response.headers.get("ETag")).toMatch(/^"[0-9a-f]{64}"$/);
+    });
+  });
+
   describe("POST /subtract", () => {
     it("returns correct difference for valid inputs", async () => {
       const response = await makeRequest("/subtract", {
@@ -135,11 +256,44 @@ describe("Calculator Routes", () => {
 
       expect(response.status).toBe(405);
       const json = await response.json();
-      expect(json).toEqual({ error: "Method not allowed" });
+      expect(json).toEqual({
+        error: "Method not allowed",
+        code: "method_not_allowed",
+        timestamp: expect.any(String),
+      });
     });
   });
 
   describe("POST /multiply", () => {
+    it("reports an overflowing product before computing it", async () => {
+      const response = await makeRequest("/multiply", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1e200, b: -1e200 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error:
+          "invalid input: product magnitude exceeds the largest finite number",
+        code: "invalid_input",
+      });
+    });
+
+    it("falls back to the generic overflow without the pre-check", async () => {
+      const app = createApp({ validators: new ValidatorRegistry() });
+
+      const response = await app.request("/multiply", {
+        method: "POST",
+        body: JSON.stringify({ a: 1e200, b: 1e200 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ error: "invalid input: result overflowed" });
+    });
+
     it("returns correct product for valid inputs", async () => {
       const response = await makeRequest("/multiply", {
         method: "POST",
@@ -181,47 +335,2603 @@ describe("Calculator Routes", () => {
 
       expect(response.status).toBe(405);
       const json = await response.json();
-      expect(json).toEqual({ error: "Method not allowed" });
+      expect(json).toEqual({
+        error: "Method not allowed",
+        code: "method_not_allowed",
+        timestamp: expect.any(String),
+      });
     });
   });
 
-  describe("GET /health", () => {
-    it("returns ok status", async () => {
-      const response = await makeRequest("/health", { method: "GET" });
+  describe("POST /divide", () => {
+    it("returns the quotient", async () => {
+      const response = await makeRequest("/divide", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: 4 }),
+      });
 
       expect(response.status).toBe(200);
       const json = await response.json();
-      expect(json).toEqual({ status: "ok" });
+      expect(json).toEqual({ result: 0.25 });
     });
 
-    it("returns 405 for POST method", async () => {
-      const response = await makeRequest("/health", { method: "POST" });
+    it("returns 400 for division by zero", async () => {
+      const response = await makeRequest("/divide", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: 0 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: division by zero",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns the exact fraction in exact mode", async () => {
+      const response = await makeRequest("/divide?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: 3 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 1 / 3, exact: "1/3" });
+    });
+
+    it("returns an integer exact result when the division is even", async () => {
+      const response = await makeRequest("/divide?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 12, b: 4 }),
+      });
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 3, exact: "3" });
+    });
+
+    it("rejects a zero divisor in exact mode", async () => {
+      const response = await makeRequest("/divide?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: 0 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    function divide(query: string, a: number, b: number) {
+      return makeRequest(`/divide${query}`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a, b }),
+      });
+    }
+
+    it("returns full precision by default", async () => {
+      const response = await divide("", 10, 3);
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 10 / 3 });
+    });
+
+    it("rounds the quotient to ?places", async () => {
+      const response = await divide("?places=4", 10, 3);
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 3.3333 });
+    });
+
+    it("rounds ties to even", async () => {
+      const response = await divide("?places=1", 1, 4);
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 0.2 });
+    });
+
+    it("rounds the result but not the fraction in exact mode", async () => {
+      const response = await divide("?exact=true&places=2", 2, 3);
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 0.67, exact: "2/3" });
+    });
+
+    it.each(["-1", "16", "1.5", "two", ""])(
+      "returns 400 for places=%j",
+      async (places) => {
+        const response = await divide(`?places=${places}`, 10, 3);
+
+        expect(response.status).toBe(400);
+        const json = await response.json();
+        expect(json).toMatchObject({
+          code: "invalid_request",
+          errors: [
+            { field: "places", message: "must be an integer from 0 to 15" },
+          ],
+        });
+      }
+    );
+
+    it("ignores ?places on other operations", async () => {
+      const response = await makeRequest("/multiply?places=1", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1.25, b: 1.25 }),
+      });
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 1.5625 });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/divide", { method: "GET" });
 
       expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /divmod", () => {
+    it.each([
+      { a: 7, b: 2, quotient: 3, remainder: 1 },
+      { a: -7, b: 2, quotient: -3, remainder: -1 },
+      { a: 7, b: -2, quotient: -3, remainder: 1 },
+      { a: -7, b: -2, quotient: 3, remainder: -1 },
+      { a: -1, b: 2, quotient: 0, remainder: -1 },
+    ])(
+      "truncates toward zero for a=$a, b=$b",
+      async ({ a, b, quotient, remainder }) => {
+        const response = await makeRequest("/divmod", {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ a, b }),
+        });
+
+        expect(response.status).toBe(200);
+        const json = await response.json();
+        expect(json).toEqual({ quotient, remainder });
+      }
+    );
+
+    it("returns 400 for a zero divisor", async () => {
+      const response = await makeRequest("/divmod", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: 0 }),
+      });
+
+      expect(response.status).toBe(400);
       const json = await response.json();
-      expect(json).toEqual({ error: "Method not allowed" });
+      expect(json).toMatchObject({
+        error: "invalid input: division by zero",
+        code: "invalid_input",
+      });
     });
 
-    it("returns 405 for PUT method", async () => {
-      const response = await makeRequest("/health", { method: "PUT" });
+    it("returns 400 listing missing fields", async () => {
+      const response = await makeRequest("/divmod", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "b", message: "required" }],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/divmod", { method: "GET" });
 
       expect(response.status).toBe(405);
     });
+  });
+
+  describe("POST /factorial", () => {
+    it.each([
+      { a: 0, exact: "1", result: 1 },
+      { a: 5, exact: "120", result: 120 },
+    ])("returns $exact for $a!", async ({ a, exact, result }) => {
+      const response = await makeRequest("/factorial", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ exact, result });
+    });
 
-    it("returns 405 for DELETE method", async () => {
-      const response = await makeRequest("/health", { method: "DELETE" });
+    it("returns a null result beyond float64 range", async () => {
+      const response = await makeRequest("/factorial", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 171 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = (await response.json()) as FactorialResponse;
+      expect(json.result).toBeNull();
+      expect(json.exact).toHaveLength(310);
+    });
+
+    it.each([
+      {
+        a: -1,
+        error: "invalid input: factorial requires a non-negative operand",
+      },
+      { a: 1.5, error: "invalid input: operands must be integers" },
+      { a: 10001, error: "invalid input: factorial operand exceeds 10000" },
+    ])("returns 400 for $a", async ({ a, error }) => {
+      const response = await makeRequest("/factorial", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ error, code: "invalid_input" });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/factorial", { method: "GET" });
 
       expect(response.status).toBe(405);
     });
   });
 
-  describe("404 Not Found", () => {
-    it("returns 404 for unknown endpoints", async () => {
-      const response = await makeRequest("/unknown", { method: "GET" });
+  describe("POST /hypot", () => {
+    it("returns 5 for the 3-4-5 triangle", async () => {
+      const response = await makeRequest("/hypot", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 3, b: 4 }),
+      });
 
-      expect(response.status).toBe(404);
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5 });
+    });
+
+    it("returns a positive result for negative operands", async () => {
+      const response = await makeRequest("/hypot", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: -6, b: -8 }),
+      });
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 10 });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/hypot", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /diff", () => {
+    it.each([
+      { a: 3, b: 7, expected: 4 },
+      { a: 7, b: 3, expected: 4 },
+      { a: -3, b: -7, expected: 4 },
+      { a: 5, b: 5, expected: 0 },
+    ])("returns $expected for a=$a, b=$b", async ({ a, b, expected }) => {
+      const response = await makeRequest("/diff", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a, b }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: expected });
+    });
+
+    it("returns 400 when the difference overflows", async () => {
+      const response = await makeRequest("/diff", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: Number.MAX_VALUE, b: -Number.MAX_VALUE }),
+      });
+
+      expect(response.status).toBe(400);
       const json = await response.json();
-      expect(json).toEqual({ error: "Not found" });
+      expect(json).toEqual({
+        error: "invalid input: result overflowed",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/diff", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /percent-change", () => {
+    it.each([
+      { a: 50, b: 75, expected: 50 },
+      { a: 50, b: 25, expected: -50 },
+      { a: 40, b: 40, expected: 0 },
+    ])("returns $expected for a=$a, b=$b", async ({ a, b, expected }) => {
+      const response = await makeRequest("/percent-change", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a, b }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: expected });
+    });
+
+    it("returns 400 when a is zero", async () => {
+      const response = await makeRequest("/percent-change", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0, b: 5 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "invalid input: division by zero",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 400 when the result overflows", async () => {
+      const response = await makeRequest("/percent-change", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: Number.MIN_VALUE, b: 1 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json.error).toBe("invalid input: result overflowed");
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/percent-change", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /gcd", () => {
+    it("returns the greatest common divisor", async () => {
+      const response = await makeRequest("/gcd", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 12, b: 18 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 6 });
+    });
+
+    it("returns 400 for a non-integer operand", async () => {
+      const response = await makeRequest("/gcd", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1.5, b: 3 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "invalid input: operands must be integers",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/gcd", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /lcm", () => {
+    it("returns the least common multiple", async () => {
+      const response = await makeRequest("/lcm", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 4, b: 6 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 12 });
+    });
+
+    it("returns 400 beyond the safe integer range", async () => {
+      const response = await makeRequest("/lcm", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 2 ** 30 + 1, b: 2 ** 30 - 1 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: result overflowed",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/lcm", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /add/many", () => {
+    it.each([
+      { numbers: [], expected: 0 },
+      { numbers: [42], expected: 42 },
+      { numbers: [1, 2, 3.5], expected: 6.5 },
+    ])("sums $numbers to $expected", async ({ numbers, expected }) => {
+      const response = await makeRequest("/add/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: expected });
+    });
+
+    it("sums a list too long to spread into a call", async () => {
+      const numbers = Array<number>(300_000).fill(1);
+
+      const response = await makeRequest("/add/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers }),
+      });
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({ result: 300_000 });
+    });
+
+    it("rejects a NaN element", async () => {
+      // JSON has no NaN; JSON.stringify sends it as null.
+      const response = await makeRequest("/add/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [1, NaN, 3] }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "numbers[1]", message: "must be a number" }],
+      });
+    });
+
+    it("rejects a non-finite element", async () => {
+      const response = await makeRequest("/add/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"numbers": [1, 1e999]}',
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("lists a missing list", async () => {
+      const response = await makeRequest("/add/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: 3 }),
+      });
+
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [{ field: "numbers", message: "must be an array" }],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/add/many", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /sum/kahan", () => {
+    it("keeps small elements next to a large one", async () => {
+      const numbers = [1e16, ...Array<number>(100).fill(1)];
+      const response = await makeRequest("/sum/kahan", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 1e16 + 100 });
+    });
+
+    it("rejects a non-number element", async () => {
+      const response = await makeRequest("/sum/kahan", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [1, "2"] }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "numbers[1]", message: "must be a number" }],
+      });
+    });
+
+    it("rejects a non-finite element", async () => {
+      const response = await makeRequest("/sum/kahan", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"numbers": [1, 1e999]}',
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/sum/kahan", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /stats/summary", () => {
+    it("summarizes the list", async () => {
+      const response = await makeRequest("/stats/summary", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [2, 4, 4, 4, 5, 5, 7, 9] }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({
+        count: 8,
+        mean: 5,
+        variance: 4,
+        stddev: 2,
+        min: 2,
+        max: 9,
+      });
+    });
+
+    it("rejects an empty list", async () => {
+      const response = await makeRequest("/stats/summary", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [] }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_input",
+        error: "invalid input: summary requires at least one number",
+      });
+    });
+
+    it("rejects a non-number element", async () => {
+      const response = await makeRequest("/stats/summary", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [1, null] }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [{ field: "numbers[1]", message: "must be a number" }],
+      });
+    });
+
+    it("rejects a non-finite element", async () => {
+      const response = await makeRequest("/stats/summary", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"numbers": [1, 1e999]}',
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/stats/summary", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /multiply/many", () => {
+    it.each([
+      { numbers: [], expected: 1 },
+      { numbers: [42], expected: 42 },
+      { numbers: [2, 3, 0.5], expected: 3 },
+    ])("multiplies $numbers to $expected", async ({ numbers, expected }) => {
+      const response = await makeRequest("/multiply/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: expected });
+    });
+
+    it("rejects a NaN element", async () => {
+      const response = await makeRequest("/multiply/many", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [NaN] }),
+      });
+
+      expect(response.status).toBe(400);
+    });
+  });
+
+  describe("POST /weighted-sum", () => {
+    it("returns the weighted sum for fractional weights", async () => {
+      const response = await makeRequest("/weighted-sum", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 100, wa: 0.2, b: 50, wb: 0.8 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 60 });
+    });
+
+    it("returns 400 when the result overflows", async () => {
+      const response = await makeRequest("/weighted-sum", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1e308, wa: 2, b: 1e308, wb: 2 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: result overflowed",
+        code: "invalid_input",
+      });
+    });
+
+    it("lists missing weights", async () => {
+      const response = await makeRequest("/weighted-sum", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: 2 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [
+          { field: "wa", message: "required" },
+          { field: "wb", message: "required" },
+        ],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/weighted-sum", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /polynomial", () => {
+    const polynomial = (body: unknown) =>
+      makeRequest("/polynomial", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+      });
+
+    it("evaluates 2x² + 3x + 1 at 2", async () => {
+      const response = await polynomial({ coefficients: [2, 3, 1], x: 2 });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 15 });
+    });
+
+    it("returns 400 for no coefficients", async () => {
+      const response = await polynomial({ coefficients: [], x: 2 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: polynomial requires at least one coefficient",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 400 when the result overflows", async () => {
+      const response = await polynomial({ coefficients: [1, 0, 0], x: 1e200 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: result overflowed",
+        code: "invalid_input",
+      });
+    });
+
+    it("lists every non-numeric coefficient and a missing x", async () => {
+      const response = await polynomial({ coefficients: [1, "2", null] });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "coefficients[1]", message: "must be a number" },
+          { field: "coefficients[2]", message: "must be a number" },
+          { field: "x", message: "required" },
+        ],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/polynomial", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /convert", () => {
+    it("converts Celsius to Fahrenheit", async () => {
+      const response = await makeRequest("/convert", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ value: 100, scale: 1.8, offset: 32 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 212 });
+    });
+
+    it("lists every missing or non-numeric field", async () => {
+      const response = await makeRequest("/convert", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ value: "NaN", offset: null }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "value", message: "must be a number" },
+          { field: "scale", message: "required" },
+          { field: "offset", message: "must be a number" },
+        ],
+      });
+    });
+
+    it("returns 400 when the result overflows", async () => {
+      const response = await makeRequest("/convert", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ value: 1e308, scale: 10, offset: 0 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/convert", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /fma", () => {
+    it("rounds the result once", async () => {
+      const response = await makeRequest("/fma", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0.1, b: 10, c: -1 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5.551115123125783e-17 });
+    });
+
+    it("lists every missing or non-numeric operand", async () => {
+      const response = await makeRequest("/fma", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1, b: "2" }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "b", message: "must be a number" },
+          { field: "c", message: "required" },
+        ],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/fma", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /clamp", () => {
+    const clamp = (body: unknown) =>
+      makeRequest("/clamp", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+      });
+
+    it.each([
+      { value: -5, expected: 0, name: "min for a value below it" },
+      { value: 15, expected: 10, name: "max for a value above it" },
+      { value: 4, expected: 4, name: "a value in range unchanged" },
+    ])("returns $name", async ({ value, expected }) => {
+      const response = await clamp({ value, min: 0, max: 10 });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: expected });
+    });
+
+    it("returns 400 when min exceeds max", async () => {
+      const response = await clamp({ value: 5, min: 10, max: 0 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: min must not exceed max",
+        code: "invalid_input",
+      });
+    });
+
+    it("lists every missing or non-numeric operand", async () => {
+      const response = await clamp({ value: "5", max: 10 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "value", message: "must be a number" },
+          { field: "min", message: "required" },
+        ],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/clamp", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /calc/reverse-polish", () => {
+    const evaluate = (body: unknown) =>
+      makeRequest("/calc/reverse-polish", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+      });
+
+    it("evaluates the expression", async () => {
+      const response = await evaluate({ tokens: ["3", "4", "+", "2", "*"] });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 14 });
+    });
+
+    it("returns 400 on stack underflow", async () => {
+      const response = await evaluate({ tokens: ["3", "+"] });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error:
+          'invalid input: stack underflow at token 1 ("+" needs 2 operands, found 1)',
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 400 when operands are left over", async () => {
+      const response = await evaluate({ tokens: ["3", "4", "5", "+"] });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: 2 operands left on the stack, expected 1",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 400 for division by zero", async () => {
+      const response = await evaluate({ tokens: ["1", "2", "2", "-", "/"] });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: division by zero",
+        code: "invalid_input",
+      });
+    });
+
+    it("lists each token that is not a string", async () => {
+      const response = await evaluate({ tokens: ["3", 4, "+"] });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "tokens[1]", message: "must be a string" }],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/calc/reverse-polish", {
+        method: "GET",
+      });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /compare", () => {
+    it.each([
+      { a: 1, b: 2, comparison: -1 },
+      { a: 2, b: 2, comparison: 0 },
+      { a: 3, b: 2, comparison: 1 },
+    ])("returns $comparison for a=$a, b=$b", async ({ a, b, comparison }) => {
+      const response = await makeRequest("/compare", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a, b }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ comparison });
+    });
+
+    it("returns 400 for an infinite operand", async () => {
+      // 1e999 is valid JSON that parses to Infinity.
+      const response = await makeRequest("/compare", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"a": 1e999, "b": 1}',
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: NaN and Infinity not allowed",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/compare", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /approx-equal", () => {
+    const approxEqual = (body: unknown) =>
+      makeRequest("/approx-equal", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+      });
+
+    it.each([
+      { a: 0.1 + 0.2, b: 0.3, epsilon: 1e-9, equal: true },
+      { a: 1e20, b: 1e20 + 16384, epsilon: 1e-9, equal: true },
+      { a: 1, b: 1.1, epsilon: 0.01, equal: false },
+      { a: 0.1 + 0.2, b: 0.3, epsilon: 0, equal: false },
+    ])(
+      "returns $equal for a=$a, b=$b, epsilon=$epsilon",
+      async ({ a, b, epsilon, equal }) => {
+        const response = await approxEqual({ a, b, epsilon });
+
+        expect(response.status).toBe(200);
+        const json = await response.json();
+        expect(json).toEqual({ equal });
+      }
+    );
+
+    it("returns 400 for a negative epsilon", async () => {
+      const response = await approxEqual({ a: 1, b: 1, epsilon: -0.1 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: epsilon must not be negative",
+        code: "invalid_input",
+      });
+    });
+
+    it("lists every missing or non-numeric operand", async () => {
+      const response = await approxEqual({ a: 1, b: "1" });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "b", message: "must be a number" },
+          { field: "epsilon", message: "required" },
+        ],
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/approx-equal", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /round", () => {
+    function round(body: unknown) {
+      return makeRequest("/round", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+      });
+    }
+
+    it.each([
+      { mode: "half_even", value: 2.5, expected: 2 },
+      { mode: "half_up", value: 2.5, expected: 3 },
+      { mode: "half_down", value: -2.5, expected: -2 },
+      { mode: "ceil", value: -2.5, expected: -2 },
+      { mode: "floor", value: -2.5, expected: -3 },
+      { mode: "trunc", value: -2.7, expected: -2 },
+    ])(
+      "$mode rounds $value to $expected",
+      async ({ mode, value, expected }) => {
+        const response = await round({ value, places: 0, mode });
+
+        expect(response.status).toBe(200);
+        const json = await response.json();
+        expect(json).toEqual({ result: expected });
+      }
+    );
+
+    it("defaults to half_even", async () => {
+      const response = await round({ value: 0.125, places: 2 });
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 0.12 });
+    });
+
+    it("returns 0 rather than -0", async () => {
+      const response = await round({ value: -0.4, places: 0, mode: "trunc" });
+
+      expect(await response.text()).toBe('{"result":0}');
+    });
+
+    it("lists an unknown mode with the other invalid fields", async () => {
+      const response = await round({ value: 1, mode: "nearest" });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "places", message: "required" },
+          {
+            field: "mode",
+            message:
+              "must be one of half_even, half_up, half_down, ceil, floor, trunc",
+          },
+        ],
+      });
+    });
+
+    it.each([-1, 2.5, 16])("returns 400 for %s places", async (places) => {
+      const response = await round({ value: 1, places });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: places must be an integer from 0 to 15",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/round", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("exact mode", () => {
+    // 2^53 + 1 is the smallest positive integer float64 cannot represent.
+    const body = '{"a": 9007199254740993, "b": 0}';
+
+    it("loses precision by default", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body,
+      });
+
+      expect(response.status).toBe(200);
+      const text = await response.text();
+      expect(text).toBe('{"result":9007199254740992}');
+    });
+
+    it("returns the exact result with ?exact=true", async () => {
+      const response = await makeRequest("/add?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body,
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({
+        result: 9007199254740992,
+        exact: "9007199254740993",
+      });
+    });
+
+    it("subtracts and multiplies exactly", async () => {
+      const subtract = await makeRequest("/subtract?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"a": 9007199254740993, "b": 9007199254740992}',
+      });
+      const multiply = await makeRequest("/multiply?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"a": 123456789012345678, "b": 1000}',
+      });
+
+      expect(await subtract.json()).toMatchObject({ exact: "1" });
+      expect(await multiply.json()).toMatchObject({
+        exact: "123456789012345678000",
+      });
+    });
+
+    it("ignores nested fields named a or b", async () => {
+      const response = await makeRequest("/add?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"a": 1, "b": 2, "meta": {"a": 100}}',
+      });
+
+      expect(await response.json()).toMatchObject({ exact: "3" });
+    });
+
+    it("returns 400 for non-integer operands", async () => {
+      const response = await makeRequest("/add?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"a": 1.5, "b": 2}',
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: exact mode requires integer operands",
+        code: "invalid_input",
+      });
+    });
+
+    it("returns 400 for malformed JSON", async () => {
+      const response = await makeRequest("/add?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: "{",
+      });
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "malformed_json" });
+    });
+  });
+
+  describe("POST /sin", () => {
+    it("returns sin(0) = 0", async () => {
+      const response = await makeRequest("/sin", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 0 });
+    });
+
+    it("returns 400 for missing operand", async () => {
+      const response = await makeRequest("/sin", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({}),
+      });
+
+      expect(response.status).toBe(400);
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/sin", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /cos", () => {
+    it("returns cos(0) = 1", async () => {
+      const response = await makeRequest("/cos", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 1 });
+    });
+  });
+
+  describe("POST /tan", () => {
+    it("returns tan(0) = 0", async () => {
+      const response = await makeRequest("/tan", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 0 });
+    });
+  });
+
+  describe("POST /log", () => {
+    it("returns log(100) = 2", async () => {
+      const response = await makeRequest("/log", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 100 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 2 });
+    });
+
+    it("returns 400 for zero operand", async () => {
+      const response = await makeRequest("/log", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "invalid input: logarithm requires a positive operand",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 400 for negative operand", async () => {
+      const response = await makeRequest("/log", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: -5 }),
+      });
+
+      expect(response.status).toBe(400);
+    });
+  });
+
+  describe("POST /ln", () => {
+    it("returns ln(1) = 0", async () => {
+      const response = await makeRequest("/ln", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 1 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 0 });
+    });
+
+    it("returns 400 for negative operand", async () => {
+      const response = await makeRequest("/ln", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: -1 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "invalid input: logarithm requires a positive operand",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+  });
+
+  describe("POST /sum-list/sse", () => {
+    it("streams running partial sums", async () => {
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [1, 2, 3.5, -4] }),
+      });
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("content-type")).toContain(
+        "text/event-stream"
+      );
+      const events = parseEvents(await response.text());
+      expect(events.map((e) => e.event)).toEqual([
+        undefined,
+        undefined,
+        undefined,
+        undefined,
+      ]);
+      expect(events.map((e) => JSON.parse(e.data))).toEqual([
+        { result: 1 },
+        { result: 3 },
+        { result: 6.5 },
+        { result: 2.5 },
+      ]);
+    });
+
+    it("emits an error event and stops at an invalid element", async () => {
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [1, 2, "three", 4] }),
+      });
+
+      const events = parseEvents(await response.text());
+      expect(events).toHaveLength(3);
+      expect(JSON.parse(events[1].data)).toEqual({ result: 3 });
+      expect(events[2].event).toBe("error");
+      expect(JSON.parse(events[2].data)).toEqual({
+        error: "invalid input: element 2 is not a number",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("emits an error event when the sum overflows", async () => {
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [1e308, 1e308] }),
+      });
+
+      const events = parseEvents(await response.text());
+      expect(events).toHaveLength(2);
+      expect(events[1].event).toBe("error");
+      expect(JSON.parse(events[1].data)).toMatchObject({
+        error: "invalid input: partial sum overflowed at element 1",
+      });
+    });
+
+    it("emits an error event for a non-finite element", async () => {
+      // 1e999 is valid JSON that parses to Infinity.
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"numbers": [1, 1e999]}',
+      });
+
+      const events = parseEvents(await response.text());
+      expect(events).toHaveLength(2);
+      expect(events[1].event).toBe("error");
+      expect(JSON.parse(events[1].data)).toMatchObject({
+        error: "invalid input: element 1 is not finite",
+        code: "invalid_input",
+      });
+    });
+
+    it("streams nothing for an empty list", async () => {
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: [] }),
+      });
+
+      expect(response.status).toBe(200);
+      expect(parseEvents(await response.text())).toEqual([]);
+    });
+
+    it("returns 400 when numbers is not a list", async () => {
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ numbers: 5 }),
+      });
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "numbers", message: "must be an array" }],
+      });
+    });
+
+    it("returns 400 empty_body for an empty body", async () => {
+      const response = await makeRequest("/sum-list/sse", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+      });
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "empty_body" });
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await makeRequest("/sum-list/sse", { method: "GET" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("GET /health", () => {
+    it("returns ok status", async () => {
+      const response = await makeRequest("/health", { method: "GET" });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ status: "ok" });
+    });
+
+    it("returns 200 with no body for HEAD method", async () => {
+      const response = await makeRequest("/health", { method: "HEAD" });
+
+      expect(response.status).toBe(200);
+      expect(await response.text()).toBe("");
+    });
+
+    it("returns 405 with an Allow header for POST method", async () => {
+      const response = await makeRequest("/health", { method: "POST" });
+
+      expect(response.status).toBe(405);
+      expect(response.headers.get("allow")).toBe("GET, HEAD");
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Method not allowed",
+        code: "health_method_not_allowed",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 405 for PUT method", async () => {
+      const response = await makeRequest("/health", { method: "PUT" });
+
+      expect(response.status).toBe(405);
+    });
+
+    it("returns 405 for DELETE method", async () => {
+      const response = await makeRequest("/health", { method: "DELETE" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("Custom validators", () => {
+    const validators = createDefaultValidators().register("add", ([a, b]) => {
+      if (a < 0 || b < 0) {
+        throw new InvalidInputError("invalid input: operands must be positive");
+      }
+    });
+    const validated = createApp({ validators });
+
+    function post(path: string, body: unknown) {
+      return validated.fetch(
+        new Request(`http://localhost${path}`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify(body),
+        })
+      );
+    }
+
+    it("rejects inputs the registered validator forbids", async () => {
+      const response = await post("/add", { a: -1, b: 2 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "invalid input: operands must be positive",
+        code: "invalid_input",
+      });
+    });
+
+    it("allows inputs the registered validator accepts", async () => {
+      const response = await post("/add", { a: 1, b: 2 });
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({ result: 3 });
+    });
+
+    it("leaves other operations unaffected", async () => {
+      const response = await post("/subtract", { a: -1, b: 2 });
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({ result: -3 });
+    });
+
+    it("keeps the built-in domain rules", async () => {
+      const response = await post("/ln", { a: -1 });
+
+      expect(response.status).toBe(400);
+    });
+  });
+
+  describe("Error codes", () => {
+    it("returns invalid_input for a non-finite operand", async () => {
+      // 1e999 is valid JSON that parses to Infinity.
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: '{"a": 1e999, "b": 1}',
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "invalid input: NaN and Infinity not allowed",
+        code: "invalid_input",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns invalid_request for a NaN operand serialized as null", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: NaN, b: 1 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_request" });
+    });
+
+    it("returns method_not_allowed for PUT on /add", async () => {
+      const response = await makeRequest("/add", { method: "PUT" });
+
+      expect(response.status).toBe(405);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "Method not allowed",
+        code: "method_not_allowed",
+      });
+    });
+
+    it("returns malformed_json for invalid JSON", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: "{not json",
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "Malformed JSON",
+        code: "malformed_json",
+      });
+    });
+
+    it("returns empty_body for a request without a body", async () => {
+      const response = await makeRequest("/add", { method: "POST" });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "Request body is empty",
+        code: "empty_body",
+      });
+    });
+
+    it("returns empty_body for a whitespace-only body", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: " \r\n\t",
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "empty_body" });
+    });
+
+    it("returns empty_body in exact mode too", async () => {
+      const response = await makeRequest("/add?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: "",
+      });
+
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "empty_body" });
+    });
+
+    it("lists the missing operands of an empty object", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: "{}",
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "a", message: "required" },
+          { field: "b", message: "required" },
+        ],
+      });
+    });
+
+    it("returns not_found for unknown endpoints", async () => {
+      const response = await makeRequest("/unknown", { method: "GET" });
+
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "not_found" });
+    });
+  });
+
+  describe("Field errors", () => {
+    it("lists a missing field", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 5 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Invalid request",
+        code: "invalid_request",
+        timestamp: expect.any(String),
+        errors: [{ field: "b", message: "required" }],
+      });
+    });
+
+    it("lists every missing field", async () => {
+      const response = await makeRequest("/subtract", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({}),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [
+          { field: "a", message: "required" },
+          { field: "b", message: "required" },
+        ],
+      });
+    });
+
+    it("computes when both fields are present", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: 0, b: 0 }),
+      });
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 0 });
+    });
+
+    it("reports fields of the wrong type", async () => {
+      const response = await makeRequest("/multiply", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ a: "2", b: null }),
+      });
+
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [
+          { field: "a", message: "must be a number" },
+          { field: "b", message: "must be a number" },
+        ],
+      });
+    });
+
+    it("reports every field when the body is not an object", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify([1, 2]),
+      });
+
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [
+          { field: "a", message: "required" },
+          { field: "b", message: "required" },
+        ],
+      });
+    });
+
+    it("lists a missing operand for unary operations", async () => {
+      const response = await makeRequest("/ln", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ b: 1 }),
+      });
+
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [{ field: "a", message: "required" }],
+      });
+    });
+
+    it("lists missing fields in exact mode", async () => {
+      const response = await makeRequest("/add?exact=true", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ b: 1 }),
+      });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        errors: [{ field: "a", message: "required" }],
+      });
+    });
+  });
+
+  describe("Error timestamps", () => {
+    it("uses the injected clock for error timestamps", async () => {
+      const clock = new FakeClock(new Date("2024-06-01T12:00:00Z"));
+      const fixed = createApp({ clock });
+
+      const response = await fixed.fetch(
+        new Request("http://localhost/add", { method: "PUT" })
+      );
+
+      expect(response.status).toBe(405);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Method not allowed",
+        code: "method_not_allowed",
+        timestamp: "2024-06-01T12:00:00.000Z",
+      });
+    });
+
+    it("stamps validation errors and not-found responses", async () => {
+      const clock = new FakeClock(new Date("2024-06-01T12:00:00Z"));
+      const fixed = createApp({ clock });
+
+      clock.advance(1500);
+      const invalid = await fixed.fetch(
+        new Request("http://localhost/log", {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ a: -1 }),
+        })
+      );
+      const missing = await fixed.fetch(new Request("http://localhost/nope"));
+
+      expect(await invalid.json()).toMatchObject({
+        timestamp: "2024-06-01T12:00:01.500Z",
+      });
+      expect(await missing.json()).toMatchObject({
+        timestamp: "2024-06-01T12:00:01.500Z",
+      });
+    });
+  });
+
+  describe("Request deadline", () => {
+    function post(app: ReturnType<typeof createApp>, path: string) {
+      return app.fetch(
+        new Request(`http://localhost${path}`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ a: 2, b: 3 }),
+        })
+      );
+    }
+
+    it("returns 503 when the service runs past the deadline", async () => {
+      const app = createApp({
+        requestTimeoutMs: 10,
+        service: {
+          ...calculatorService,
+          // Blocks until the deadline's signal fires.
+          add: (_a, _b, signal) =>
+            new Promise((_resolve, reject) => {
+              signal?.addEventListener("abort", () => reject(signal.reason));
+            }),
+        },
+      });
+
+      const response = await post(app, "/add");
+
+      expect(response.status).toBe(503);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Request timed out",
+        code: "timeout",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 503 even if the service ignores the signal", async () => {
+      const app = createApp({
+        requestTimeoutMs: 10,
+        service: {
+          ...calculatorService,
+          multiply: () => new Promise<number>(() => {}),
+        },
+      });
+
+      const response = await post(app, "/multiply");
+
+      expect(response.status).toBe(503);
+    });
+
+    it("passes the signal to the service", async () => {
+      let received: AbortSignal | undefined;
+      const app = createApp({
+        service: {
+          ...calculatorService,
+          subtract: (a, b, signal) => {
+            received = signal;
+            return a - b;
+          },
+        },
+      });
+
+      const response = await post(app, "/subtract");
+
+      expect(response.status).toBe(200);
+      expect(received).toBeInstanceOf(AbortSignal);
+      expect(received?.aborted).toBe(false);
+    });
+  });
+
+  describe("Result transform", () => {
+    function post(app: ReturnType<typeof createApp>, path: string) {
+      return app.fetch(
+        new Request(`http://localhost${path}`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ a: 2, b: 3 }),
+        })
+      );
+    }
+
+    it("applies the transform to the result", async () => {
+      const app = createApp({ resultTransform: (_, result) => result * 2 });
+
+      const response = await post(app, "/add");
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 10 });
+    });
+
+    it("passes the operation name", async () => {
+      const operations: string[] = [];
+      const app = createApp({
+        resultTransform: (operation, result) => {
+          operations.push(operation);
+          return result;
+        },
+      });
+
+      await post(app, "/multiply");
+      await post(app, "/hypot");
+
+      expect(operations).toEqual(["multiply", "hypot"]);
+    });
+
+    it("leaves the exact value as computed", async () => {
+      const app = createApp({ resultTransform: (_, result) => result * 2 });
+
+      const response = await post(app, "/add?exact=true");
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 10, exact: "5" });
+    });
+
+    it("is not applied to errors", async () => {
+      let calls = 0;
+      const app = createApp({
+        resultTransform: (_, result) => {
+          calls++;
+          return result;
+        },
+      });
+
+      await app.request("/add", { method: "POST", body: "{" });
+
+      expect(calls).toBe(0);
+    });
+
+    it("returns results unchanged by default", async () => {
+      const response = await post(createApp(), "/add");
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 5 });
+    });
+  });
+
+  describe("Integer reporting", () => {
+    const app = createApp({ reportIsInteger: true });
+
+    function add(target: ReturnType<typeof createApp>, a: number, b: number) {
+      return target.request("/add", {
+        method: "POST",
+        body: JSON.stringify({ a, b }),
+      });
+    }
+
+    it.each([
+      { a: 5, b: 5, expected: { result: 10, isInteger: true } },
+      { a: 2.5, b: 2.5, expected: { result: 5, isInteger: true } },
+      { a: 2.5, b: 0.25, expected: { result: 2.75, isInteger: false } },
+      { a: -0.5, b: 0, expected: { result: -0.5, isInteger: false } },
+    ])("reports $a + $b as $expected", async ({ a, b, expected }) => {
+      const response = await add(app, a, b);
+
+      expect(await response.json()).toEqual(expected);
+    });
+
+    it("judges the transformed result", async () => {
+      const halving = createApp({
+        reportIsInteger: true,
+        resultTransform: (_, result) => result / 2,
+      });
+
+      const response = await add(halving, 2, 3);
+
+      expect(await response.json()).toEqual({
+        result: 2.5,
+        isInteger: false,
+      });
+    });
+
+    it("keeps the exact value alongside", async () => {
+      const response = await app.request("/divide?exact=true", {
+        method: "POST",
+        body: JSON.stringify({ a: 1, b: 3 }),
+      });
+
+      expect(await response.json()).toEqual({
+        result: 1 / 3,
+        exact: "1/3",
+        isInteger: false,
+      });
+    });
+
+    it("is off by default", async () => {
+      const response = await add(createApp(), 5, 5);
+
+      expect(await response.json()).toEqual({ result: 10 });
+    });
+  });
+
+  describe("Exact division reporting", () => {
+    const app = createApp({ reportExactDivision: true });
+
+    function divide(
+      target: ReturnType<typeof createApp>,
+      a: number,
+      b: number,
+      query = ""
+    ) {
+      return target.request(`/divide${query}`, {
+        method: "POST",
+        body: JSON.stringify({ a, b }),
+      });
+    }
+
+    it("reports an exact quotient", async () => {
+      const response = await divide(app, 10, 2);
+
+      expect(await response.json()).toEqual({ result: 5, isExact: true });
+    });
+
+    it("reports an exact fractional quotient", async () => {
+      const response = await divide(app, 10, 4);
+
+      expect(await response.json()).toEqual({ result: 2.5, isExact: true });
+    });
+
+    it("reports a rounded quotient", async () => {
+      const response = await divide(app, 10, 3);
+
+      expect(await response.json()).toEqual({
+        result: 10 / 3,
+        isExact: false,
+      });
+    });
+
+    it("judges the quotient after ?places", async () => {
+      const rounded = await divide(app, 1, 4, "?places=1");
+      const kept = await divide(app, 1, 4, "?places=2");
+
+      expect(await rounded.json()).toEqual({ result: 0.2, isExact: false });
+      expect(await kept.json()).toEqual({ result: 0.25, isExact: true });
+    });
+
+    it("leaves exact mode unchanged", async () => {
+      const response = await divide(app, 1, 3, "?exact=true");
+
+      expect(await response.json()).toEqual({
+        result: 1 / 3,
+        exact: "1/3",
+      });
+    });
+
+    it("still rejects division by zero", async () => {
+      const response = await divide(app, 1, 0);
+
+      expect(response.status).toBe(400);
+    });
+
+    it("is off by default", async () => {
+      const response = await divide(createApp(), 10, 2);
+
+      expect(await response.json()).toEqual({ result: 5 });
+    });
+  });
+
+  describe("Audit log", () => {
+    it("logs sampled operations and every failure", async () => {
+      const entries: AuditEntry[] = [];
+      const app = createApp({
+        audit: { sampleRate: 2, log: (entry) => entries.push(entry) },
+      });
+      const add = (a: number, b: number) =>
+        app.request("/add", {
+          method: "POST",
+          body: JSON.stringify({ a, b }),
+        });
+
+      for (let i = 1; i <= 4; i++) {
+        await add(i, 1);
+      }
+      // Addition overflow is caught by the service rather than a validator.
+      await add(Number.MAX_VALUE, Number.MAX_VALUE);
+
+      expect(entries).toMatchObject([
+        { level: "info", operands: [1, 1], result: 2 },
+        { level: "info", operands: [3, 1], result: 4 },
+        { level: "warn", error: "invalid input: result overflowed" },
+      ]);
+    });
+  });
+
+  describe("Upstream proxy", () => {
+    function post(app: ReturnType<typeof createApp>, path: string) {
+      return app.fetch(
+        new Request(`http://localhost${path}`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ a: 2, b: 3 }),
+        })
+      );
+    }
+
+    it("computes /add on the upstream instance", async () => {
+      const upstream = createApp();
+      const proxy = createApp({
+        upstream: new CalculatorClient("http://upstream", (request) =>
+          upstream.fetch(request)
+        ),
+      });
+
+      const response = await post(proxy, "/add");
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5 });
+      const stats = await upstream.request("/stats");
+      const counts = await stats.json<StatsResponse>();
+      expect(counts.operations).toEqual({ add: 1 });
+    });
+
+    it("keeps other operations local", async () => {
+      let calls = 0;
+      const proxy = createApp({
+        upstream: new CalculatorClient("http://upstream", () => {
+          calls++;
+          return Promise.resolve(Response.json({ result: 0 }));
+        }),
+      });
+
+      const response = await post(proxy, "/multiply");
+
+      const json = await response.json();
+      expect(json).toEqual({ result: 6 });
+      expect(calls).toBe(0);
+    });
+
+    it("returns 502 when the upstream responds with an error", async () => {
+      const proxy = createApp({
+        upstream: new CalculatorClient("http://upstream", () =>
+          Promise.resolve(Response.json({ error: "boom" }, { status: 500 }))
+        ),
+      });
+
+      const response = await post(proxy, "/add");
+
+      expect(response.status).toBe(502);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Upstream calculator failed",
+        code: "upstream_error",
+        timestamp: expect.any(String),
+      });
+    });
+
+    it("returns 502 when the upstream cannot be reached", async () => {
+      const proxy = createApp({
+        upstream: new CalculatorClient("http://upstream", () =>
+          Promise.reject(new TypeError("Network connection lost"))
+        ),
+      });
+
+      const response = await post(proxy, "/add");
+
+      expect(response.status).toBe(502);
+    });
+
+    describe("circuit breaker", () => {
+      const clock = new FakeClock();
+      let upstreamUp = false;
+      let calls = 0;
+      const upstream = createApp();
+      const proxy = createApp({
+        clock,
+        upstream: new CalculatorClient("http://upstream", (request) => {
+          calls++;
+          return upstreamUp
+            ? upstream.fetch(request)
+            : Promise.reject(new TypeError("Network connection lost"));
+        }),
+        upstreamBreaker: { failureThreshold: 3, resetTimeoutMs: 10_000 },
+      });
+
+      it("opens after repeated upstream failures and fails fast", async () => {
+        for (let i = 0; i < 3; i++) {
+          expect((await post(proxy, "/add")).status).toBe(502);
+        }
+
+        const response = await post(proxy, "/add");
+
+        expect(response.status).toBe(503);
+        expect(await response.json()).toEqual({
+          error: "Upstream calculator unavailable",
+          code: "circuit_open",
+          timestamp: expect.any(String),
+        });
+        expect(calls).toBe(3);
+      });
+
+      it("keeps local operations working while open", async () => {
+        const response = await post(proxy, "/multiply");
+
+        expect(response.status).toBe(200);
+      });
+
+      it("recovers through a successful half-open trial", async () => {
+        upstreamUp = true;
+        clock.advance(10_000);
+
+        const trial = await post(proxy, "/add");
+        const after = await post(proxy, "/add");
+
+        expect(trial.status).toBe(200);
+        expect(await after.json()).toEqual({ result: 5 });
+        expect(calls).toBe(5);
+      });
+    });
+  });
+
+  describe("Route prefix", () => {
+    const prefixed = createApp({ prefix: "/api/v1" });
+
+    function post(path: string) {
+      return prefixed.fetch(
+        new Request(`http://localhost${path}`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ a: 2, b: 3 }),
+        })
+      );
+    }
+
+    it("serves routes under the prefix", async () => {
+      const response = await post("/api/v1/add");
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ result: 5 });
+    });
+
+    it("no longer serves routes at the root", async () => {
+      const response = await post("/add");
+
+      expect(response.status).toBe(404);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "not_found" });
+    });
+
+    it("prefixes method checks", async () => {
+      const response = await prefixed.fetch(
+        new Request("http://localhost/api/v1/add", { method: "PUT" })
+      );
+
+      expect(response.status).toBe(405);
+    });
+
+    it("ignores a trailing slash on the prefix", async () => {
+      const trailing = createApp({ prefix: "/api/v1/" });
+
+      const response = await trailing.fetch(
+        new Request("http://localhost/api/v1/health")
+      );
+
+      expect(response.status).toBe(200);
+    });
+  });
+
+  describe("Signed zero", () => {
+    it.each([
+      { path: "/subtract", body: '{"a":0,"b":0}' },
+      { path: "/subtract", body: '{"a":-0,"b":0}' },
+      { path: "/multiply", body: '{"a":-1,"b":0}' },
+      { path: "/divide", body: '{"a":0,"b":-5}' },
+      { path: "/sin", body: '{"a":-0}' },
+      { path: "/divide?places=2", body: '{"a":-1,"b":1000}' },
+    ])("$path $body gives positive zero", async ({ path, body }) => {
+      const received: number[] = [];
+      const app = createApp({
+        resultTransform: (_, result) => {
+          received.push(result);
+          return result;
+        },
+      });
+
+      const response = await app.request(path, { method: "POST", body });
+
+      expect(response.status).toBe(200);
+      expect(await response.text()).toBe('{"result":0}');
+      expect(Object.is(received[0], 0)).toBe(true);
+    });
+  });
+
+  describe("IEEE mode", () => {
+    const post = (path: string, body: unknown) =>
+      makeRequest(path, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+      });
+
+    it("returns Infinity as a string for 1/0", async () => {
+      const response = await post("/divide?ieee=true", { a: 1, b: 0 });
+
+      expect(response.status).toBe(200);
+      expect(await response.text()).toBe('{"result":"Infinity"}');
+    });
+
+    it.each([
+      { path: "/divide", body: { a: 0, b: 0 }, result: "NaN" },
+      { path: "/divide", body: { a: -1, b: 0 }, result: "-Infinity" },
+      { path: "/multiply", body: { a: 1e308, b: 10 }, result: "Infinity" },
+      { path: "/ln", body: { a: -1 }, result: "NaN" },
+      { path: "/add", body: { a: "Infinity", b: 1 }, result: "Infinity" },
+      { path: "/add", body: { a: "-Infinity", b: "Infinity" }, result: "NaN" },
+      { path: "/subtract", body: { a: 5, b: 2 }, result: 3 },
+    ])("returns $result for $path $body", async ({ path, body, result }) => {
+      const response = await post(`${path}?ieee=true`, body);
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({ result });
+    });
+
+    it("lists operands that are neither numbers nor IEEE names", async () => {
+      const response = await post("/add?ieee=true", { a: "inf" });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          {
+            field: "a",
+            message: 'must be a number or "NaN", "Infinity" or "-Infinity"',
+          },
+          { field: "b", message: "required" },
+        ],
+      });
+    });
+
+    it("keeps strict errors without the parameter", async () => {
+      const response = await post("/divide", { a: 1, b: 0 });
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("leaves operations without an IEEE definition strict", async () => {
+      const response = await post("/gcd?ieee=true", { a: 1.5, b: 2 });
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "invalid_input" });
+    });
+  });
+
+  describe("Response encoding", () => {
+    function post(accept: string, body: unknown) {
+      return makeRequest("/divide", {
+        method: "POST",
+        headers: { "Content-Type": "application/json", Accept: accept },
+        body: JSON.stringify(body),
+      });
+    }
+
+    it("round-trips an OperationResponse through MessagePack", async () => {
+      const response = await post("application/msgpack", { a: 1, b: 4 });
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("Content-Type")).toBe("application/msgpack");
+      expect(response.headers.get("Vary")).toBe("Accept");
+      const decoded = decodeMessagePack(await response.arrayBuffer());
+      expect(decoded).toEqual({ result: 0.25 });
+    });
+
+    it("encodes errors with the selected encoder", async () => {
+      const response = await post("application/msgpack", { a: 1, b: 0 });
+
+      expect(response.status).toBe(400);
+      expect(response.headers.get("Content-Type")).toBe("application/msgpack");
+      const decoded = decodeMessagePack(await response.arrayBuffer());
+      expect(decoded).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("returns the bare result as plain text", async () => {
+      const response = await post("text/plain", { a: 1, b: 4 });
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("Content-Type")).toBe("text/plain");
+      expect(await response.text()).toBe("0.25\n");
+    });
+
+    it("returns the error message as plain text", async () => {
+      const response = await post("text/plain", { a: 1, b: 0 });
+
+      expect(response.status).toBe(400);
+      expect(response.headers.get("Content-Type")).toBe("text/plain");
+      expect(await response.text()).toBe("invalid input: division by zero\n");
+    });
+
+    it("defaults to JSON for unsupported media types", async () => {
+      const response = await post("text/html", { a: 1, b: 4 });
+
+      expect(response.headers.get("Content-Type")).toContain(
+        "application/json"
+      );
+      expect(await response.json()).toEqual({ result: 0.25 });
+    });
+
+    it("replays MessagePack bodies intact", async () => {
+      const app = createApp();
+      const send = () =>
+        app.request("/multiply", {
+          method: "POST",
+          headers: {
+            Accept: "application/msgpack",
+            "Idempotency-Key": "msgpack-replay",
+          },
+          body: JSON.stringify({ a: 1.5, b: 3 }),
+        });
+
+      await send();
+      const replayed = await send();
+
+      expect(replayed.headers.get("Idempotent-Replayed")).toBe("true");
+      const decoded = decodeMessagePack(await replayed.arrayBuffer());
+      expect(decoded).toEqual({ result: 4.5 });
+    });
+
+    it("uses custom encoders", async () => {
+      const app = createApp({
+        encoders: [
+          {
+            contentType: "text/plain",
+            encode: (data) => `result=${(data as { result: number }).result}`,
+          },
+        ],
+      });
+
+      const response = await app.request("/add", {
+        method: "POST",
+        body: JSON.stringify({ a: 2, b: 3 }),
+      });
+
+      expect(response.headers.get("Content-Type")).toBe("text/plain");
+      expect(await response.text()).toBe("result=5");
+    });
+
+    it("writes canonical JSON when enabled", async () => {
+      const app = createApp({
+        canonicalJson: true,
+        clock: new FakeClock(new Date("2024-01-01T00:00:00.000Z")),
+      });
+      const send = () =>
+        app.request("/divide?envelope=true", {
+          method: "POST",
+          headers: { "X-Request-Id": "req-1" },
+          body: JSON.stringify({ a: 1, b: 0 }),
+        });
+
+      const first = await (await send()).text();
+      const second = await (await send()).text();
+
+      expect(first).toBe(
+        '{"error":{"code":"invalid_input",' +
+          '"error":"invalid input: division by zero",' +
+          '"timestamp":"2024-01-01T00:00:00.000Z"},' +
+          '"meta":{"durationMs":0,"requestId":"req-1"}}'
+      );
+      expect(second).toBe(first);
+    });
+  });
+
+  describe("response keys", () => {
+    it("writes result under the configured key", async () => {
+      const app = createApp({ responseKeys: { result: "value" } });
+
+      const response = await app.request("/add", {
+        method: "POST",
+        body: JSON.stringify({ a: 2, b: 3 }),
+      });
+
+      expect(response.status).toBe(200);
+      expect(await response.text()).toBe('{"value":5}');
+    });
+
+    it("renames inside the envelope's data", async () => {
+      const app = createApp({ responseKeys: { result: "value" } });
+
+      const response = await app.request("/add?envelope=true", {
+        method: "POST",
+        body: JSON.stringify({ a: 2, b: 3 }),
+      });
+
+      expect((await response.json()).data).toEqual({ value: 5 });
+    });
+
+    it("keeps result by default", async () => {
+      const response = await makeRequest("/add", {
+        method: "POST",
+        body: JSON.stringify({ a: 2, b: 3 }),
+      });
+
+      expect(await response.text()).toBe('{"result":5}');
+    });
+  });
+
+  describe("significant digits", () => {
+    const sum = (target: ReturnType<typeof createApp>) =>
+      target.request("/add", {
+        method: "POST",
+        body: JSON.stringify({ a: 0.1, b: 0.2 }),
+      });
+
+    it("writes results in full by default", async () => {
+      const response = await sum(createApp());
+
+      expect(await response.text()).toBe('{"result":0.30000000000000004}');
+    });
+
+    it("writes results to the configured significant digits", async () => {
+      const app = createApp({ significantDigits: 6 });
+
+      const response = await sum(app);
+
+      expect(await response.text()).toBe('{"result":0.3}');
+    });
+
+    it("rounds before renaming the result", async () => {
+      const app = createApp({
+        significantDigits: 6,
+        responseKeys: { result: "value" },
+      });
+
+      const response = await sum(app);
+
+      expect(await response.text()).toBe('{"value":0.3}');
+    });
+  });
+
+  describe("404 Not Found", () => {
+    it("returns 404 for unknown endpoints", async () => {
+      const response = await makeRequest("/unknown", { method: "GET" });
+
+      expect(response.status).toBe(404);
+      const json = await response.json();
+      expect(json).toEqual({
+        error: "Not found",
+        code: "not_found",
+        timestamp: expect.any(String),
+      });
     });
   });
 
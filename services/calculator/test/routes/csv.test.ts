@@ -0,0 +1,208 @@
+import { describe, it, expect } from "vitest";
+import app, { createApp } from "../../src/index";
+import type { AppOptions } from "../../src/index";
+import { MAX_CSV_ROWS } from "../../src/routes/csv";
+import { FakeCalculator } from "../../src/services/fake";
+
+function postCsv(path: string, body: string) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, {
+      method: "POST",
+      headers: { "Content-Type": "text/csv" },
+      body,
+    })
+  );
+}
+
+describe("CSV Routes", () => {
+  describe("POST /add/csv", () => {
+    it("computes each row", async () => {
+      const response = await postCsv("/add/csv", "1,2\n3.5,-1\n");
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("content-type")).toContain("text/csv");
+      expect(await response.text()).toBe(
+        "a,b,result,error\r\n1,2,3,\r\n3.5,-1,2.5,\r\n"
+      );
+    });
+
+    it("skips a header row", async () => {
+      const response = await postCsv("/add/csv", "a,b\n1,2\n");
+
+      expect(await response.text()).toBe("a,b,result,error\r\n1,2,3,\r\n");
+    });
+
+    it("reports malformed rows without aborting the file", async () => {
+      const response = await postCsv(
+        "/add/csv",
+        "a,b\n1,2\nthree,4\n5\n1e999,1\n6,7\n"
+      );
+
+      expect(response.status).toBe(200);
+      expect(await response.text()).toBe(
+        [
+          "a,b,result,error",
+          "1,2,3,",
+          "three,4,,invalid number",
+          '5,,,"expected 2 columns, got 1"',
+          "1e999,1,,invalid input: NaN and Infinity not allowed",
+          "6,7,13,",
+          "",
+        ].join("\r\n")
+      );
+    });
+
+    it("rejects cells that are not decimal numbers", async () => {
+      const response = await postCsv(
+        "/add/csv",
+        "1,2\n ,4\n0x10,1\n1,Infinity\n 1e3 , 2 \n"
+      );
+
+      expect(await response.text()).toBe(
+        [
+          "a,b,result,error",
+          "1,2,3,",
+          " ,4,,invalid number",
+          "0x10,1,,invalid number",
+          "1,Infinity,,invalid number",
+          " 1e3 , 2 ,1002,",
+          "",
+        ].join("\r\n")
+      );
+    });
+
+    it("returns only the header for an empty body", async () => {
+      const response = await postCsv("/add/csv", "");
+
+      expect(await response.text()).toBe("a,b,result,error\r\n");
+    });
+
+    it("returns 405 for GET method", async () => {
+      const response = await app.fetch(new Request("http://localhost/add/csv"));
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("POST /divide/csv", () => {
+    it("reports division by zero in the row", async () => {
+      const response = await postCsv("/divide/csv", "1,4\n1,0\n");
+
+      expect(await response.text()).toBe(
+        [
+          "a,b,result,error",
+          "1,4,0.25,",
+          "1,0,,invalid input: division by zero",
+          "",
+        ].join("\r\n")
+      );
+    });
+  });
+
+  describe("other operations", () => {
+    it.each([
+      { path: "/subtract/csv", expected: "5,3,2," },
+      { path: "/multiply/csv", expected: "5,3,15," },
+      { path: "/hypot/csv", expected: "3,4,5," },
+    ])("$path computes rows", async ({ path, expected }) => {
+      const [a, b] = expected.split(",");
+      const response = await postCsv(path, `${a},${b}\n`);
+
+      expect(await response.text()).toBe(
+        `a,b,result,error\r\n${expected}\r\n`
+      );
+    });
+  });
+
+  describe("row limit", () => {
+    const rows = (count: number) => "1,2\n".repeat(count);
+
+    it(`computes ${MAX_CSV_ROWS} rows after a header`, async () => {
+      const response = await postCsv("/add/csv", "a,b\n" + rows(MAX_CSV_ROWS));
+
+      expect(response.status).toBe(200);
+      expect((await response.text()).split("\r\n")).toHaveLength(
+        MAX_CSV_ROWS + 2
+      );
+    });
+
+    it("rejects a body with more rows", async () => {
+      const response = await postCsv("/add/csv", rows(MAX_CSV_ROWS + 1));
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        error: `CSV body must have at most ${MAX_CSV_ROWS} rows`,
+        code: "invalid_request",
+      });
+    });
+  });
+
+  describe("unexpected errors", () => {
+    function failingApp(options: AppOptions = {}) {
+      return createApp({
+        ...options,
+        service: new FakeCalculator().throws("add", new TypeError("boom")),
+      });
+    }
+
+    function post(target: ReturnType<typeof createApp>, body: string) {
+      return target.request("/add/csv", {
+        method: "POST",
+        headers: { "Content-Type": "text/csv" },
+        body,
+      });
+    }
+
+    it("reports the row and computes the rest", async () => {
+      const response = await post(failingApp(), "1,2\n");
+
+      expect(response.status).toBe(200);
+      expect(await response.text()).toBe(
+        "a,b,result,error\r\n1,2,,Invalid request\r\n"
+      );
+    });
+
+    it("fails with 500 under the panic policy", async () => {
+      const log = console.error;
+      console.error = () => {};
+      try {
+        const response = await post(failingApp({ onError: "panic" }), "1,2\n");
+
+        expect(response.status).toBe(500);
+        expect(await response.json()).toMatchObject({ code: "internal_error" });
+      } finally {
+        console.error = log;
+      }
+    });
+  });
+
+  describe("strict content type", () => {
+    const strict = createApp({ strictContentType: true });
+
+    it("accepts text/csv", async () => {
+      const response = await strict.fetch(
+        new Request("http://localhost/add/csv", {
+          method: "POST",
+          headers: { "Content-Type": "text/csv; charset=utf-8" },
+          body: "1,2\n",
+        })
+      );
+
+      expect(response.status).toBe(200);
+    });
+
+    it("rejects JSON", async () => {
+      const response = await strict.fetch(
+        new Request("http://localhost/add/csv", {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: "1,2\n",
+        })
+      );
+
+      expect(response.status).toBe(415);
+      const json = await response.json();
+      expect(json).toMatchObject({ error: "Content-Type must be text/csv" });
+    });
+  });
+});
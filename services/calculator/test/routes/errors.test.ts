@@ -0,0 +1,272 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import type { AppOptions } from "../../src/index";
+import { FakeCalculator } from "../../src/services/fake";
+import {
+  DivisionByZeroError,
+  InvalidInputError,
+  NonIntegerError,
+  OverflowError,
+} from "../../src/services/calculator";
+import { CircuitOpenError } from "../../src/services/breaker";
+import { UpstreamError } from "../../src/services/proxy";
+import {
+  describeError,
+  RequestValidationError,
+  statusForError,
+} from "../../src/routes/errors";
+import { EmptyBodyError } from "../../src/routes/request";
+
+describe("describeError", () => {
+  it.each([
+    {
+      name: "InvalidInputError",
+      error: new InvalidInputError(),
+      status: 400,
+      code: "invalid_input",
+      message: "invalid input: NaN and Infinity not allowed",
+    },
+    {
+      name: "DivisionByZeroError",
+      error: new DivisionByZeroError(),
+      status: 400,
+      code: "invalid_input",
+      message: "invalid input: division by zero",
+    },
+    {
+      name: "OverflowError",
+      error: new OverflowError(),
+      status: 400,
+      code: "invalid_input",
+      message: "invalid input: result overflowed",
+    },
+    {
+      name: "NonIntegerError",
+      error: new NonIntegerError(),
+      status: 400,
+      code: "invalid_input",
+      message: "invalid input: operands must be integers",
+    },
+    {
+      name: "RequestValidationError",
+      error: new RequestValidationError([{ field: "a", message: "required" }]),
+      status: 400,
+      code: "invalid_request",
+      message: "Invalid request",
+    },
+    {
+      name: "EmptyBodyError",
+      error: new EmptyBodyError(),
+      status: 400,
+      code: "empty_body",
+      message: "Request body is empty",
+    },
+    {
+      name: "SyntaxError",
+      error: new SyntaxError("Unexpected token"),
+      status: 400,
+      code: "malformed_json",
+      message: "Malformed JSON",
+    },
+    {
+      name: "UpstreamError",
+      error: new UpstreamError(new Error("connection refused")),
+      status: 502,
+      code: "upstream_error",
+      message: "Upstream calculator failed",
+    },
+    {
+      name: "CircuitOpenError",
+      error: new CircuitOpenError(),
+      status: 503,
+      code: "circuit_open",
+      message: "Upstream calculator unavailable",
+    },
+    {
+      name: "a timeout",
+      error: new DOMException("The operation timed out.", "TimeoutError"),
+      status: 503,
+      code: "timeout",
+      message: "Request timed out",
+    },
+    {
+      name: "an unrecognised error",
+      error: new Error("boom"),
+      status: 400,
+      code: "invalid_request",
+      message: "Invalid request",
+    },
+  ])("maps $name to $status $code", ({ error, status, code, message }) => {
+    expect(describeError(error)).toEqual({ status, code, message });
+    expect(statusForError(error)).toBe(status);
+  });
+});
+
+describe("error policy", () => {
+  // An app whose add fails with error, and the messages it logs with
+  // console.error while handling a request to path.
+  async function addFailingWith(
+    error: unknown,
+    options: AppOptions = {},
+    body: unknown = { a: 2, b: 3 },
+    path = "/add"
+  ) {
+    const app = createApp({
+      ...options,
+      service: new FakeCalculator().throws("add", error),
+    });
+    const logged: unknown[][] = [];
+    const log = console.error;
+    console.error = (...args: unknown[]) => logged.push(args);
+    try {
+      const response = await app.request(path, {
+        method: "POST",
+        body: JSON.stringify(body),
+      });
+      return { response, logged };
+    } finally {
+      console.error = log;
+    }
+  }
+
+  const batchBody = {
+    operations: [
+      { operation: "multiply", a: 2, b: 3 },
+      { operation: "add", a: 2, b: 3 },
+    ],
+  };
+
+  describe("respond", () => {
+    it("is the default", async () => {
+      const { response, logged } = await addFailingWith(
+        new TypeError("boom")
+      );
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "invalid_request" });
+      expect(logged).toEqual([]);
+    });
+
+    it("reports domain errors as usual", async () => {
+      const { response } = await addFailingWith(new InvalidInputError(), {
+        onError: "respond",
+      });
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("reports an unexpected error in a batch as the item's reply", async () => {
+      const { response } = await addFailingWith(
+        new TypeError("boom"),
+        {},
+        batchBody,
+        "/batch"
+      );
+
+      expect(response.status).toBe(207);
+      expect(await response.json()).toMatchObject({
+        results: [{ result: 6 }, { code: "invalid_request" }],
+      });
+    });
+  });
+
+  describe("panic", () => {
+    const options: AppOptions = { onError: "panic" };
+
+    it("fails with 500 and logs the stack of an unexpected error", async () => {
+      const error = new TypeError("boom");
+
+      const { response, logged } = await addFailingWith(error, options);
+
+      expect(response.status).toBe(500);
+      expect(await response.json()).toMatchObject({
+        error: "Internal server error",
+        code: "internal_error",
+      });
+      expect(logged).toEqual([["Unhandled error:", error.stack]]);
+      expect(error.stack).toContain("TypeError: boom");
+    });
+
+    it("reports domain errors as usual", async () => {
+      const { response, logged } = await addFailingWith(
+        new OverflowError(),
+        options
+      );
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        error: "invalid input: result overflowed",
+        code: "invalid_input",
+      });
+      expect(logged).toEqual([]);
+    });
+
+    it("reports validation errors as usual", async () => {
+      const { response } = await addFailingWith(
+        new TypeError("boom"),
+        options,
+        { a: 2 }
+      );
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "b", message: "required" }],
+      });
+    });
+
+    it("reports a malformed streamed list as usual", async () => {
+      const { response } = await addFailingWith(
+        new TypeError("boom"),
+        options,
+        { numbers: 5 },
+        "/sum-list/sse"
+      );
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "numbers", message: "must be an array" }],
+      });
+    });
+
+    it("reports upstream failures as usual", async () => {
+      const { response } = await addFailingWith(
+        new UpstreamError(new Error("connection refused")),
+        options
+      );
+
+      expect(response.status).toBe(502);
+    });
+
+    it("fails a batch with 500 on an unexpected error", async () => {
+      const error = new TypeError("boom");
+
+      const { response, logged } = await addFailingWith(
+        error,
+        options,
+        batchBody,
+        "/batch"
+      );
+
+      expect(response.status).toBe(500);
+      expect(await response.json()).toMatchObject({ code: "internal_error" });
+      expect(logged).toEqual([["Unhandled error:", error.stack]]);
+    });
+
+    it("reports domain errors in a batch as usual", async () => {
+      const { response } = await addFailingWith(
+        new OverflowError(),
+        options,
+        batchBody,
+        "/batch"
+      );
+
+      expect(response.status).toBe(207);
+      expect(await response.json()).toMatchObject({
+        results: [{ result: 6 }, { code: "invalid_input" }],
+      });
+    });
+  });
+});
@@ -0,0 +1,77 @@
+import { describe, it, expect } from "vitest";
+import { CalculatorClient } from "../../src/client";
+import app, { createApp } from "../../src/index";
+
+const node = createApp();
+
+describe("Fleet Routes", () => {
+  describe("GET /health/fleet", () => {
+    it("returns ok when every node is up", async () => {
+      const fleet = createApp({
+        fleet: {
+          nodes: [
+            new CalculatorClient("http://node-1", (r) => node.fetch(r)),
+            new CalculatorClient("http://node-2", (r) => node.fetch(r)),
+          ],
+        },
+      });
+
+      const response = await fleet.request("/health/fleet");
+
+      expect(response.status).toBe(200);
+      expect(await response.json()).toEqual({
+        status: "ok",
+        nodes: [
+          { url: "http://node-1", status: "ok", latencyMs: expect.any(Number) },
+          { url: "http://node-2", status: "ok", latencyMs: expect.any(Number) },
+        ],
+      });
+    });
+
+    it("returns 503 degraded when a node is down", async () => {
+      const degraded = createApp({
+        fleet: {
+          nodes: [
+            new CalculatorClient("http://node-1", (r) => node.fetch(r)),
+            new CalculatorClient("http://node-2", () =>
+              Promise.resolve(
+                Response.json(
+                  { error: "Service unavailable", code: "degraded" },
+                  { status: 503 }
+                )
+              )
+            ),
+          ],
+        },
+      });
+
+      const response = await degraded.request("/health/fleet");
+
+      expect(response.status).toBe(503);
+      expect(await response.json()).toEqual({
+        status: "degraded",
+        nodes: [
+          { url: "http://node-1", status: "ok", latencyMs: expect.any(Number) },
+          {
+            url: "http://node-2",
+            status: "down",
+            latencyMs: expect.any(Number),
+            error: "Service unavailable",
+          },
+        ],
+      });
+    });
+
+    it("returns 404 when no nodes are configured", async () => {
+      const response = await app.request("/health/fleet");
+
+      expect(response.status).toBe(404);
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.request("/health/fleet", { method: "POST" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
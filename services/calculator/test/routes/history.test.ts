@@ -0,0 +1,130 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import type { HistoryResponse } from "../../src/types";
+
+function post(app: ReturnType<typeof createApp>, path: string, body: unknown) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+    })
+  );
+}
+
+async function getHistory(app: ReturnType<typeof createApp>) {
+  const response = await app.fetch(new Request("http://localhost/history"));
+  expect(response.status).toBe(200);
+  return (await response.json()) as HistoryResponse;
+}
+
+describe("History Routes", () => {
+  describe("GET /history", () => {
+    it("returns an empty list before any operation", async () => {
+      const app = createApp();
+
+      expect(await getHistory(app)).toEqual({ entries: [] });
+    });
+
+    it("keeps the last operations, newest first", async () => {
+      const app = createApp({ history: { size: 3 } });
+
+      for (let a = 1; a <= 5; a++) {
+        await post(app, "/add", { a, b: 10 });
+      }
+
+      const { entries } = await getHistory(app);
+      expect(entries).toMatchObject([
+        { operation: "add", operands: [5, 10], result: 15 },
+        { operation: "add", operands: [4, 10], result: 14 },
+        { operation: "add", operands: [3, 10], result: 13 },
+      ]);
+      expect(typeof entries[0].timestamp).toBe("string");
+    });
+
+    it("records every figure of divmod", async () => {
+      const app = createApp();
+
+      await post(app, "/divmod", { a: -7, b: 2 });
+
+      expect((await getHistory(app)).entries).toMatchObject([
+        {
+          operation: "divmod",
+          operands: [-7, 2],
+          result: { quotient: -3, remainder: -1 },
+        },
+      ]);
+    });
+
+    it("records factorial both exactly and as a float", async () => {
+      const app = createApp();
+
+      await post(app, "/factorial", { a: 5 });
+
+      expect((await getHistory(app)).entries).toMatchObject([
+        {
+          operation: "factorial",
+          operands: [5],
+          result: { exact: "120", result: 120 },
+        },
+      ]);
+    });
+
+    it("records every figure of a summary", async () => {
+      const app = createApp();
+
+      await post(app, "/stats/summary", { numbers: [1, 3] });
+
+      expect((await getHistory(app)).entries).toMatchObject([
+        {
+          operation: "summary",
+          operands: [1, 3],
+          result: { count: 2, mean: 2, variance: 1, min: 1, max: 3 },
+        },
+      ]);
+    });
+
+    it("records approximate equality", async () => {
+      const app = createApp();
+
+      await post(app, "/approx-equal", { a: 1, b: 1.05, epsilon: 0.1 });
+
+      expect((await getHistory(app)).entries).toMatchObject([
+        { operation: "approxEqual", operands: [1, 1.05, 0.1], result: true },
+      ]);
+    });
+
+    it("records rounding", async () => {
+      const app = createApp();
+
+      await post(app, "/round", { value: 2.5, places: 0, mode: "half_up" });
+
+      expect((await getHistory(app)).entries).toMatchObject([
+        { operation: "round", operands: [2.5, 0], result: 3 },
+      ]);
+    });
+
+    it("records errors only when configured to", async () => {
+      // Addition overflow is caught by the service rather than a validator.
+      const overflow = { a: Number.MAX_VALUE, b: Number.MAX_VALUE };
+      const quiet = createApp();
+      const verbose = createApp({ history: { includeErrors: true } });
+
+      await post(quiet, "/add", overflow);
+      await post(verbose, "/add", overflow);
+
+      expect(await getHistory(quiet)).toEqual({ entries: [] });
+      expect((await getHistory(verbose)).entries).toMatchObject([
+        { operation: "add", error: "invalid input: result overflowed" },
+      ]);
+    });
+
+    it("rejects other methods with 405", async () => {
+      const app = createApp();
+
+      const response = await post(app, "/history", {});
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
@@ -0,0 +1,136 @@
+import { describe, it, expect } from "vitest";
+import app, { createApp } from "../../src/index";
+import { isValidCallback } from "../../src/routes/jsonp";
+
+function get(query: string) {
+  return app.fetch(new Request(`http://localhost/add/jsonp?${query}`));
+}
+
+describe("JSONP Routes", () => {
+  describe("GET /add/jsonp", () => {
+    it("wraps the result in the callback", async () => {
+      const response = await get("a=2&b=3&callback=handleResult");
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("content-type")).toContain(
+        "application/javascript"
+      );
+      expect(response.headers.get("x-content-type-options")).toBe("nosniff");
+      expect(await response.text()).toBe('/**/handleResult({"result":5});');
+    });
+
+    it("accepts a dotted callback name", async () => {
+      const response = await get("a=1.5&b=-4&callback=widget.onResult");
+
+      expect(await response.text()).toBe(
+        '/**/widget.onResult({"result":-2.5});'
+      );
+    });
+
+    it("returns plain JSON without a callback", async () => {
+      const response = await get("a=2&b=3");
+
+      expect(response.headers.get("content-type")).toContain(
+        "application/json"
+      );
+      expect(await response.json()).toEqual({ result: 5 });
+    });
+
+    it("rejects a malicious callback name", async () => {
+      const callback = encodeURIComponent("alert(document.cookie);cb");
+      const response = await get(`a=2&b=3&callback=${callback}`);
+
+      expect(response.status).toBe(400);
+      expect(response.headers.get("content-type")).toContain(
+        "application/json"
+      );
+      const json = await response.json();
+      expect(json).toMatchObject({
+        error: "Invalid callback name",
+        code: "invalid_callback",
+      });
+    });
+
+    it("wraps errors in the callback", async () => {
+      const response = await get("a=2&callback=cb");
+
+      expect(response.status).toBe(400);
+      const text = await response.text();
+      expect(text).toMatch(/^\/\*\*\/cb\(\{.*\}\);$/);
+      expect(text).toContain('"code":"invalid_request"');
+    });
+
+    it("rejects non-finite operands", async () => {
+      const response = await get("a=Infinity&b=1");
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("rejects non-numeric operands", async () => {
+      const response = await get("a=two&b=1");
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "a", message: "must be a number" }],
+      });
+    });
+
+    it("lists each missing operand", async () => {
+      const response = await get("b=");
+
+      expect(await response.json()).toMatchObject({
+        code: "invalid_request",
+        errors: [
+          { field: "a", message: "required" },
+          { field: "b", message: "must be a number" },
+        ],
+      });
+    });
+
+    it("answers a bad operand with 400 under the panic policy", async () => {
+      const panicking = createApp({ onError: "panic" });
+
+      const response = await panicking.request("/add/jsonp?a=x&b=1");
+
+      expect(response.status).toBe(400);
+      expect(await response.json()).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "a", message: "must be a number" }],
+      });
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.fetch(
+        new Request("http://localhost/add/jsonp?a=1&b=2", { method: "POST" })
+      );
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  describe("isValidCallback", () => {
+    it.each(["cb", "_cb", "$", "jQuery123_456", "a.b.c"])(
+      "accepts %s",
+      (name) => {
+        expect(isValidCallback(name)).toBe(true);
+      }
+    );
+
+    it.each([
+      "",
+      "1cb",
+      "cb()",
+      "a..b",
+      "a.",
+      "cb;alert(1)",
+      "<script>",
+      "a".repeat(129),
+    ])("rejects %s", (name) => {
+      expect(isValidCallback(name)).toBe(false);
+    });
+  });
+});
@@ -0,0 +1,94 @@
+import { describe, it, expect } from "vitest";
+import app, { createApp } from "../../src/index";
+import {
+  createDefaultOperationRegistry,
+  OperationRegistry,
+} from "../../src/services/metadata";
+import type { OperationDescriptionResponse } from "../../src/types";
+
+describe("Operation Routes", () => {
+  describe("GET /operations/{name}/describe", () => {
+    it("describes divide with its zero-divisor constraint", async () => {
+      const response = await app.request("/operations/divide/describe");
+
+      expect(response.status).toBe(200);
+      const json = await response.json<OperationDescriptionResponse>();
+      expect(json).toMatchObject({
+        name: "divide",
+        path: "/divide",
+        arity: 2,
+        operands: ["a", "b"],
+      });
+      expect(json.constraints).toContain(
+        "b must not be zero (division by zero)"
+      );
+    });
+
+    it("reports list operations as variadic", async () => {
+      const response = await app.request("/operations/addMany/describe");
+
+      const json = await response.json<OperationDescriptionResponse>();
+      expect(json.arity).toBe("variadic");
+      expect(json.operands).toEqual(["numbers"]);
+    });
+
+    it("returns 404 for an unknown operation", async () => {
+      const response = await app.request("/operations/sqrt/describe");
+
+      expect(response.status).toBe(404);
+      expect(await response.json()).toMatchObject({
+        code: "not_found",
+        error: 'Unknown operation "sqrt"',
+      });
+    });
+
+    it("serves a custom registry", async () => {
+      const custom = createApp({
+        operations: new OperationRegistry().register({
+          name: "double",
+          path: "/double",
+          description: "Twice a",
+          arity: 1,
+          operands: ["a"],
+          constraints: [],
+          example: { request: { a: 2 }, response: { result: 4 } },
+        }),
+      });
+
+      const found = await custom.request("/operations/double/describe");
+      const missing = await custom.request("/operations/add/describe");
+
+      expect(found.status).toBe(200);
+      expect(missing.status).toBe(404);
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.request("/operations/add/describe", {
+        method: "POST",
+      });
+
+      expect(response.status).toBe(405);
+    });
+  });
+
+  // The examples are documentation clients rely on, so each must be what
+  // its endpoint really answers.
+  describe("examples", () => {
+    const registry = createDefaultOperationRegistry();
+
+    it.each(registry.names())(
+      "%s example matches its endpoint",
+      async (name) => {
+        const { path, example } = registry.describe(name)!;
+
+        const response = await app.request(path, {
+          method: "POST",
+          body: JSON.stringify(example.request),
+        });
+
+        expect(response.status).toBe(200);
+        expect(await response.json()).toEqual(example.response);
+      }
+    );
+  });
+});
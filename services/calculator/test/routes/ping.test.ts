@@ -0,0 +1,61 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import type { PingResponse } from "../../src/types";
+
+describe("Ping Routes", () => {
+  describe("GET /ping", () => {
+    const app = createApp();
+
+    it("echoes the nonce with a recent server time", async () => {
+      const before = Date.now();
+
+      const response = await app.request("/ping?nonce=abc123");
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("Cache-Control")).toBe("no-store");
+      const json = await response.json<PingResponse>();
+      expect(json.nonce).toBe("abc123");
+      const serverTime = Date.parse(json.serverTime);
+      expect(serverTime).toBeGreaterThanOrEqual(before);
+      expect(serverTime).toBeLessThanOrEqual(Date.now());
+    });
+
+    it("takes the server time from the app clock", async () => {
+      const clock = new FakeClock(new Date("2024-01-02T03:04:05.000Z"));
+
+      const response = await createApp({ clock }).request("/ping");
+
+      const json = await response.json<PingResponse>();
+      expect(json).toEqual({
+        nonce: null,
+        serverTime: "2024-01-02T03:04:05.000Z",
+        instanceId: expect.any(String),
+      });
+    });
+
+    it("reports the same instance on every ping", async () => {
+      const first = await (await app.request("/ping")).json<PingResponse>();
+      const second = await (await app.request("/ping")).json<PingResponse>();
+
+      expect(second.instanceId).toBe(first.instanceId);
+    });
+
+    it("returns 400 for a nonce over 128 characters", async () => {
+      const response = await app.request(`/ping?nonce=${"x".repeat(129)}`);
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({
+        code: "invalid_request",
+        errors: [{ field: "nonce", message: "must be at most 128 characters" }],
+      });
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.request("/ping", { method: "POST" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
@@ -0,0 +1,88 @@
+import { describe, it, expect } from "vitest";
+import app, { createApp } from "../../src/index";
+import type { ReadinessResponse } from "../../src/types";
+
+const sleep = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
+
+describe("Readiness Routes", () => {
+  describe("GET /readyz", () => {
+    it("returns ok when every check passes", async () => {
+      const response = await app.request("/readyz");
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({ status: "ok" });
+    });
+
+    it("lists each check with its latency when verbose", async () => {
+      const response = await app.request("/readyz?verbose=true");
+
+      const json = await response.json();
+      expect(json).toEqual({
+        status: "ok",
+        checks: [
+          { name: "arithmetic", status: "ok", latencyMs: expect.any(Number) },
+        ],
+      });
+    });
+
+    it("reports the latency of a slow check", async () => {
+      const slow = createApp({
+        readinessChecks: [{ name: "slow", check: () => sleep(50) }],
+      });
+
+      const response = await slow.request("/readyz?verbose=true");
+
+      expect(response.status).toBe(200);
+      const json = await response.json<ReadinessResponse>();
+      expect(json.checks?.[0].name).toBe("slow");
+      expect(json.checks?.[0].latencyMs).toBeGreaterThanOrEqual(40);
+    });
+
+    it("returns 503 when a check fails", async () => {
+      const failing = createApp({
+        readinessChecks: [
+          {
+            name: "upstream",
+            check: () => {
+              throw new Error("unreachable");
+            },
+          },
+        ],
+      });
+
+      const response = await failing.request("/readyz?verbose=true");
+
+      expect(response.status).toBe(503);
+      const json = await response.json();
+      expect(json).toEqual({
+        status: "unavailable",
+        checks: [
+          {
+            name: "upstream",
+            status: "failing",
+            latencyMs: expect.any(Number),
+            error: "unreachable",
+          },
+        ],
+      });
+    });
+
+    it("fails a check that outlives the request deadline", async () => {
+      const hung = createApp({
+        requestTimeoutMs: 10,
+        readinessChecks: [{ name: "hung", check: () => new Promise(() => {}) }],
+      });
+
+      const response = await hung.request("/readyz");
+
+      expect(response.status).toBe(503);
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.request("/readyz", { method: "POST" });
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
@@ -0,0 +1,96 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { REQUEST_SCHEMAS } from "../../src/routes/schema";
+import type {
+  SchemaListResponse,
+  ValidationErrorResponse,
+} from "../../src/types";
+
+const app = createApp();
+
+describe("GET /schema/:name", () => {
+  it("declares a and b as required numbers in operation-request", async () => {
+    const response = await app.request("/schema/operation-request");
+
+    expect(response.status).toBe(200);
+    expect(response.headers.get("Content-Type")).toBe(
+      "application/schema+json"
+    );
+    const json = await response.json();
+    expect(json).toMatchObject({
+      $schema: "https://json-schema.org/draft/2020-12/schema",
+      type: "object",
+      required: ["a", "b"],
+      properties: { a: { type: "number" }, b: { type: "number" } },
+    });
+  });
+
+  it("lists optional fields as properties but not as required", async () => {
+    const response = await app.request("/schema/round-request");
+
+    const json = await response.json<{
+      required: string[];
+      properties: Record<string, unknown>;
+    }>();
+    expect(json.required).toEqual(["value", "places"]);
+    expect(Object.keys(json.properties)).toContain("mode");
+  });
+
+  it("returns 404 for an unknown schema", async () => {
+    const response = await app.request("/schema/nope");
+
+    expect(response.status).toBe(404);
+    const json = await response.json();
+    expect(json).toMatchObject({ code: "not_found" });
+  });
+
+  it("returns 405 for POST method", async () => {
+    const response = await app.request("/schema/operation-request", {
+      method: "POST",
+    });
+
+    expect(response.status).toBe(405);
+  });
+});
+
+describe("GET /schema", () => {
+  it("lists every schema", async () => {
+    const response = await app.request("/schema");
+
+    const json = await response.json<SchemaListResponse>();
+    expect(json.schemas).toEqual(Object.keys(REQUEST_SCHEMAS));
+  });
+});
+
+describe("REQUEST_SCHEMAS", () => {
+  // Posting {} must report exactly the schema's required fields as missing,
+  // which keeps the schemas in step with the route parsers.
+  it.each([
+    { name: "operation-request", path: "/add" },
+    { name: "unary-operation-request", path: "/sin" },
+    { name: "number-list-request", path: "/add/many" },
+    { name: "weighted-sum-request", path: "/weighted-sum" },
+    { name: "convert-request", path: "/convert" },
+    { name: "fma-request", path: "/fma" },
+    { name: "clamp-request", path: "/clamp" },
+    { name: "polynomial-request", path: "/polynomial" },
+    { name: "round-request", path: "/round" },
+    { name: "rpn-request", path: "/calc/reverse-polish" },
+    { name: "batch-request", path: "/batch" },
+  ])(
+    "$name requires the fields $path reports missing",
+    async ({ name, path }) => {
+      const response = await app.request(path, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: "{}",
+      });
+
+      const json = await response.json<ValidationErrorResponse>();
+      const missing = json.errors
+        .filter((error) => error.message === "required")
+        .map((error) => error.field);
+      expect(missing).toEqual(REQUEST_SCHEMAS[name].required);
+    }
+  );
+});
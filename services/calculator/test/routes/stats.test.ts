@@ -0,0 +1,73 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { FakeClock } from "../../src/services/clock";
+import type { StatsResponse } from "../../src/types";
+
+function post(app: ReturnType<typeof createApp>, path: string, body: unknown) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+    })
+  );
+}
+
+describe("Stats Routes", () => {
+  describe("GET /stats", () => {
+    it("summarizes a mix of operations", async () => {
+      const clock = new FakeClock();
+      const app = createApp({ clock });
+
+      await post(app, "/add", { a: 1, b: 2 });
+      await post(app, "/add", { a: 3, b: 4 });
+      await post(app, "/multiply", { a: 2, b: 5 });
+      await post(app, "/ln", { a: -1 });
+      clock.advance(1500);
+
+      const response = await app.fetch(new Request("http://localhost/stats"));
+
+      expect(response.status).toBe(200);
+      const json = await response.json();
+      expect(json).toEqual({
+        total: 4,
+        errors: 1,
+        operations: { add: 2, multiply: 1, ln: 1 },
+        uptimeSeconds: 1.5,
+      });
+    });
+
+    it("reports positive uptime", async () => {
+      const clock = new FakeClock();
+      const app = createApp({ clock });
+
+      await post(app, "/sin", { a: 0 });
+      clock.advance(10);
+
+      const response = await app.fetch(new Request("http://localhost/stats"));
+      const json = await response.json<StatsResponse>();
+      expect(json.uptimeSeconds).toBeGreaterThan(0);
+    });
+
+    it("keeps separate counts per app", async () => {
+      const first = createApp({ clock: new FakeClock() });
+      const second = createApp({ clock: new FakeClock() });
+
+      await post(first, "/add", { a: 1, b: 2 });
+
+      const response = await second.fetch(
+        new Request("http://localhost/stats")
+      );
+      const json = await response.json<StatsResponse>();
+      expect(json.total).toBe(0);
+    });
+
+    it("returns 405 for POST method", async () => {
+      const app = createApp();
+
+      const response = await post(app, "/stats", {});
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
@@ -0,0 +1,194 @@
+import { describe, it, expect } from "vitest";
+import { Hono } from "hono";
+import app, { createApp } from "../../src/index";
+import { withVariables } from "../../src/middleware/variables";
+import { evaluateMessage } from "../../src/routes/websocket";
+import { FakeClock } from "../../src/services/clock";
+import { FakeCalculator } from "../../src/services/fake";
+import { calculatorService } from "../../src/services/operations";
+import { createDefaultValidators } from "../../src/services/validators";
+import type { AppEnv } from "../../src/types";
+
+// Runs evaluateMessage inside a real request so it has a populated context.
+async function evaluate(data: string | ArrayBuffer) {
+  const harness = new Hono<AppEnv>();
+  harness.use(
+    "*",
+    withVariables({
+      clock: new FakeClock(),
+      validators: createDefaultValidators(),
+      service: calculatorService,
+      resultTransform: (_, result) => result,
+    })
+  );
+  harness.get("/", async (c) => c.json(await evaluateMessage(c, data)));
+  const response = await harness.fetch(new Request("http://localhost/"));
+  return response.json();
+}
+
+function nextMessage(socket: WebSocket): Promise<unknown> {
+  return new Promise((resolve) => {
+    socket.addEventListener(
+      "message",
+      (event) => resolve(JSON.parse(event.data as string)),
+      { once: true }
+    );
+  });
+}
+
+describe("WebSocket Routes", () => {
+  describe("evaluateMessage", () => {
+    it("evaluates a binary operation", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "add", a: 2, b: 3 })
+      );
+
+      expect(reply).toEqual({ result: 5 });
+    });
+
+    it("evaluates hypot", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "hypot", a: 3, b: 4 })
+      );
+
+      expect(reply).toEqual({ result: 5 });
+    });
+
+    it("evaluates diff", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "diff", a: 3, b: 7 })
+      );
+
+      expect(reply).toEqual({ result: 4 });
+    });
+
+    it("evaluates gcd", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "gcd", a: 12, b: 18 })
+      );
+
+      expect(reply).toEqual({ result: 6 });
+    });
+
+    it("evaluates a unary operation", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "log", a: 1000 })
+      );
+
+      expect(reply).toEqual({ result: 3 });
+    });
+
+    it("reports malformed JSON", async () => {
+      const reply = await evaluate("not json");
+
+      expect(reply).toMatchObject({ code: "malformed_json" });
+    });
+
+    it("reports an unknown operation", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "modulo", a: 1, b: 2 })
+      );
+
+      expect(reply).toMatchObject({
+        error: "Unknown operation: modulo",
+        code: "unknown_operation",
+      });
+    });
+
+    it("reports a missing operand", async () => {
+      const reply = await evaluate(JSON.stringify({ operation: "add", a: 1 }));
+
+      expect(reply).toMatchObject({ code: "invalid_request" });
+    });
+
+    it("reports a domain error", async () => {
+      const reply = await evaluate(JSON.stringify({ operation: "ln", a: 0 }));
+
+      expect(reply).toMatchObject({ code: "invalid_input" });
+    });
+
+    it("does not resolve inherited object properties as operations", async () => {
+      const reply = await evaluate(
+        JSON.stringify({ operation: "toString", a: 1, b: 2 })
+      );
+
+      expect(reply).toMatchObject({ code: "unknown_operation" });
+    });
+
+    it("rejects binary frames", async () => {
+      const reply = await evaluate(new ArrayBuffer(4));
+
+      expect(reply).toMatchObject({ code: "invalid_request" });
+    });
+  });
+
+  describe("GET /ws", () => {
+    it("replies to each message over one connection", async () => {
+      const response = await app.fetch(
+        new Request("http://localhost/ws", {
+          headers: { Upgrade: "websocket" },
+        })
+      );
+
+      expect(response.status).toBe(101);
+      const socket = response.webSocket!;
+      socket.accept();
+
+      let reply = nextMessage(socket);
+      socket.send(JSON.stringify({ operation: "add", a: 2, b: 3 }));
+      expect(await reply).toEqual({ result: 5 });
+
+      reply = nextMessage(socket);
+      socket.send("{bad");
+      expect(await reply).toMatchObject({ code: "malformed_json" });
+
+      reply = nextMessage(socket);
+      socket.send(JSON.stringify({ operation: "multiply", a: 4, b: 5 }));
+      expect(await reply).toEqual({ result: 20 });
+
+      socket.close();
+    });
+
+    it("closes with 1011 on an unexpected error under the panic policy", async () => {
+      const panicking = createApp({
+        onError: "panic",
+        service: new FakeCalculator().throws("add", new TypeError("boom")),
+      });
+      const response = await panicking.fetch(
+        new Request("http://localhost/ws", {
+          headers: { Upgrade: "websocket" },
+        })
+      );
+      const socket = response.webSocket!;
+      socket.accept();
+      const closed = new Promise<CloseEvent>((resolve) =>
+        socket.addEventListener("close", resolve, { once: true })
+      );
+      const log = console.error;
+      console.error = () => {};
+
+      try {
+        socket.send(JSON.stringify({ operation: "add", a: 2, b: 3 }));
+        expect((await closed).code).toBe(1011);
+      } finally {
+        console.error = log;
+      }
+    });
+
+    it("returns 426 without an Upgrade header", async () => {
+      const response = await app.fetch(new Request("http://localhost/ws"));
+
+      expect(response.status).toBe(426);
+      const json = await response.json();
+      expect(json).toMatchObject({ code: "upgrade_required" });
+    });
+
+    it("returns 405 for POST method", async () => {
+      const response = await app.fetch(
+        new Request("http://localhost/ws", { method: "POST" })
+      );
+
+      expect(response.status).toBe(405);
+    });
+  });
+});
@@ -0,0 +1,124 @@
+import { describe, it, expect } from "vitest";
+import { auditOperations } from "../../src/services/audit";
+import type { AuditEntry } from "../../src/services/audit";
+import { FakeClock } from "../../src/services/clock";
+import { calculatorService } from "../../src/services/operations";
+
+function audited(sampleRate?: number) {
+  const entries: AuditEntry[] = [];
+  const service = auditOperations(
+    calculatorService,
+    new FakeClock(new Date("2024-01-01T00:00:00.000Z")),
+    { sampleRate, log: (entry) => entries.push(entry) }
+  );
+  return { service, entries };
+}
+
+describe("auditOperations", () => {
+  it("logs every operation by default", async () => {
+    const { service, entries } = audited();
+
+    await service.add(1, 2);
+    await service.sin(0);
+
+    expect(entries).toEqual([
+      {
+        level: "info",
+        message: "operation",
+        operation: "add",
+        operands: [1, 2],
+        result: 3,
+        timestamp: "2024-01-01T00:00:00.000Z",
+      },
+      {
+        level: "info",
+        message: "operation",
+        operation: "sin",
+        operands: [0],
+        result: 0,
+        timestamp: "2024-01-01T00:00:00.000Z",
+      },
+    ]);
+  });
+
+  it.each([2, 10, 100])(
+    "logs one in every %i successful operations",
+    async (sampleRate) => {
+      const { service, entries } = audited(sampleRate);
+
+      for (let i = 0; i < 1000; i++) {
+        await service.add(i, 1);
+      }
+
+      expect(entries.length).toBe(1000 / sampleRate);
+      expect(entries[0].operands).toEqual([0, 1]);
+      expect(entries[1].operands).toEqual([sampleRate, 1]);
+    }
+  );
+
+  it("samples across operations", async () => {
+    const { service, entries } = audited(2);
+
+    await service.add(1, 1);
+    await service.multiply(2, 2);
+    await service.subtract(3, 3);
+    await service.divide(4, 4);
+
+    expect(entries.map((entry) => entry.operation)).toEqual([
+      "add",
+      "subtract",
+    ]);
+  });
+
+  it("logs every failure regardless of sampling", async () => {
+    const { service, entries } = audited(100);
+
+    for (let i = 0; i < 50; i++) {
+      await expect(service.divide(i, 0)).rejects.toThrow(
+        "division by zero"
+      );
+    }
+
+    expect(entries.length).toBe(50);
+    expect(entries[0]).toEqual({
+      level: "warn",
+      message: "operation",
+      operation: "divide",
+      operands: [0, 0],
+      error: "invalid input: division by zero",
+      timestamp: "2024-01-01T00:00:00.000Z",
+    });
+  });
+
+  it("does not count failures towards the sample", async () => {
+    const { service, entries } = audited(3);
+
+    await service.add(1, 1);
+    await expect(service.divide(1, 0)).rejects.toThrow();
+    await service.add(2, 2);
+    await service.add(3, 3);
+    await service.add(4, 4);
+
+    expect(entries.map((entry) => entry.result ?? entry.error)).toEqual([
+      2,
+      "invalid input: division by zero",
+      8,
+    ]);
+  });
+
+  it("copies the operands", async () => {
+    const { service, entries } = audited();
+    const numbers = [1, 2, 3];
+
+    await service.addMany(numbers);
+    numbers.push(4);
+
+    expect(entries[0].operands).toEqual([1, 2, 3]);
+  });
+
+  it.each([0, -1, 1.5, NaN])("rejects a sample rate of %s", (sampleRate) => {
+    expect(() =>
+      auditOperations(calculatorService, new FakeClock(), { sampleRate })
+    ).toThrow(RangeError);
+  });
+});
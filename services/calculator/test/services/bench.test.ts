@@ -0,0 +1,47 @@
+import { describe, it, expect } from "vitest";
+import { runBenchmark } from "../../src/services/bench";
+import { calculatorService } from "../../src/services/operations";
+
+// A clock that advances by step milliseconds each time it is read.
+function ticking(step: number) {
+  let time = 0;
+  return () => (time += step);
+}
+
+describe("runBenchmark", () => {
+  it("calls the operation the requested number of times", async () => {
+    let calls = 0;
+    const service = {
+      ...calculatorService,
+      multiply: (a: number, b: number) => {
+        calls++;
+        return a * b;
+      },
+    };
+
+    const result = await runBenchmark(service, "multiply", 250, ticking(1));
+
+    expect(calls).toBe(250);
+    expect(result.operation).toBe("multiply");
+    expect(result.iterations).toBe(250);
+  });
+
+  it("reports throughput from the elapsed time", async () => {
+    const result = await runBenchmark(
+      calculatorService,
+      "add",
+      1000,
+      ticking(50)
+    );
+
+    expect(result.durationMs).toBe(50);
+    expect(result.opsPerSecond).toBe(20_000);
+  });
+
+  it("reports null throughput when no time elapses", async () => {
+    const result = await runBenchmark(calculatorService, "add", 10, () => 0);
+
+    expect(result.durationMs).toBe(0);
+    expect(result.opsPerSecond).toBeNull();
+  });
+});
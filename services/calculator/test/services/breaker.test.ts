@@ -0,0 +1,127 @@
+import { describe, it, expect } from "vitest";
+import { CircuitBreaker, CircuitOpenError } from "../../src/services/breaker";
+import { FakeClock } from "../../src/services/clock";
+
+const succeed = () => Promise.resolve("ok");
+const fail = () => Promise.reject(new Error("upstream down"));
+
+// Makes times failing calls through breaker, ignoring their errors.
+async function failTimes(breaker: CircuitBreaker, times: number) {
+  for (let i = 0; i < times; i++) {
+    await breaker.call(fail).catch(() => {});
+  }
+}
+
+describe("CircuitBreaker", () => {
+  it("opens after failureThreshold consecutive failures", async () => {
+    const breaker = new CircuitBreaker(new FakeClock(), {
+      failureThreshold: 3,
+    });
+
+    await failTimes(breaker, 2);
+    expect(breaker.state).toBe("closed");
+    await failTimes(breaker, 1);
+
+    expect(breaker.state).toBe("open");
+  });
+
+  it("resets the count after a success", async () => {
+    const breaker = new CircuitBreaker(new FakeClock(), {
+      failureThreshold: 3,
+    });
+
+    await failTimes(breaker, 2);
+    await breaker.call(succeed);
+    await failTimes(breaker, 2);
+
+    expect(breaker.state).toBe("closed");
+  });
+
+  it("fails fast without calling while open", async () => {
+    const breaker = new CircuitBreaker(new FakeClock(), {
+      failureThreshold: 1,
+    });
+    await failTimes(breaker, 1);
+    let calls = 0;
+
+    const error = await breaker
+      .call(() => {
+        calls++;
+        return succeed();
+      })
+      .catch((e) => e);
+
+    expect(error).toBeInstanceOf(CircuitOpenError);
+    expect(calls).toBe(0);
+  });
+
+  it("closes when the half-open trial succeeds", async () => {
+    const clock = new FakeClock();
+    const breaker = new CircuitBreaker(clock, {
+      failureThreshold: 1,
+      resetTimeoutMs: 1000,
+    });
+    await failTimes(breaker, 1);
+
+    clock.advance(999);
+    expect(breaker.state).toBe("open");
+    clock.advance(1);
+    expect(breaker.state).toBe("half_open");
+
+    expect(await breaker.call(succeed)).toBe("ok");
+    expect(breaker.state).toBe("closed");
+  });
+
+  it("reopens when the half-open trial fails", async () => {
+    const clock = new FakeClock();
+    const breaker = new CircuitBreaker(clock, {
+      failureThreshold: 2,
+      resetTimeoutMs: 1000,
+    });
+    await failTimes(breaker, 2);
+    clock.advance(1000);
+
+    await failTimes(breaker, 1);
+
+    expect(breaker.state).toBe("open");
+    clock.advance(999);
+    expect(breaker.state).toBe("open");
+  });
+
+  it("lets only one trial through while half-open", async () => {
+    const clock = new FakeClock();
+    const breaker = new CircuitBreaker(clock, {
+      failureThreshold: 1,
+      resetTimeoutMs: 1000,
+    });
+    await failTimes(breaker, 1);
+    clock.advance(1000);
+    let finish: (value: string) => void = () => {};
+
+    const trial = breaker.call(
+      () => new Promise<string>((resolve) => (finish = resolve))
+    );
+    const other = await breaker.call(succeed).catch((e) => e);
+    finish("ok");
+
+    expect(other).toBeInstanceOf(CircuitOpenError);
+    expect(await trial).toBe("ok");
+    expect(breaker.state).toBe("closed");
+  });
+
+  it("ignores errors that isFailure does not count", async () => {
+    const breaker = new CircuitBreaker(new FakeClock(), {
+      failureThreshold: 1,
+    });
+
+    await breaker.call(fail, () => false).catch(() => {});
+
+    expect(breaker.state).toBe("closed");
+  });
+
+  it.each([0, -1, 1.5])("rejects a failure threshold of %d", (threshold) => {
+    expect(
+      () => new CircuitBreaker(new FakeClock(), { failureThreshold: threshold })
+    ).toThrow(RangeError);
+  });
+});
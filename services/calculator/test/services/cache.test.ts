@@ -0,0 +1,157 @@
+import { describe, it, expect } from "vitest";
+import { cacheResults } from "../../src/services/cache";
+import { FakeClock } from "../../src/services/clock";
+import { FakeCalculator } from "../../src/services/fake";
+import { Lifecycle } from "../../src/services/lifecycle";
+import { Metrics } from "../../src/services/metrics";
+import { InvalidInputError } from "../../src/services/calculator";
+
+function setup(maxEntries?: number) {
+  const clock = new FakeClock();
+  const metrics = new Metrics();
+  const fake = new FakeCalculator();
+  const service = cacheResults(fake, clock, metrics, {
+    ttlMs: 1000,
+    maxEntries,
+  });
+  return { clock, metrics, fake, service };
+}
+
+describe("cacheResults", () => {
+  it("answers a repeated call from the cache", async () => {
+    const { metrics, fake, service } = setup();
+
+    expect(await service.add(2, 3)).toBe(5);
+    expect(await service.add(2, 3)).toBe(5);
+
+    expect(fake.callsTo("add")).toEqual([[2, 3]]);
+    expect(metrics.cacheMisses.value).toBe(1);
+    expect(metrics.cacheHits.value).toBe(1);
+  });
+
+  it("keys by operation and operands", async () => {
+    const { metrics, fake, service } = setup();
+
+    await service.add(2, 3);
+    await service.add(3, 2);
+    await service.multiply(2, 3);
+    await service.addMany([2, 3]);
+
+    expect(fake.calls).toHaveLength(4);
+    expect(metrics.cacheHits.value).toBe(0);
+    expect(metrics.cacheMisses.value).toBe(4);
+  });
+
+  it("caches results with several figures", async () => {
+    const { fake, service } = setup();
+    const expected = { quotient: -3, remainder: -1 };
+
+    expect(await service.divmod(-7, 2)).toEqual(expected);
+    expect(await service.divmod(-7, 2)).toEqual(expected);
+
+    expect(fake.callsTo("divmod")).toEqual([[-7, 2]]);
+  });
+
+  it("keys rounding by mode", async () => {
+    const { fake, service } = setup();
+
+    expect(await service.round(2.5, 0, "half_up")).toBe(3);
+    expect(await service.round(2.5, 0, "half_down")).toBe(2);
+    expect(await service.round(2.5, 0, "half_up")).toBe(3);
+
+    expect(fake.callsTo("round")).toEqual([
+      [2.5, 0],
+      [2.5, 0],
+    ]);
+  });
+
+  it("computes again once an entry outlives its TTL", async () => {
+    const { clock, metrics, fake, service } = setup();
+
+    await service.add(2, 3);
+    clock.advance(999);
+    await service.add(2, 3);
+    clock.advance(1);
+    await service.add(2, 3);
+
+    expect(fake.callsTo("add")).toHaveLength(2);
+    expect(metrics.cacheHits.value).toBe(1);
+    expect(metrics.cacheMisses.value).toBe(2);
+  });
+
+  it("does not cache errors", async () => {
+    const { fake, service } = setup();
+    fake.throws("add", new InvalidInputError());
+
+    await expect(service.add(2, 3)).rejects.toThrow(InvalidInputError);
+    fake.reset();
+
+    expect(await service.add(2, 3)).toBe(5);
+  });
+
+  it("evicts the oldest entry when full", async () => {
+    const { fake, service } = setup(2);
+
+    await service.add(1, 1);
+    await service.add(2, 2);
+    await service.add(3, 3);
+    await service.add(2, 2);
+    await service.add(1, 1);
+
+    expect(fake.callsTo("add")).toEqual([
+      [1, 1],
+      [2, 2],
+      [3, 3],
+      [1, 1],
+    ]);
+  });
+
+  it("sweeps expired entries before evicting live ones", async () => {
+    const { clock, fake, service } = setup(2);
+
+    await service.add(1, 1);
+    clock.advance(600);
+    await service.add(2, 2);
+    clock.advance(600);
+    await service.add(3, 3);
+    await service.add(2, 2);
+
+    expect(fake.callsTo("add")).toEqual([
+      [1, 1],
+      [2, 2],
+      [3, 3],
+    ]);
+  });
+
+  it("shares an entry among concurrent calls after it is stored", async () => {
+    const { fake, service } = setup();
+    await service.add(2, 3);
+
+    const results = await Promise.all(
+      Array.from({ length: 10 }, () => service.add(2, 3))
+    );
+
+    expect(results).toEqual(Array(10).fill(5));
+    expect(fake.callsTo("add")).toHaveLength(1);
+  });
+
+  it("empties the cache at shutdown", async () => {
+    const fake = new FakeCalculator();
+    const lifecycle = new Lifecycle();
+    const service = cacheResults(
+      fake,
+      new FakeClock(),
+      new Metrics(),
+      { ttlMs: 1000 },
+      lifecycle
+    );
+    await service.add(2, 3);
+
+    expect(await lifecycle.shutdown()).toEqual([
+      { name: "cache", status: "ok" },
+    ]);
+    await service.add(2, 3);
+
+    expect(fake.callsTo("add")).toHaveLength(2);
+  });
+});
@@ -0,0 +1,18 @@
+import { bench, describe } from "vitest";
+import { add, addMany, divide } from "../../src/services/calculator";
+
+const numbers = Array.from({ length: 100 }, (_, i) => i);
+
+describe("Calculator Service", () => {
+  bench("add", () => {
+    add(1.5, 2.5);
+  });
+
+  bench("divide", () => {
+    divide(10, 3);
+  });
+
+  bench("addMany of 100 numbers", () => {
+    addMany(numbers);
+  });
+});
@@ -3,8 +3,38 @@ import {
   add,
   subtract,
   multiply,
+  divide,
+  divmod,
+  hypot,
+  absDiff,
+  percentChange,
+  gcd,
+  lcm,
+  round,
+  roundHalfEven,
+  normalizeResult,
+  addMany,
+  kahanSum,
+  multiplyMany,
+  summarize,
+  weightedSum,
+  evalPolynomial,
+  convert,
+  fma,
+  approxEqual,
+  clamp,
+  compare,
+  sin,
+  cos,
+  tan,
+  log,
+  ln,
   validateInputs,
+  validateProductMagnitude,
   InvalidInputError,
+  DivisionByZeroError,
+  NonIntegerError,
+  OverflowError,
 } from "../../src/services/calculator";
 
 describe("Calculator Service", () => {
@@ -39,6 +69,22 @@ describe("Calculator Service", () => {
     it("throws InvalidInputError for negative Infinity", () => {
       expect(() => add(5, -Infinity)).toThrow(InvalidInputError);
     });
+
+    it("keeps large results that do not overflow", () => {
+      expect(add(1e307, 1e307)).toBe(2e307);
+    });
+
+    it("throws OverflowError when the sum overflows", () => {
+      expect(() => add(Number.MAX_VALUE, Number.MAX_VALUE)).toThrow(
+        OverflowError
+      );
+    });
+
+    it("throws OverflowError when the sum overflows negatively", () => {
+      expect(() => add(-Number.MAX_VALUE, -Number.MAX_VALUE)).toThrow(
+        OverflowError
+      );
+    });
   });
 
   describe("subtract", () => {
@@ -62,6 +108,12 @@ describe("Calculator Service", () => {
     it("throws InvalidInputError for Infinity", () => {
       expect(() => subtract(Infinity, 5)).toThrow(InvalidInputError);
     });
+
+    it("throws OverflowError when the difference overflows", () => {
+      expect(() => subtract(-Number.MAX_VALUE, Number.MAX_VALUE)).toThrow(
+        OverflowError
+      );
+    });
   });
 
   describe("multiply", () => {
@@ -84,6 +136,823 @@ describe("Calculator Service", () => {
     it("throws InvalidInputError for Infinity", () => {
       expect(() => multiply(5, Infinity)).toThrow(InvalidInputError);
     });
+
+    it("throws OverflowError when the product overflows", () => {
+      expect(() => multiply(1e200, 1e200)).toThrow(OverflowError);
+    });
+  });
+
+  describe("validateProductMagnitude", () => {
+    const largest = Number.MAX_VALUE;
+    const nextAboveOne = 1 + Number.EPSILON;
+
+    it.each([
+      { a: largest, b: 1, name: "largest times one" },
+      { a: -largest, b: 1, name: "negative largest" },
+      { a: 2 ** 512, b: largest / 2 ** 512, name: "split exactly at largest" },
+      { a: 1e154, b: 1e154, name: "just under the limit" },
+      { a: 1e300, b: 0, name: "zero operand" },
+      { a: 1e-300, b: 1e300, name: "tiny times huge" },
+    ])("accepts $name", ({ a, b }) => {
+      expect(() => validateProductMagnitude(a, b)).not.toThrow();
+    });
+
+    it.each([
+      { a: largest, b: nextAboveOne, name: "largest times next above one" },
+      { a: -largest, b: 2, name: "negative product" },
+      { a: 2 ** 512, b: 2 ** 512, name: "exactly 2^1024" },
+      { a: 1e155, b: 1e155, name: "just over the limit" },
+      { a: 1e200, b: 1e200, name: "far over the limit" },
+    ])("rejects $name before computing", ({ a, b }) => {
+      expect(() => validateProductMagnitude(a, b)).toThrow(
+        "invalid input: product magnitude exceeds the largest finite number"
+      );
+      expect(() => validateProductMagnitude(a, b)).toThrow(OverflowError);
+    });
+
+    it("agrees with the computed product near the boundary", () => {
+      const rejects = (a: number, b: number) => {
+        try {
+          validateProductMagnitude(a, b);
+          return false;
+        } catch {
+          return true;
+        }
+      };
+      const root = Math.sqrt(largest);
+      let overflowing = 0;
+      for (let i = -200; i <= 200; i++) {
+        const nudge = 1 + i * Number.EPSILON;
+        const pairs = [
+          [root * nudge, root],
+          [root * nudge, root * nextAboveOne],
+          [largest, nudge],
+          [largest / 4, 4 * nudge],
+        ];
+        for (const [a, b] of pairs) {
+          const overflows = !Number.isFinite(a * b);
+          overflowing += overflows ? 1 : 0;
+          expect(rejects(a, b)).toBe(overflows);
+        }
+      }
+      // Both sides of the boundary were exercised.
+      expect(overflowing).toBeGreaterThan(0);
+      expect(overflowing).toBeLessThan(4 * 401);
+    });
+  });
+
+  describe("divide", () => {
+    it.each([
+      { a: 10, b: 5, expected: 2, name: "positive numbers" },
+      { a: -10, b: 4, expected: -2.5, name: "mixed signs" },
+      { a: 0, b: 5, expected: 0, name: "zero dividend" },
+      { a: 1, b: 3, expected: 1 / 3, name: "repeating fraction" },
+    ])("$name: divide($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(divide(a, b)).toBe(expected);
+    });
+
+    it("throws DivisionByZeroError for a zero divisor", () => {
+      expect(() => divide(1, 0)).toThrow(DivisionByZeroError);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => divide(NaN, 1)).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the quotient overflows", () => {
+      expect(() => divide(1e308, 1e-10)).toThrow(OverflowError);
+    });
+  });
+
+  describe("divmod", () => {
+    // Truncation toward zero: the quotient drops its fraction and the
+    // remainder has the sign of the dividend, unlike floored division.
+    it.each([
+      { a: 7, b: 2, quotient: 3, remainder: 1 },
+      { a: -7, b: 2, quotient: -3, remainder: -1 },
+      { a: 7, b: -2, quotient: -3, remainder: 1 },
+      { a: -7, b: -2, quotient: 3, remainder: -1 },
+      { a: 6, b: 3, quotient: 2, remainder: 0 },
+      { a: 7.5, b: 2, quotient: 3, remainder: 1.5 },
+    ])("divmod($a, $b) = $quotient r $remainder", ({ a, b, ...expected }) => {
+      const result = divmod(a, b);
+
+      expect(result).toEqual(expected);
+      expect(result.quotient * b + result.remainder).toBe(a);
+    });
+
+    it("throws DivisionByZeroError for a zero divisor", () => {
+      expect(() => divmod(1, 0)).toThrow(DivisionByZeroError);
+    });
+
+    it("throws OverflowError when the quotient overflows", () => {
+      expect(() => divmod(1e308, 1e-10)).toThrow(OverflowError);
+    });
+  });
+
+  describe("round", () => {
+    it.each([
+      { mode: "half_even", tie: 2, negativeTie: -2, above: 3 },
+      { mode: "half_up", tie: 3, negativeTie: -3, above: 3 },
+      { mode: "half_down", tie: 2, negativeTie: -2, above: 3 },
+      { mode: "ceil", tie: 3, negativeTie: -2, above: 3 },
+      { mode: "floor", tie: 2, negativeTie: -3, above: 2 },
+      { mode: "trunc", tie: 2, negativeTie: -2, above: 2 },
+    ] as const)(
+      "$mode rounds 2.5 to $tie and -2.5 to $negativeTie",
+      ({ mode, tie, negativeTie, above }) => {
+        expect(round(2.5, 0, mode)).toBe(tie);
+        expect(round(-2.5, 0, mode)).toBe(negativeTie);
+        expect(round(2.6, 0, mode)).toBe(above);
+      }
+    );
+
+    it.each([
+      { mode: "half_up", value: 0.125, expected: 0.13 },
+      { mode: "half_down", value: 0.125, expected: 0.12 },
+      { mode: "ceil", value: -0.125, expected: -0.12 },
+      { mode: "floor", value: -0.125, expected: -0.13 },
+      { mode: "trunc", value: -0.129, expected: -0.12 },
+    ] as const)(
+      "$mode rounds $value to $expected at two places",
+      ({ mode, value, expected }) => {
+        expect(round(value, 2, mode)).toBe(expected);
+      }
+    );
+
+    it("does not push exact values past a boundary", () => {
+      // 1.1 * 100 is 110.00000000000001 before snapping.
+      expect(round(1.1, 2, "ceil")).toBe(1.1);
+      expect(round(2.675, 2, "floor")).toBe(2.67);
+    });
+
+    it.each([-1, 1.5, 16])(
+      "throws InvalidInputError for %s places",
+      (places) => {
+        expect(() => round(1, places, "half_even")).toThrow(
+          "invalid input: places must be an integer from 0 to 15"
+        );
+      }
+    );
+
+    it("throws InvalidInputError for an infinite value", () => {
+      expect(() => round(Infinity, 2, "ceil")).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("roundHalfEven", () => {
+    it.each([
+      { value: 10 / 3, places: 4, expected: 3.3333, name: "rounds down" },
+      { value: 2 / 3, places: 2, expected: 0.67, name: "rounds up" },
+      { value: 0.125, places: 2, expected: 0.12, name: "tie to even down" },
+      { value: 0.375, places: 2, expected: 0.38, name: "tie to even up" },
+      { value: 2.5, places: 0, expected: 2, name: "tie at zero places" },
+      { value: 3.5, places: 0, expected: 4, name: "odd tie at zero places" },
+      { value: -2.5, places: 0, expected: -2, name: "negative tie" },
+      { value: 2.675, places: 2, expected: 2.68, name: "inexact tie" },
+      { value: 1.5, places: 3, expected: 1.5, name: "fewer digits" },
+    ])(
+      "$name: roundHalfEven($value, $places)",
+      ({ value, places, expected }) => {
+        expect(roundHalfEven(value, places)).toBe(expected);
+      }
+    );
+
+    it("leaves values too large to have fractional digits unchanged", () => {
+      expect(roundHalfEven(1e300, 2)).toBe(1e300);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => roundHalfEven(NaN, 2)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("normalizeResult", () => {
+    it("turns negative zero into positive zero", () => {
+      expect(Object.is(normalizeResult(-0), 0)).toBe(true);
+      expect(Object.is(normalizeResult(multiply(-1, 0)), 0)).toBe(true);
+    });
+
+    it.each([0, 1.5, -2, Number.MIN_VALUE, -Number.MIN_VALUE])(
+      "leaves %d unchanged",
+      (value) => {
+        expect(Object.is(normalizeResult(value), value)).toBe(true);
+      }
+    );
+  });
+
+  describe("hypot", () => {
+    it.each([
+      { a: 3, b: 4, expected: 5, name: "3-4-5 triangle" },
+      { a: 0, b: 0, expected: 0, name: "zeros" },
+      { a: 0, b: 7, expected: 7, name: "zero first operand" },
+      { a: -3, b: -4, expected: 5, name: "negative operands" },
+      { a: -5, b: 12, expected: 13, name: "mixed signs" },
+    ])("$name: hypot($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(hypot(a, b)).toBe(expected);
+    });
+
+    it("does not overflow on large operands", () => {
+      expect(hypot(3e200, 4e200) / 5e200).toBeCloseTo(1, 12);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => hypot(NaN, 1)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for Infinity", () => {
+      expect(() => hypot(1, -Infinity)).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the result overflows", () => {
+      expect(() => hypot(Number.MAX_VALUE, Number.MAX_VALUE)).toThrow(
+        OverflowError
+      );
+    });
+  });
+
+  describe("absDiff", () => {
+    it.each([
+      { a: 3, b: 7, expected: 4, name: "smaller first" },
+      { a: 7, b: 3, expected: 4, name: "larger first" },
+      { a: -3, b: -7, expected: 4, name: "negative operands" },
+      { a: -2, b: 5, expected: 7, name: "mixed signs" },
+      { a: 5, b: 5, expected: 0, name: "equal operands" },
+    ])("$name: absDiff($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(absDiff(a, b)).toBe(expected);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => absDiff(1, NaN)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for Infinity", () => {
+      expect(() => absDiff(Infinity, 1)).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the subtraction overflows", () => {
+      expect(() => absDiff(Number.MAX_VALUE, -Number.MAX_VALUE)).toThrow(
+        OverflowError
+      );
+    });
+  });
+
+  describe("percentChange", () => {
+    it.each([
+      { a: 50, b: 75, expected: 50, name: "increase" },
+      { a: 50, b: 25, expected: -50, name: "decrease" },
+      { a: -50, b: -25, expected: -50, name: "negative base" },
+      { a: 40, b: 40, expected: 0, name: "equal values" },
+    ])("$name: percentChange($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(percentChange(a, b)).toBe(expected);
+    });
+
+    it("throws DivisionByZeroError for a zero base", () => {
+      expect(() => percentChange(0, 5)).toThrow(DivisionByZeroError);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => percentChange(1, NaN)).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the result overflows", () => {
+      expect(() => percentChange(Number.MIN_VALUE, 1)).toThrow(OverflowError);
+    });
+  });
+
+  describe("gcd", () => {
+    it.each([
+      { a: 12, b: 18, expected: 6, name: "typical pair" },
+      { a: 48, b: 180, expected: 12, name: "larger pair" },
+      { a: 17, b: 5, expected: 1, name: "coprime" },
+      { a: 7, b: 7, expected: 7, name: "equal operands" },
+      { a: -12, b: 18, expected: 6, name: "negative operand" },
+      { a: 0, b: 9, expected: 9, name: "zero first operand" },
+      { a: 9, b: 0, expected: 9, name: "zero second operand" },
+      { a: 0, b: 0, expected: 0, name: "both zero" },
+    ])("$name: gcd($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(gcd(a, b)).toBe(expected);
+    });
+
+    it("throws NonIntegerError for a fractional operand", () => {
+      expect(() => gcd(4.5, 2)).toThrow(NonIntegerError);
+      expect(() => gcd(4, 2.5)).toThrow(
+        "invalid input: operands must be integers"
+      );
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => gcd(NaN, 2)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("lcm", () => {
+    it.each([
+      { a: 4, b: 6, expected: 12, name: "typical pair" },
+      { a: 21, b: 6, expected: 42, name: "larger pair" },
+      { a: 5, b: 7, expected: 35, name: "coprime" },
+      { a: -4, b: 6, expected: 12, name: "negative operand" },
+      { a: 0, b: 5, expected: 0, name: "zero operand" },
+      { a: 0, b: 0, expected: 0, name: "both zero" },
+    ])("$name: lcm($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(lcm(a, b)).toBe(expected);
+    });
+
+    it("throws NonIntegerError for a fractional operand", () => {
+      expect(() => lcm(0.5, 2)).toThrow(NonIntegerError);
+    });
+
+    it("throws OverflowError beyond the safe integer range", () => {
+      expect(() => lcm(2 ** 30 + 1, 2 ** 30 - 1)).toThrow(OverflowError);
+    });
+  });
+
+  describe("addMany", () => {
+    it.each([
+      { numbers: [], expected: 0, name: "empty list" },
+      { numbers: [7], expected: 7, name: "single element" },
+      { numbers: [1, 2, 3, 4], expected: 10, name: "several elements" },
+      { numbers: [1.5, -0.5, 2], expected: 3, name: "mixed decimals" },
+    ])("$name: addMany($numbers) = $expected", ({ numbers, expected }) => {
+      expect(addMany(numbers)).toBe(expected);
+    });
+
+    it("throws InvalidInputError for a NaN element", () => {
+      expect(() => addMany([1, NaN, 3])).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("kahanSum", () => {
+    it.each([
+      { numbers: [], expected: 0, name: "empty list" },
+      { numbers: [7], expected: 7, name: "single element" },
+      { numbers: [1, 2, 3, 4], expected: 10, name: "several elements" },
+    ])("$name: kahanSum($numbers) = $expected", ({ numbers, expected }) => {
+      expect(kahanSum(numbers)).toBe(expected);
+    });
+
+    it("keeps small operands that a naive sum loses", () => {
+      const numbers = [1e16, ...Array<number>(1000).fill(1)];
+
+      expect(addMany(numbers)).toBe(1e16);
+      expect(kahanSum(numbers)).toBe(1e16 + 1000);
+    });
+
+    it("is more accurate than a naive sum of tenths", () => {
+      const numbers = Array<number>(10).fill(0.1);
+
+      expect(addMany(numbers)).not.toBe(1);
+      expect(kahanSum(numbers)).toBe(1);
+    });
+
+    it("recovers operands cancelled by a larger one", () => {
+      expect(addMany([1, 1e100, 1, -1e100])).toBe(0);
+      expect(kahanSum([1, 1e100, 1, -1e100])).toBe(2);
+    });
+
+    it("throws InvalidInputError for a NaN element", () => {
+      expect(() => kahanSum([1, NaN, 3])).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the sum overflows", () => {
+      expect(() => kahanSum([Number.MAX_VALUE, Number.MAX_VALUE])).toThrow(
+        OverflowError
+      );
+    });
+  });
+
+  describe("summarize", () => {
+    it("describes the textbook dataset", () => {
+      // Deviations from the mean of 5 are -3, -1, -1, -1, 0, 0, 2 and 4,
+      // whose squares sum to 32.
+      expect(summarize([2, 4, 4, 4, 5, 5, 7, 9])).toEqual({
+        count: 8,
+        mean: 5,
+        variance: 4,
+        stddev: 2,
+        min: 2,
+        max: 9,
+      });
+    });
+
+    it("describes a single number", () => {
+      expect(summarize([-3.5])).toEqual({
+        count: 1,
+        mean: -3.5,
+        variance: 0,
+        stddev: 0,
+        min: -3.5,
+        max: -3.5,
+      });
+    });
+
+    it("keeps the variance of large, close values", () => {
+      // 4, 7, 13 and 16 shifted by 1e9: deviations of -6, -3, 3 and 6 give
+      // 90 / 4. Summing squares first would cancel most of the digits.
+      const summary = summarize([1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16]);
+
+      expect(summary.mean).toBe(1e9 + 10);
+      expect(summary.variance).toBe(22.5);
+      expect(summary.stddev).toBeCloseTo(Math.sqrt(22.5), 12);
+    });
+
+    it("is symmetric about zero", () => {
+      expect(summarize([-1, 1])).toMatchObject({ mean: 0, variance: 1 });
+    });
+
+    it("throws InvalidInputError for an empty list", () => {
+      expect(() => summarize([])).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for a NaN element", () => {
+      expect(() => summarize([1, NaN])).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the spread overflows", () => {
+      expect(() => summarize([1e308, -1e308])).toThrow(OverflowError);
+    });
+  });
+
+  describe("multiplyMany", () => {
+    it.each([
+      { numbers: [], expected: 1, name: "empty list" },
+      { numbers: [7], expected: 7, name: "single element" },
+      { numbers: [1, 2, 3, 4], expected: 24, name: "several elements" },
+      { numbers: [2, 0, 5], expected: 0, name: "contains zero" },
+    ])(
+      "$name: multiplyMany($numbers) = $expected",
+      ({ numbers, expected }) => {
+        expect(multiplyMany(numbers)).toBe(expected);
+      }
+    );
+
+    it("throws InvalidInputError for an Infinity element", () => {
+      expect(() => multiplyMany([2, Infinity])).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the product overflows", () => {
+      expect(() => multiplyMany([1e200, 1e200, 1e-300])).toThrow(
+        OverflowError
+      );
+    });
+  });
+
+  describe("lists longer than the call stack allows as arguments", () => {
+    const ones = Array<number>(300_000).fill(1);
+
+    it.each([
+      { name: "addMany", compute: () => addMany(ones), expected: 300_000 },
+      { name: "kahanSum", compute: () => kahanSum(ones), expected: 300_000 },
+      { name: "multiplyMany", compute: () => multiplyMany(ones), expected: 1 },
+      {
+        name: "summarize",
+        compute: () => summarize(ones).count,
+        expected: 300_000,
+      },
+      {
+        name: "evalPolynomial",
+        compute: () => evalPolynomial(ones, 1),
+        expected: 300_000,
+      },
+    ])("$name handles 300,000 numbers", ({ compute, expected }) => {
+      expect(compute()).toBe(expected);
+    });
+
+    it("still rejects a NaN element", () => {
+      expect(() => addMany([...ones, NaN])).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("weightedSum", () => {
+    it.each([
+      { a: 10, wa: 0.25, b: 20, wb: 0.75, expected: 17.5, name: "fractions" },
+      { a: 3, wa: 1, b: 4, wb: 1, expected: 7, name: "unit weights" },
+      { a: 3, wa: 0, b: 4, wb: 0, expected: 0, name: "zero weights" },
+      { a: 3, wa: -2, b: 4, wb: 1, expected: -2, name: "negative weight" },
+    ])(
+      "$name: weightedSum($a, $wa, $b, $wb) = $expected",
+      ({ a, wa, b, wb, expected }) => {
+        expect(weightedSum(a, wa, b, wb)).toBeCloseTo(expected, 12);
+      }
+    );
+
+    it("throws InvalidInputError for a NaN weight", () => {
+      expect(() => weightedSum(1, NaN, 2, 3)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for an Infinity operand", () => {
+      expect(() => weightedSum(1, 2, Infinity, 3)).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the result overflows", () => {
+      expect(() => weightedSum(1e308, 10, 1, 1)).toThrow(OverflowError);
+    });
+
+    it("throws InvalidInputError when products overflow in opposite directions", () => {
+      expect(() => weightedSum(1e308, 10, -1e308, 10)).toThrow(
+        InvalidInputError
+      );
+    });
+  });
+
+  describe("evalPolynomial", () => {
+    it.each([
+      { coefficients: [2, 3, 1], x: 2, expected: 15, name: "2x² + 3x + 1" },
+      { coefficients: [1, 0, -1], x: 3, expected: 8, name: "x² - 1" },
+      { coefficients: [1, -6, 11, -6], x: 1, expected: 0, name: "at a root" },
+      { coefficients: [1, -6, 11, -6], x: 4, expected: 6, name: "a cubic" },
+      { coefficients: [1, 0, 0], x: -3, expected: 9, name: "negative x" },
+      { coefficients: [4, 3, 7], x: 0, expected: 7, name: "x = 0" },
+      { coefficients: [5], x: 100, expected: 5, name: "a constant" },
+      { coefficients: [0.5, 0.25], x: 0.5, expected: 0.5, name: "fractions" },
+    ])(
+      "$name: evaluates $coefficients at $x",
+      ({ coefficients, x, expected }) => {
+        expect(evalPolynomial(coefficients, x)).toBe(expected);
+      }
+    );
+
+    it("throws InvalidInputError for no coefficients", () => {
+      expect(() => evalPolynomial([], 2)).toThrow(
+        "invalid input: polynomial requires at least one coefficient"
+      );
+    });
+
+    it("throws InvalidInputError for a NaN coefficient", () => {
+      expect(() => evalPolynomial([1, NaN], 2)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for an Infinity x", () => {
+      expect(() => evalPolynomial([1, 2], Infinity)).toThrow(
+        InvalidInputError
+      );
+    });
+
+    it("throws OverflowError when the result overflows", () => {
+      expect(() => evalPolynomial([1, 0, 0], 1e200)).toThrow(OverflowError);
+    });
+
+    it("throws OverflowError when an intermediate step overflows", () => {
+      // 1.125e308 exactly, but the first step, 1.5e308 * 0.5 + 1.5e308, is
+      // beyond the largest finite number.
+      expect(() => evalPolynomial([1.5e308, 1.5e308, 0], 0.5)).toThrow(
+        OverflowError
+      );
+    });
+  });
+
+  describe("convert", () => {
+    it.each([
+      { value: 100, expected: 212, name: "boiling point" },
+      { value: 0, expected: 32, name: "freezing point" },
+      { value: -40, expected: -40, name: "where the scales meet" },
+    ])("$name: $value°C is $expected°F", ({ value, expected }) => {
+      expect(convert(value, 1.8, 32)).toBeCloseTo(expected, 10);
+    });
+
+    it("is the identity for scale 1 and offset 0", () => {
+      expect(convert(12.5, 1, 0)).toBe(12.5);
+    });
+
+    it.each([
+      { value: NaN, scale: 1.8, offset: 32 },
+      { value: 100, scale: NaN, offset: 32 },
+      { value: 100, scale: 1.8, offset: NaN },
+      { value: 100, scale: 1.8, offset: Infinity },
+    ])(
+      "throws InvalidInputError for ($value, $scale, $offset)",
+      ({ value, scale, offset }) => {
+        expect(() => convert(value, scale, offset)).toThrow(InvalidInputError);
+      }
+    );
+
+    it("throws OverflowError when the result overflows", () => {
+      expect(() => convert(1e308, 10, 0)).toThrow(OverflowError);
+    });
+  });
+
+  describe("fma", () => {
+    it.each([
+      { a: 2, b: 3, c: 4, expected: 10, name: "small integers" },
+      { a: -2, b: 3, c: 0, expected: -6, name: "zero addend" },
+      { a: 0.5, b: 0.5, c: -0.25, expected: 0, name: "exact cancellation" },
+    ])("$name: fma($a, $b, $c) = $expected", ({ a, b, c, expected }) => {
+      expect(fma(a, b, c)).toBe(expected);
+    });
+
+    it("rounds once where a*b + c rounds twice", () => {
+      // 0.1 is slightly above one tenth, which the rounded product 0.1 * 10
+      // hides.
+      expect(0.1 * 10 - 1).toBe(0);
+      expect(fma(0.1, 10, -1)).toBe(5.551115123125783e-17);
+    });
+
+    it("recovers the rounding error of a product", () => {
+      const a = 1 + 2 ** -30;
+
+      expect(a * a - a * a).toBe(0);
+      expect(fma(a, a, -(a * a))).toBe(2 ** -60);
+    });
+
+    it("rounds ties to even", () => {
+      expect(fma(Number.MIN_VALUE, 0.5, 0)).toBe(0);
+      expect(fma(Number.MIN_VALUE, 1.5, 0)).toBe(2 * Number.MIN_VALUE);
+    });
+
+    it("keeps the sign of a negative zero", () => {
+      expect(Object.is(fma(-0, 1, -0), -0)).toBe(true);
+    });
+
+    it("throws InvalidInputError for a non-finite operand", () => {
+      expect(() => fma(1, 2, NaN)).toThrow(InvalidInputError);
+    });
+
+    it("throws OverflowError when the result overflows", () => {
+      expect(() => fma(1e308, 10, 0)).toThrow(OverflowError);
+    });
+  });
+
+  describe("clamp", () => {
+    it.each([
+      { value: -5, min: 0, max: 10, expected: 0, name: "below min" },
+      { value: 15, min: 0, max: 10, expected: 10, name: "above max" },
+      { value: 4, min: 0, max: 10, expected: 4, name: "in range" },
+      { value: 0, min: 0, max: 10, expected: 0, name: "at min" },
+      { value: 7, min: 3, max: 3, expected: 3, name: "empty range" },
+    ])(
+      "$name: clamp($value, $min, $max) = $expected",
+      ({ value, min, max, expected }) => {
+        expect(clamp(value, min, max)).toBe(expected);
+      }
+    );
+
+    it("throws InvalidInputError when min exceeds max", () => {
+      expect(() => clamp(5, 10, 0)).toThrow(
+        "invalid input: min must not exceed max"
+      );
+    });
+
+    it("throws InvalidInputError for a non-finite operand", () => {
+      expect(() => clamp(1, -Infinity, 2)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("compare", () => {
+    it.each([
+      { a: 1, b: 2, expected: -1, name: "less" },
+      { a: 2, b: 2, expected: 0, name: "equal" },
+      { a: 3, b: 2, expected: 1, name: "greater" },
+      { a: -0, b: 0, expected: 0, name: "signed zeros" },
+      { a: -1e308, b: 1e308, expected: -1, name: "opposite extremes" },
+    ])("$name: compare($a, $b) = $expected", ({ a, b, expected }) => {
+      expect(compare(a, b)).toBe(expected);
+    });
+
+    it.each([
+      { a: NaN, b: 1 },
+      { a: 1, b: Infinity },
+      { a: -Infinity, b: 1 },
+    ])("throws InvalidInputError for ($a, $b)", ({ a, b }) => {
+      expect(() => compare(a, b)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("approxEqual", () => {
+    it.each([
+      { a: 0.1 + 0.2, b: 0.3, epsilon: 1e-9, name: "representation error" },
+      { a: 1e-12, b: -1e-12, epsilon: 1e-9, name: "absolutely near zero" },
+      { a: 1e20, b: 1e20 + 16384, epsilon: 1e-9, name: "relatively large" },
+      { a: 1, b: 1.5, epsilon: 0.5, name: "exactly epsilon apart" },
+      { a: 2, b: 2, epsilon: 0, name: "exactly equal" },
+      { a: -0, b: 0, epsilon: 0, name: "signed zeros" },
+    ])("$name: ($a, $b) within $epsilon", ({ a, b, epsilon }) => {
+      expect(approxEqual(a, b, epsilon)).toBe(true);
+    });
+
+    it.each([
+      { a: 1, b: 1.1, epsilon: 0.01, name: "beyond tolerance" },
+      { a: 0.1 + 0.2, b: 0.3, epsilon: 0, name: "inexact with epsilon 0" },
+      { a: 1e-3, b: 2e-3, epsilon: 1e-9, name: "small but distinct" },
+      { a: 1e20, b: 2e20, epsilon: 0.1, name: "large but distinct" },
+      { a: -1e308, b: 1e308, epsilon: 1, name: "overflowing difference" },
+    ])("$name: ($a, $b) not within $epsilon", ({ a, b, epsilon }) => {
+      expect(approxEqual(a, b, epsilon)).toBe(false);
+    });
+
+    it("throws InvalidInputError for a negative epsilon", () => {
+      expect(() => approxEqual(1, 1, -1e-9)).toThrow(
+        "invalid input: epsilon must not be negative"
+      );
+    });
+
+    it.each([
+      { a: NaN, b: 1, epsilon: 0 },
+      { a: 1, b: Infinity, epsilon: 0 },
+      { a: 1, b: 1, epsilon: Infinity },
+    ])("throws InvalidInputError for ($a, $b, $epsilon)", (operands) => {
+      const { a, b, epsilon } = operands;
+
+      expect(() => approxEqual(a, b, epsilon)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("sin", () => {
+    it.each([
+      { a: 0, expected: 0, name: "zero" },
+      { a: Math.PI / 2, expected: 1, name: "pi/2" },
+      { a: -Math.PI / 2, expected: -1, name: "-pi/2" },
+      { a: Math.PI / 6, expected: 0.5, name: "pi/6" },
+    ])("$name: sin($a) = $expected", ({ a, expected }) => {
+      expect(sin(a)).toBeCloseTo(expected, 12);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => sin(NaN)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for Infinity", () => {
+      expect(() => sin(Infinity)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("cos", () => {
+    it.each([
+      { a: 0, expected: 1, name: "zero" },
+      { a: Math.PI, expected: -1, name: "pi" },
+      { a: Math.PI / 3, expected: 0.5, name: "pi/3" },
+    ])("$name: cos($a) = $expected", ({ a, expected }) => {
+      expect(cos(a)).toBeCloseTo(expected, 12);
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => cos(NaN)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("tan", () => {
+    it.each([
+      { a: 0, expected: 0, name: "zero" },
+      { a: Math.PI / 4, expected: 1, name: "pi/4" },
+      { a: -Math.PI / 4, expected: -1, name: "-pi/4" },
+    ])("$name: tan($a) = $expected", ({ a, expected }) => {
+      expect(tan(a)).toBeCloseTo(expected, 12);
+    });
+
+    it("throws InvalidInputError for -Infinity", () => {
+      expect(() => tan(-Infinity)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("log", () => {
+    it.each([
+      { a: 1, expected: 0, name: "one" },
+      { a: 10, expected: 1, name: "ten" },
+      { a: 1000, expected: 3, name: "thousand" },
+      { a: 0.01, expected: -2, name: "fraction" },
+    ])("$name: log($a) = $expected", ({ a, expected }) => {
+      expect(log(a)).toBeCloseTo(expected, 12);
+    });
+
+    it("throws InvalidInputError for zero", () => {
+      expect(() => log(0)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for negative operand", () => {
+      expect(() => log(-10)).toThrow(
+        "invalid input: logarithm requires a positive operand"
+      );
+    });
+
+    it("throws InvalidInputError for NaN", () => {
+      expect(() => log(NaN)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("ln", () => {
+    it.each([
+      { a: 1, expected: 0, name: "one" },
+      { a: Math.E, expected: 1, name: "e" },
+      { a: Math.E ** 2, expected: 2, name: "e squared" },
+    ])("$name: ln($a) = $expected", ({ a, expected }) => {
+      expect(ln(a)).toBeCloseTo(expected, 12);
+    });
+
+    it("throws InvalidInputError for zero", () => {
+      expect(() => ln(0)).toThrow(InvalidInputError);
+    });
+
+    it("throws InvalidInputError for negative operand", () => {
+      expect(() => ln(-1)).toThrow(InvalidInputError);
+    });
+  });
+
+  describe("OverflowError", () => {
+    it("is an InvalidInputError", () => {
+      const error = new OverflowError();
+
+      expect(error).toBeInstanceOf(InvalidInputError);
+      expect(error.message).toBe("invalid input: result overflowed");
+    });
   });
 
   describe("validateInputs", () => {
@@ -122,6 +991,11 @@ describe("Calculator Service", () => {
       expect(() => validateInputs(5, -Infinity)).toThrow(InvalidInputError);
     });
 
+    it("accepts a single operand", () => {
+      expect(() => validateInputs(42)).not.toThrow();
+      expect(() => validateInputs(NaN)).toThrow(InvalidInputError);
+    });
+
     it("validates error message is correct", () => {
       expect(() => validateInputs(NaN, 5)).toThrow(
         "invalid input: NaN and Infinity not allowed"
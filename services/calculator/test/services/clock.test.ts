@@ -0,0 +1,44 @@
+import { describe, it, expect } from "vitest";
+import { FakeClock, systemClock } from "../../src/services/clock";
+
+describe("Clock", () => {
+  describe("systemClock", () => {
+    it("returns the current time", () => {
+      const before = Date.now();
+      const now = systemClock.now().getTime();
+
+      expect(now).toBeGreaterThanOrEqual(before);
+      expect(now).toBeLessThanOrEqual(Date.now());
+    });
+  });
+
+  describe("FakeClock", () => {
+    it("starts at the epoch by default", () => {
+      expect(new FakeClock().now().toISOString()).toBe(
+        "1970-01-01T00:00:00.000Z"
+      );
+    });
+
+    it("returns the same time until advanced", () => {
+      const clock = new FakeClock(new Date("2024-01-01T00:00:00Z"));
+
+      expect(clock.now().getTime()).toBe(clock.now().getTime());
+      clock.advance(250);
+      expect(clock.now().toISOString()).toBe("2024-01-01T00:00:00.250Z");
+    });
+
+    it("can be set to an arbitrary time", () => {
+      const clock = new FakeClock();
+
+      clock.set(new Date("2030-05-05T05:05:05Z"));
+      expect(clock.now().toISOString()).toBe("2030-05-05T05:05:05.000Z");
+    });
+
+    it("returns copies that callers cannot mutate", () => {
+      const clock = new FakeClock(new Date("2024-01-01T00:00:00Z"));
+
+      clock.now().setFullYear(1999);
+      expect(clock.now().toISOString()).toBe("2024-01-01T00:00:00.000Z");
+    });
+  });
+});
@@ -0,0 +1,121 @@
+import { describe, it, expect } from "vitest";
+import { coalesce } from "../../src/services/coalesce";
+import { calculatorService } from "../../src/services/operations";
+import type { CalculatorService } from "../../src/services/operations";
+
+// A service whose add resolves only when release() is called, counting how
+// many times it was invoked.
+function slowService() {
+  let release = () => {};
+  const gate = new Promise<void>((resolve) => (release = resolve));
+  const fake = {
+    calls: 0,
+    release,
+    service: {
+      ...calculatorService,
+      add: async (a: number, b: number) => {
+        fake.calls++;
+        await gate;
+        return a + b;
+      },
+    } satisfies CalculatorService,
+  };
+  return fake;
+}
+
+describe("coalesce", () => {
+  it("shares one execution among concurrent identical calls", async () => {
+    const fake = slowService();
+    const service = coalesce(fake.service);
+
+    const results = Array.from({ length: 50 }, () => service.add(2, 3));
+    fake.release();
+
+    expect(await Promise.all(results)).toEqual(Array(50).fill(5));
+    expect(fake.calls).toBe(1);
+  });
+
+  it("runs calls with different operands separately", async () => {
+    const fake = slowService();
+    const service = coalesce(fake.service);
+
+    const results = [service.add(2, 3), service.add(3, 2), service.add(2, 4)];
+    fake.release();
+
+    expect(await Promise.all(results)).toEqual([5, 5, 6]);
+    expect(fake.calls).toBe(3);
+  });
+
+  it("keeps different operations apart", async () => {
+    const service = coalesce(calculatorService);
+
+    const results = await Promise.all([
+      service.add(6, 3),
+      service.subtract(6, 3),
+      service.divide(6, 3),
+    ]);
+
+    expect(results).toEqual([9, 3, 2]);
+  });
+
+  it("keeps -0 apart from 0", async () => {
+    const service = coalesce(calculatorService);
+
+    const [negative, positive] = await Promise.all([
+      service.add(-0, -0),
+      service.add(0, 0),
+    ]);
+
+    expect(Object.is(negative, -0)).toBe(true);
+    expect(Object.is(positive, 0)).toBe(true);
+  });
+
+  it("runs again once the previous execution has settled", async () => {
+    const fake = slowService();
+    const service = coalesce(fake.service);
+
+    const first = service.add(2, 3);
+    fake.release();
+    await first;
+    await service.add(2, 3);
+
+    expect(fake.calls).toBe(2);
+  });
+
+  it("shares a failure with every waiting caller", async () => {
+    let calls = 0;
+    const service = coalesce({
+      ...calculatorService,
+      multiply: () => {
+        calls++;
+        throw new Error("boom");
+      },
+    });
+
+    const results = [service.multiply(1, 2), service.multiply(1, 2)];
+
+    await expect(results[0]).rejects.toThrow("boom");
+    await expect(results[1]).rejects.toThrow("boom");
+    expect(calls).toBe(1);
+  });
+
+  it("coalesces list operations by their elements", async () => {
+    let calls = 0;
+    const service = coalesce({
+      ...calculatorService,
+      addMany: (numbers) => {
+        calls++;
+        return numbers.reduce((sum, n) => sum + n, 0);
+      },
+    });
+
+    const results = await Promise.all([
+      service.addMany([1, 2, 3]),
+      service.addMany([1, 2, 3]),
+      service.addMany([1, 2]),
+    ]);
+
+    expect(results).toEqual([6, 6, 3]);
+    expect(calls).toBe(2);
+  });
+});
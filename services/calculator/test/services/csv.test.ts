@@ -0,0 +1,68 @@
+import { describe, it, expect } from "vitest";
+import { formatCsv, parseCsv } from "../../src/services/csv";
+
+describe("CSV Service", () => {
+  describe("parseCsv", () => {
+    it("splits records and fields", () => {
+      expect(parseCsv("1,2\n3,4\n")).toEqual([
+        ["1", "2"],
+        ["3", "4"],
+      ]);
+    });
+
+    it("accepts CRLF line endings and a missing final newline", () => {
+      expect(parseCsv("1,2\r\n3,4")).toEqual([
+        ["1", "2"],
+        ["3", "4"],
+      ]);
+    });
+
+    it("skips blank lines", () => {
+      expect(parseCsv("1,2\n\n3,4\n\n")).toEqual([
+        ["1", "2"],
+        ["3", "4"],
+      ]);
+    });
+
+    it("unquotes quoted fields", () => {
+      expect(parseCsv('"1,5","say ""hi"""\n')).toEqual([
+        ["1,5", 'say "hi"'],
+      ]);
+    });
+
+    it("keeps newlines inside quoted fields", () => {
+      expect(parseCsv('"a\nb",c\n')).toEqual([["a\nb", "c"]]);
+    });
+
+    it("keeps empty fields", () => {
+      expect(parseCsv("1,,\n")).toEqual([["1", "", ""]]);
+    });
+
+    it("returns no records for an empty body", () => {
+      expect(parseCsv("")).toEqual([]);
+    });
+  });
+
+  describe("formatCsv", () => {
+    it("joins records with CRLF", () => {
+      expect(
+        formatCsv([
+          ["a", "b"],
+          ["1", "2"],
+        ])
+      ).toBe("a,b\r\n1,2\r\n");
+    });
+
+    it("quotes fields that need it", () => {
+      expect(formatCsv([["1,5", 'say "hi"', "a\nb"]])).toBe(
+        '"1,5","say ""hi""","a\nb"\r\n'
+      );
+    });
+
+    it("round-trips through parseCsv", () => {
+      const records = [["x,y", '"', "plain"]];
+
+      expect(parseCsv(formatCsv(records))).toEqual(records);
+    });
+  });
+});
@@ -0,0 +1,100 @@
+import { describe, it, expect } from "vitest";
+import {
+  deadlineSignal,
+  isTimeout,
+  sleep,
+  untilAborted,
+} from "../../src/services/deadline";
+
+describe("untilAborted", () => {
+  it("settles with the work when it finishes first", async () => {
+    const signal = AbortSignal.timeout(1000);
+
+    await expect(untilAborted(Promise.resolve(5), signal)).resolves.toBe(5);
+  });
+
+  it("passes through the work's rejection", async () => {
+    const signal = AbortSignal.timeout(1000);
+    const work = Promise.reject(new Error("boom"));
+
+    await expect(untilAborted(work, signal)).rejects.toThrow("boom");
+  });
+
+  it("rejects with the signal's reason when it aborts first", async () => {
+    const controller = new AbortController();
+    const never = new Promise<number>(() => {});
+
+    const result = untilAborted(never, controller.signal);
+    controller.abort(new Error("cancelled"));
+
+    await expect(result).rejects.toThrow("cancelled");
+  });
+
+  it("rejects at once if the signal has already aborted", async () => {
+    const signal = AbortSignal.abort(new Error("too late"));
+
+    await expect(untilAborted(Promise.resolve(1), signal)).rejects.toThrow(
+      "too late"
+    );
+  });
+});
+
+describe("isTimeout", () => {
+  it("recognizes a timeout signal's reason", async () => {
+    const signal = AbortSignal.timeout(1);
+    await new Promise((resolve) =>
+      signal.addEventListener("abort", resolve, { once: true })
+    );
+
+    expect(isTimeout(signal.reason)).toBe(true);
+  });
+
+  it("rejects other errors", () => {
+    expect(isTimeout(new Error("boom"))).toBe(false);
+  });
+});
+
+describe("sleep", () => {
+  it("resolves after the given time", async () => {
+    const start = Date.now();
+
+    await sleep(30, new AbortController().signal);
+
+    expect(Date.now() - start).toBeGreaterThanOrEqual(25);
+  });
+
+  it("rejects with the signal's reason when it aborts first", async () => {
+    const controller = new AbortController();
+
+    const result = sleep(10_000, controller.signal);
+    controller.abort(new Error("cancelled"));
+
+    await expect(result).rejects.toThrow("cancelled");
+  });
+
+  it("rejects at once if the signal has already aborted", async () => {
+    const signal = AbortSignal.abort(new Error("too late"));
+
+    await expect(sleep(10_000, signal)).rejects.toThrow("too late");
+  });
+});
+
+describe("deadlineSignal", () => {
+  it("aborts with a timeout when the deadline passes", async () => {
+    const signal = deadlineSignal(10, new AbortController().signal);
+
+    const error = await sleep(1000, signal).catch((reason) => reason);
+
+    expect(isTimeout(error)).toBe(true);
+  });
+
+  it("aborts with the client's reason when it disconnects", async () => {
+    const client = new AbortController();
+    const signal = deadlineSignal(10_000, client.signal);
+
+    client.abort(new Error("gone"));
+
+    expect(signal.aborted).toBe(true);
+    expect(isTimeout(signal.reason)).toBe(false);
+  });
+});
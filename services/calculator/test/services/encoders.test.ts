@@ -0,0 +1,203 @@
+import { describe, it, expect } from "vitest";
+import {
+  canonicalJson,
+  canonicalJsonEncoder,
+  DEFAULT_ENCODERS,
+  jsonEncoder,
+  messagePackEncoder,
+  plainTextEncoder,
+  renameKeys,
+  restoreKeys,
+  selectEncoder,
+  withSignificantDigits,
+} from "../../src/services/encoders";
+
+describe("selectEncoder", () => {
+  it.each([
+    { accept: undefined, expected: jsonEncoder },
+    { accept: "", expected: jsonEncoder },
+    { accept: "*/*", expected: jsonEncoder },
+    { accept: "application/json", expected: jsonEncoder },
+    { accept: "application/msgpack", expected: messagePackEncoder },
+    { accept: "Application/MsgPack", expected: messagePackEncoder },
+    { accept: "text/html", expected: jsonEncoder },
+    { accept: "text/plain", expected: plainTextEncoder },
+    { accept: "text/*", expected: plainTextEncoder },
+    {
+      accept: "text/html,application/xhtml+xml,*/*;q=0.8",
+      expected: jsonEncoder,
+    },
+    {
+      accept: "application/json;q=0.5, application/msgpack",
+      expected: messagePackEncoder,
+    },
+    {
+      accept: "application/msgpack;q=0.2, */*;q=0.8",
+      expected: jsonEncoder,
+    },
+    {
+      accept: "application/*;q=0.9, application/json;q=0",
+      expected: messagePackEncoder,
+    },
+  ])("selects $expected.contentType for '$accept'", ({ accept, expected }) => {
+    expect(selectEncoder(accept, DEFAULT_ENCODERS)).toBe(expected);
+  });
+
+  it("falls back to the first registered encoder", () => {
+    const encoders = [messagePackEncoder, jsonEncoder];
+
+    expect(selectEncoder(undefined, encoders)).toBe(messagePackEncoder);
+    expect(selectEncoder("image/png", encoders)).toBe(messagePackEncoder);
+  });
+});
+
+describe("jsonEncoder", () => {
+  it("encodes JSON text", () => {
+    expect(jsonEncoder.encode({ result: 3 })).toBe('{"result":3}');
+  });
+});
+
+describe("canonicalJson", () => {
+  it.each([
+    { data: { b: 1, a: 2 }, expected: '{"a":2,"b":1}' },
+    { data: { "10": 1, "2": 2, a: 3 }, expected: '{"10":1,"2":2,"a":3}' },
+    { data: { é: 1, z: 2, Z: 3 }, expected: '{"Z":3,"z":2,"é":1}' },
+    {
+      data: { b: [{ d: 1, c: 2 }], a: null },
+      expected: '{"a":null,"b":[{"c":2,"d":1}]}',
+    },
+    { data: { a: undefined, b: 1 }, expected: '{"b":1}' },
+    { data: [undefined, 1e21, -0], expected: "[null,1e+21,0]" },
+    {
+      data: { at: new Date(0) },
+      expected: '{"at":"1970-01-01T00:00:00.000Z"}',
+    },
+    { data: "line\n", expected: '"line\\n"' },
+  ])("encodes $data as $expected", ({ data, expected }) => {
+    expect(canonicalJson(data)).toBe(expected);
+  });
+
+  it("encodes equal data identically whatever its key order", () => {
+    const response = {
+      result: 0.1 + 0.2,
+      exact: "3",
+      meta: { requestId: "req-1", durationMs: 4 },
+    };
+    const reordered = {
+      meta: { durationMs: 4, requestId: "req-1" },
+      exact: "3",
+      result: 0.1 + 0.2,
+    };
+
+    const encoded = canonicalJsonEncoder.encode(response);
+    for (let i = 0; i < 10; i++) {
+      expect(canonicalJsonEncoder.encode(response)).toBe(encoded);
+    }
+    expect(canonicalJsonEncoder.encode(reordered)).toBe(encoded);
+  });
+});
+
+describe("renameKeys", () => {
+  const encoder = renameKeys(jsonEncoder, { result: "value" });
+
+  it("keeps the wrapped content type", () => {
+    expect(encoder.contentType).toBe("application/json");
+  });
+
+  it.each([
+    { data: { result: 5 }, expected: '{"value":5}' },
+    { data: { result: 5, exact: "5" }, expected: '{"value":5,"exact":"5"}' },
+    {
+      data: { results: [{ result: 1 }] },
+      expected: '{"results":[{"result":1}]}',
+    },
+    { data: { constructor: 1 }, expected: '{"constructor":1}' },
+    { data: [{ result: 1 }], expected: '[{"result":1}]' },
+    { data: 5, expected: "5" },
+  ])("encodes $data as $expected", ({ data, expected }) => {
+    expect(encoder.encode(data)).toBe(expected);
+  });
+});
+
+describe("restoreKeys", () => {
+  const keys = { result: "value" };
+
+  it.each([
+    { data: { value: 5 }, expected: { result: 5 } },
+    { data: { value: 5, exact: "5" }, expected: { result: 5, exact: "5" } },
+    { data: { code: "timeout" }, expected: { code: "timeout" } },
+    { data: [{ value: 1 }], expected: [{ value: 1 }] },
+    { data: null, expected: null },
+  ])("restores $data to $expected", ({ data, expected }) => {
+    expect(restoreKeys(data, keys)).toEqual(expected);
+  });
+
+  it("undoes renameKeys", () => {
+    const data = { result: 5, exact: "5" };
+
+    const encoded = renameKeys(jsonEncoder, keys).encode(data) as string;
+
+    expect(restoreKeys(JSON.parse(encoded), keys)).toEqual(data);
+  });
+});
+
+describe("withSignificantDigits", () => {
+  const encoder = withSignificantDigits(jsonEncoder, 6);
+
+  it.each([
+    { data: { result: 0.1 + 0.2 }, expected: '{"result":0.3}' },
+    { data: { result: 2 / 3 }, expected: '{"result":0.666667}' },
+    { data: { result: 123456789 }, expected: '{"result":123457000}' },
+    { data: { result: 1.5e-10 }, expected: '{"result":1.5e-10}' },
+    { data: { result: -0.000001 }, expected: '{"result":-0.000001}' },
+    {
+      data: { result: 1 / 3, exact: "1/3" },
+      expected: '{"result":0.333333,"exact":"1/3"}',
+    },
+    { data: { result: "Infinity" }, expected: '{"result":"Infinity"}' },
+    { data: { quotient: 1 / 3 }, expected: `{"quotient":${1 / 3}}` },
+  ])("encodes $data as $expected", ({ data, expected }) => {
+    expect(encoder.encode(data)).toBe(expected);
+  });
+
+  it("leaves the data it is given unchanged", () => {
+    const data = { result: 0.1 + 0.2 };
+
+    encoder.encode(data);
+
+    expect(data.result).toBe(0.30000000000000004);
+  });
+
+  it("rounds for other encodings too", () => {
+    const text = withSignificantDigits(plainTextEncoder, 3);
+
+    expect(text.encode({ result: 2 / 3 })).toBe("0.667\n");
+  });
+
+  it.each([0, 18, 1.5])("rejects %d digits", (digits) => {
+    expect(() => withSignificantDigits(jsonEncoder, digits)).toThrow(
+      RangeError
+    );
+  });
+});
+
+describe("plainTextEncoder", () => {
+  it.each([
+    { data: { result: 5 }, expected: "5\n" },
+    { data: { result: -0.5 }, expected: "-0.5\n" },
+    {
+      data: { result: 3, exact: "9007199254740993" },
+      expected: "9007199254740993\n",
+    },
+    {
+      data: { error: "invalid input: division by zero", code: "invalid_input" },
+      expected: "invalid input: division by zero\n",
+    },
+    {
+      data: { quotient: 3, remainder: 1 },
+      expected: '{"quotient":3,"remainder":1}\n',
+    },
+  ])("encodes $data as $expected", ({ data, expected }) => {
+    expect(plainTextEncoder.encode(data)).toBe(expected);
+  });
+});
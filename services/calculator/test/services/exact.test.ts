@@ -0,0 +1,187 @@
+import { describe, it, expect } from "vitest";
+import {
+  DivisionByZeroError,
+  InvalidInputError,
+  MAX_FACTORIAL_OPERAND,
+  NonIntegerError,
+} from "../../src/services/calculator";
+import {
+  exactAdd,
+  exactSubtract,
+  exactMultiply,
+  exactDivide,
+  exactFactorial,
+  isExactQuotient,
+  Rational,
+  toRational,
+  parseExactInteger,
+  MAX_EXACT_DIGITS,
+} from "../../src/services/exact";
+
+describe("Exact Service", () => {
+  describe("parseExactInteger", () => {
+    it.each([
+      { literal: "0", expected: 0n },
+      { literal: "-7", expected: -7n },
+      { literal: "9007199254740993", expected: 9007199254740993n },
+    ])("parses $literal", ({ literal, expected }) => {
+      expect(parseExactInteger(literal)).toBe(expected);
+    });
+
+    it.each(["1.5", "1e3", "-0.0", "01"])(
+      "rejects non-integer literal %s",
+      (literal) => {
+        expect(() => parseExactInteger(literal)).toThrow(
+          "invalid input: exact mode requires integer operands"
+        );
+      }
+    );
+
+    it("rejects operands longer than the digit limit", () => {
+      expect(() => parseExactInteger("9".repeat(MAX_EXACT_DIGITS + 1))).toThrow(
+        InvalidInputError
+      );
+      expect(() =>
+        parseExactInteger("-" + "9".repeat(MAX_EXACT_DIGITS))
+      ).not.toThrow();
+    });
+  });
+
+  describe("exact arithmetic", () => {
+    it("adds beyond 2^53 without losing precision", () => {
+      expect(exactAdd(9007199254740993n, 2n)).toBe(9007199254740995n);
+    });
+
+    it("subtracts beyond 2^53 without losing precision", () => {
+      expect(exactSubtract(9007199254740993n, 9007199254740992n)).toBe(1n);
+    });
+
+    it("multiplies beyond 2^53 without losing precision", () => {
+      expect(exactMultiply(9007199254740993n, 3n)).toBe(27021597764222979n);
+    });
+  });
+
+  describe("exactDivide", () => {
+    it.each([
+      { a: 1n, b: 3n, expected: "1/3" },
+      { a: 2n, b: 6n, expected: "1/3" },
+      { a: 6n, b: 3n, expected: "2" },
+      { a: 0n, b: 5n, expected: "0" },
+      { a: -1n, b: 3n, expected: "-1/3" },
+      { a: 1n, b: -3n, expected: "-1/3" },
+      { a: -4n, b: -6n, expected: "2/3" },
+    ])("$a / $b = $expected", ({ a, b, expected }) => {
+      expect(exactDivide(a, b).toString()).toBe(expected);
+    });
+
+    it("keeps precision beyond 2^53", () => {
+      expect(exactDivide(9007199254740993n, 2n).toString()).toBe(
+        "9007199254740993/2"
+      );
+    });
+
+    it("throws DivisionByZeroError for a zero divisor", () => {
+      expect(() => exactDivide(1n, 0n)).toThrow(DivisionByZeroError);
+    });
+  });
+
+  describe("exactFactorial", () => {
+    it.each([
+      { n: 0, expected: 1n },
+      { n: 1, expected: 1n },
+      { n: 5, expected: 120n },
+      { n: 25, expected: 15511210043330985984000000n },
+    ])("$n! = $expected", ({ n, expected }) => {
+      expect(exactFactorial(n)).toBe(expected);
+    });
+
+    it("accepts operands up to the cap", () => {
+      expect(exactFactorial(MAX_FACTORIAL_OPERAND).toString()).toHaveLength(
+        35660
+      );
+    });
+
+    it("rejects operands above the cap", () => {
+      expect(() => exactFactorial(MAX_FACTORIAL_OPERAND + 1)).toThrow(
+        `invalid input: factorial operand exceeds ${MAX_FACTORIAL_OPERAND}`
+      );
+    });
+
+    it("rejects negative operands", () => {
+      expect(() => exactFactorial(-1)).toThrow(
+        "invalid input: factorial requires a non-negative operand"
+      );
+    });
+
+    it("rejects non-integer operands", () => {
+      expect(() => exactFactorial(2.5)).toThrow(NonIntegerError);
+    });
+  });
+
+  describe("Rational", () => {
+    it("approximates its value as a number", () => {
+      expect(new Rational(1n, 3n).toNumber()).toBeCloseTo(1 / 3, 15);
+    });
+
+    it("reduces to lowest terms with a positive denominator", () => {
+      const rational = new Rational(10n, -4n);
+
+      expect(rational.numerator).toBe(-5n);
+      expect(rational.denominator).toBe(2n);
+    });
+
+    it("equals another of the same value", () => {
+      expect(new Rational(2n, 4n).equals(new Rational(-1n, -2n))).toBe(true);
+      expect(new Rational(1n, 2n).equals(new Rational(1n, 3n))).toBe(false);
+    });
+  });
+
+  describe("toRational", () => {
+    it.each([
+      [0, "0"],
+      [-0, "0"],
+      [5, "5"],
+      [-2.5, "-5/2"],
+      [0.1, "3602879701896397/36028797018963968"],
+      [2 ** 60, "1152921504606846976"],
+      [Number.MIN_VALUE, `1/${2n ** 1074n}`],
+    ])("gives the exact value of %s", (x, expected) => {
+      expect(toRational(x).toString()).toBe(expected);
+    });
+
+    it("round-trips through toNumber", () => {
+      for (const x of [Math.PI, -1e-10, 1e300]) {
+        expect(toRational(x).toNumber()).toBe(x);
+      }
+    });
+
+    it.each([NaN, Infinity, -Infinity])("rejects %s", (x) => {
+      expect(() => toRational(x)).toThrow(RangeError);
+    });
+  });
+
+  describe("isExactQuotient", () => {
+    it.each([
+      [10, 2],
+      [10, 4],
+      [-7, 8],
+      [1, 2 ** 60],
+      [0.75, 0.25],
+    ])("accepts %s / %s", (a, b) => {
+      expect(isExactQuotient(a, b, a / b)).toBe(true);
+    });
+
+    it.each([
+      [10, 3],
+      [1, 10],
+      [2, 7],
+      [0.3, 0.1],
+    ])("rejects %s / %s, which rounds", (a, b) => {
+      expect(isExactQuotient(a, b, a / b)).toBe(false);
+    });
+
+    it("rejects a quotient other than a / b", () => {
+      expect(isExactQuotient(10, 4, 2)).toBe(false);
+    });
+  });
+});
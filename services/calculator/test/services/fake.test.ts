@@ -0,0 +1,115 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { InvalidInputError } from "../../src/services/calculator";
+import { FakeCalculator } from "../../src/services/fake";
+
+function post(app: ReturnType<typeof createApp>, path: string, body: unknown) {
+  return app.fetch(
+    new Request(`http://localhost${path}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+    })
+  );
+}
+
+describe("FakeCalculator", () => {
+  it("computes real results by default", async () => {
+    const fake = new FakeCalculator();
+
+    expect(await fake.add(2, 3)).toBe(5);
+    expect(await fake.addMany([1, 2, 3])).toBe(6);
+    expect(await fake.ln(1)).toBe(0);
+  });
+
+  it("records each call with its operands", async () => {
+    const fake = new FakeCalculator();
+    const signal = new AbortController().signal;
+
+    await fake.multiply(4, 5, signal);
+    await fake.weightedSum(1, 2, 3, 4);
+
+    expect(fake.calls).toEqual([
+      { operation: "multiply", operands: [4, 5], signal },
+      { operation: "weightedSum", operands: [1, 2, 3, 4], signal: undefined },
+    ]);
+  });
+
+  it("returns programmed values", async () => {
+    const fake = new FakeCalculator()
+      .returns("add", 42)
+      .returns("subtract", ([a, b]) => b - a);
+
+    expect(await fake.add(1, 1)).toBe(42);
+    expect(await fake.subtract(1, 10)).toBe(9);
+    expect(await fake.multiply(2, 3)).toBe(6);
+  });
+
+  it("throws programmed errors", () => {
+    const fake = new FakeCalculator().throws("sin", new Error("boom"));
+
+    expect(() => fake.sin(0)).toThrow("boom");
+    expect(fake.callsTo("sin")).toEqual([[0]]);
+  });
+
+  it("forgets calls and behavior on reset", async () => {
+    const fake = new FakeCalculator().returns("add", 0);
+    await fake.add(1, 2);
+
+    fake.reset();
+
+    expect(fake.calls).toEqual([]);
+    expect(await fake.add(1, 2)).toBe(3);
+  });
+
+  describe("injected into createApp", () => {
+    it("serves the programmed result and records the call", async () => {
+      const fake = new FakeCalculator().returns("divide", 0.5);
+      const app = createApp({ service: fake });
+
+      const response = await post(app, "/divide", { a: 7, b: 3 });
+
+      expect(await response.json()).toEqual({ result: 0.5 });
+      expect(fake.callsTo("divide")).toEqual([[7, 3]]);
+      expect(fake.calls[0].signal).toBeInstanceOf(AbortSignal);
+    });
+
+    it("maps a programmed InvalidInputError to 400", async () => {
+      const fake = new FakeCalculator().throws(
+        "hypot",
+        new InvalidInputError("invalid input: nope")
+      );
+      const app = createApp({ service: fake });
+
+      const response = await post(app, "/hypot", { a: 3, b: 4 });
+
+      expect(response.status).toBe(400);
+      const json = await response.json();
+      expect(json).toMatchObject({ error: "invalid input: nope" });
+    });
+
+    it("is not called when validation rejects the request", async () => {
+      const fake = new FakeCalculator();
+      const app = createApp({ service: fake });
+
+      await post(app, "/ln", { a: -1 });
+      await post(app, "/add", { a: 1 });
+
+      expect(fake.calls).toEqual([]);
+    });
+
+    it("sees every item of a batch", async () => {
+      const fake = new FakeCalculator();
+      const app = createApp({ service: fake });
+
+      await post(app, "/batch", {
+        operations: [
+          { operation: "add", a: 1, b: 2 },
+          { operation: "cos", a: 0 },
+        ],
+      });
+
+      expect(fake.calls.map((call) => call.operation)).toEqual(["add", "cos"]);
+    });
+  });
+});
@@ -0,0 +1,49 @@
+import { describe, it, expect } from "vitest";
+import { CalculatorClient } from "../../src/client";
+import { createApp } from "../../src/index";
+import { checkFleet } from "../../src/services/fleet";
+
+const node = createApp();
+const up = new CalculatorClient("http://node-1", (request) =>
+  node.fetch(request)
+);
+
+describe("checkFleet", () => {
+  it("reports each node by its base URL", async () => {
+    const failing = new CalculatorClient("http://node-2", () =>
+      Promise.reject(new Error("connection refused"))
+    );
+
+    expect(await checkFleet([up, failing], 1000)).toEqual([
+      { url: "http://node-1", status: "ok", latencyMs: expect.any(Number) },
+      {
+        url: "http://node-2",
+        status: "down",
+        latencyMs: expect.any(Number),
+        error: "connection refused",
+      },
+    ]);
+  });
+
+  it("marks a node down when it answers with an error status", async () => {
+    const unhealthy = new CalculatorClient("http://node-2", () =>
+      Promise.resolve(new Response("Bad Gateway", { status: 502 }))
+    );
+
+    const [result] = await checkFleet([unhealthy], 1000);
+
+    expect(result.status).toBe("down");
+    expect(result.error).toBe("Calculator responded with 502");
+  });
+
+  it("marks a node down when it outlives the timeout", async () => {
+    const hung = new CalculatorClient(
+      "http://node-2",
+      () => new Promise<Response>(() => {})
+    );
+
+    const results = await checkFleet([up, hung], 10);
+
+    expect(results.map((result) => result.status)).toEqual(["ok", "down"]);
+  });
+});
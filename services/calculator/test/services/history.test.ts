@@ -0,0 +1,91 @@
+import { describe, it, expect } from "vitest";
+import { FakeClock } from "../../src/services/clock";
+import { History, recordHistory } from "../../src/services/history";
+import { calculatorService } from "../../src/services/operations";
+
+describe("History", () => {
+  it("starts empty", () => {
+    expect(new History(new FakeClock()).entries()).toEqual([]);
+  });
+
+  it("lists entries newest first with timestamps", () => {
+    const clock = new FakeClock(new Date("2024-01-01T00:00:00.000Z"));
+    const history = new History(clock);
+
+    history.record({ operation: "add", operands: [1, 2], result: 3 });
+    clock.advance(1000);
+    history.record({ operation: "sin", operands: [0], result: 0 });
+
+    expect(history.entries()).toEqual([
+      {
+        operation: "sin",
+        operands: [0],
+        result: 0,
+        timestamp: "2024-01-01T00:00:01.000Z",
+      },
+      {
+        operation: "add",
+        operands: [1, 2],
+        result: 3,
+        timestamp: "2024-01-01T00:00:00.000Z",
+      },
+    ]);
+  });
+
+  it("keeps only the most recent entries once full", () => {
+    const history = new History(new FakeClock(), 3);
+
+    for (let i = 1; i <= 7; i++) {
+      history.record({ operation: "add", operands: [i, 0], result: i });
+    }
+
+    expect(history.entries().map((entry) => entry.result)).toEqual([7, 6, 5]);
+  });
+
+  it("rejects a size that is not a positive integer", () => {
+    expect(() => new History(new FakeClock(), 0)).toThrow(RangeError);
+    expect(() => new History(new FakeClock(), 1.5)).toThrow(RangeError);
+  });
+});
+
+describe("recordHistory", () => {
+  it("records results of the wrapped service", async () => {
+    const history = new History(new FakeClock());
+    const service = recordHistory(calculatorService, history);
+
+    expect(await service.multiply(3, 4)).toBe(12);
+    expect(await service.addMany([1, 2, 3])).toBe(6);
+
+    expect(history.entries()).toMatchObject([
+      { operation: "addMany", operands: [1, 2, 3], result: 6 },
+      { operation: "multiply", operands: [3, 4], result: 12 },
+    ]);
+  });
+
+  it("skips failed operations by default", async () => {
+    const history = new History(new FakeClock());
+    const service = recordHistory(calculatorService, history);
+
+    await expect(
+      service.add(Number.MAX_VALUE, Number.MAX_VALUE)
+    ).rejects.toThrow("invalid input: result overflowed");
+
+    expect(history.entries()).toEqual([]);
+  });
+
+  it("records failed operations when asked to", async () => {
+    const history = new History(new FakeClock());
+    const service = recordHistory(calculatorService, history, true);
+
+    await expect(service.divide(1, 0)).rejects.toThrow();
+
+    expect(history.entries()).toMatchObject([
+      {
+        operation: "divide",
+        operands: [1, 0],
+        error: "invalid input: division by zero",
+      },
+    ]);
+    expect(history.entries()[0].result).toBeUndefined();
+  });
+});
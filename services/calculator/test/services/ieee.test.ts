@@ -0,0 +1,54 @@
+import { describe, it, expect } from "vitest";
+import {
+  fromIeeeJson,
+  IEEE_OPERATIONS,
+  toIeeeJson,
+} from "../../src/services/ieee";
+
+describe("toIeeeJson", () => {
+  it.each([
+    { value: NaN, expected: "NaN" },
+    { value: Infinity, expected: "Infinity" },
+    { value: -Infinity, expected: "-Infinity" },
+    { value: 1.5, expected: 1.5 },
+  ])("writes $value as $expected", ({ value, expected }) => {
+    expect(toIeeeJson(value)).toBe(expected);
+  });
+
+  it("writes negative zero as 0", () => {
+    expect(Object.is(toIeeeJson(-0), 0)).toBe(true);
+  });
+});
+
+describe("fromIeeeJson", () => {
+  it.each([
+    { value: "NaN", expected: NaN },
+    { value: "Infinity", expected: Infinity },
+    { value: "-Infinity", expected: -Infinity },
+    { value: 2, expected: 2 },
+  ])("reads $value", ({ value, expected }) => {
+    expect(fromIeeeJson(value)).toBe(expected);
+  });
+
+  it.each(["nan", "inf", "1", null, true])("rejects %j", (value) => {
+    expect(fromIeeeJson(value)).toBeUndefined();
+  });
+});
+
+describe("IEEE_OPERATIONS", () => {
+  it.each([
+    { name: "divide", operands: [1, 0], expected: Infinity },
+    { name: "divide", operands: [-1, 0], expected: -Infinity },
+    { name: "divide", operands: [0, 0], expected: NaN },
+    { name: "multiply", operands: [1e308, 10], expected: Infinity },
+    { name: "subtract", operands: [Infinity, Infinity], expected: NaN },
+    { name: "ln", operands: [-1], expected: NaN },
+    { name: "log", operands: [0], expected: -Infinity },
+  ] as const)(
+    "$name($operands) is $expected",
+    ({ name, operands, expected }) => {
+      const operation = IEEE_OPERATIONS[name] as (...args: number[]) => number;
+      expect(operation(...operands)).toBe(expected);
+    }
+  );
+});
@@ -0,0 +1,154 @@
+import { describe, it, expect } from "vitest";
+import { createApp } from "../../src/index";
+import { Lifecycle } from "../../src/services/lifecycle";
+
+describe("Lifecycle", () => {
+  it("runs hooks in reverse registration order", async () => {
+    const ran: string[] = [];
+    const lifecycle = new Lifecycle()
+      .onShutdown({ name: "metrics", run: () => ran.push("metrics") })
+      .onShutdown({ name: "audit", run: () => ran.push("audit") });
+
+    const results = await lifecycle.shutdown();
+
+    expect(ran).toEqual(["audit", "metrics"]);
+    expect(results).toEqual([
+      { name: "audit", status: "ok" },
+      { name: "metrics", status: "ok" },
+    ]);
+  });
+
+  it("waits for each hook before starting the next", async () => {
+    const ran: string[] = [];
+    const lifecycle = new Lifecycle()
+      .onShutdown({ name: "first", run: () => ran.push("first") })
+      .onShutdown({
+        name: "second",
+        run: async () => {
+          await new Promise((resolve) => setTimeout(resolve, 10));
+          ran.push("second");
+        },
+      });
+
+    await lifecycle.shutdown();
+
+    expect(ran).toEqual(["second", "first"]);
+  });
+
+  it("abandons a hook at its timeout and runs the rest", async () => {
+    let aborted = false;
+    const lifecycle = new Lifecycle()
+      .onShutdown({ name: "cache", run: () => {} })
+      .onShutdown({
+        name: "stuck",
+        timeoutMs: 10,
+        run: (signal) => {
+          signal.addEventListener("abort", () => (aborted = true));
+          return new Promise(() => {});
+        },
+      });
+
+    const results = await lifecycle.shutdown();
+
+    expect(aborted).toBe(true);
+    expect(results).toMatchObject([
+      { name: "stuck", status: "failed" },
+      { name: "cache", status: "ok" },
+    ]);
+    expect(results[0].error).toMatch(/timeout/);
+  });
+
+  it("reports a failing hook and runs the rest", async () => {
+    const lifecycle = new Lifecycle()
+      .onShutdown({ name: "cache", run: () => {} })
+      .onShutdown({
+        name: "audit",
+        run: () => Promise.reject(new Error("disk full")),
+      })
+      .onShutdown({
+        name: "metrics",
+        run: () => {
+          throw new Error("flush failed");
+        },
+      });
+
+    expect(await lifecycle.shutdown()).toEqual([
+      { name: "metrics", status: "failed", error: "flush failed" },
+      { name: "audit", status: "failed", error: "disk full" },
+      { name: "cache", status: "ok" },
+    ]);
+  });
+
+  it("runs hooks only once", async () => {
+    let runs = 0;
+    const lifecycle = new Lifecycle().onShutdown({
+      name: "audit",
+      run: () => runs++,
+    });
+
+    const [first, second] = await Promise.all([
+      lifecycle.shutdown(),
+      lifecycle.shutdown(),
+    ]);
+
+    expect(runs).toBe(1);
+    expect(second).toBe(first);
+  });
+
+  it("refuses hooks once shutdown has begun", async () => {
+    const lifecycle = new Lifecycle();
+    await lifecycle.shutdown();
+
+    expect(() => lifecycle.onShutdown({ name: "late", run: () => {} })).toThrow(
+      "cannot register a shutdown hook after shutdown"
+    );
+  });
+
+  it("does nothing without hooks", async () => {
+    expect(await new Lifecycle().shutdown()).toEqual([]);
+  });
+
+  describe("with createApp", () => {
+    function postAdd(app: ReturnType<typeof createApp>) {
+      return app.request("/add", {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          "Idempotency-Key": "key-1",
+        },
+        body: '{"a": 2, "b": 3}',
+      });
+    }
+
+    it("registers the cache and idempotency store", async () => {
+      const lifecycle = new Lifecycle();
+      createApp({ lifecycle, cache: { ttlMs: 1000 } });
+
+      expect(await lifecycle.shutdown()).toEqual([
+        { name: "idempotency", status: "ok" },
+        { name: "cache", status: "ok" },
+      ]);
+    });
+
+    it("leaves the cache out when it is off", async () => {
+      const lifecycle = new Lifecycle();
+      createApp({ lifecycle });
+
+      expect(await lifecycle.shutdown()).toEqual([
+        { name: "idempotency", status: "ok" },
+      ]);
+    });
+
+    it("forgets idempotency keys at shutdown", async () => {
+      const lifecycle = new Lifecycle();
+      const app = createApp({ lifecycle });
+      await postAdd(app);
+
+      await lifecycle.shutdown();
+      const response = await postAdd(app);
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get("Idempotent-Replayed")).toBeNull();
+    });
+  });
+});
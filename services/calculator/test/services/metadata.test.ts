@@ -0,0 +1,55 @@
+import { describe, it, expect } from "vitest";
+import {
+  createDefaultOperationRegistry,
+  OperationRegistry,
+} from "../../src/services/metadata";
+import { OPERATION_NAMES } from "../../src/services/operations";
+import type { OperationMetadata } from "../../src/services/metadata";
+
+const double: OperationMetadata = {
+  name: "double",
+  path: "/double",
+  description: "Twice a",
+  arity: 1,
+  operands: ["a"],
+  constraints: [],
+  example: { request: { a: 2 }, response: { result: 4 } },
+};
+
+describe("OperationRegistry", () => {
+  it("describes registered operations by name", () => {
+    const registry = new OperationRegistry().register(double);
+
+    expect(registry.describe("double")).toBe(double);
+    expect(registry.describe("triple")).toBeUndefined();
+    expect(registry.names()).toEqual(["double"]);
+  });
+
+  it("replaces an operation registered again", () => {
+    const registry = new OperationRegistry()
+      .register(double)
+      .register({ ...double, description: "2a" });
+
+    expect(registry.describe("double")?.description).toBe("2a");
+    expect(registry.names()).toEqual(["double"]);
+  });
+
+  it("ignores inherited property names", () => {
+    expect(new OperationRegistry().describe("constructor")).toBeUndefined();
+  });
+});
+
+describe("createDefaultOperationRegistry", () => {
+  const registry = createDefaultOperationRegistry();
+
+  it.each(OPERATION_NAMES)("describes the named operation %s", (name) => {
+    expect(registry.describe(name)?.name).toBe(name);
+  });
+
+  it("gives every operation as many operand fields as its arity", () => {
+    for (const name of registry.names()) {
+      const { arity, operands } = registry.describe(name)!;
+      expect(operands).toHaveLength(arity === "variadic" ? 1 : arity);
+    }
+  });
+});
@@ -0,0 +1,119 @@
+import { describe, it, expect } from "vitest";
+import {
+  Counter,
+  Histogram,
+  Metrics,
+  OperationCounter,
+} from "../../src/services/metrics";
+
+describe("Histogram", () => {
+  it("renders empty buckets", () => {
+    const histogram = new Histogram("latency_seconds", "Latency.", [0.1, 1]);
+
+    expect(histogram.render()).toBe(
+      [
+        "# HELP latency_seconds Latency.",
+        "# TYPE latency_seconds histogram",
+        'latency_seconds_bucket{le="0.1"} 0',
+        'latency_seconds_bucket{le="1"} 0',
+        'latency_seconds_bucket{le="+Inf"} 0',
+        "latency_seconds_sum 0",
+        "latency_seconds_count 0",
+        "",
+      ].join("\n")
+    );
+  });
+
+  it("counts observations cumulatively", () => {
+    const histogram = new Histogram("latency_seconds", "Latency.", [0.1, 1]);
+
+    histogram.observe(0.05);
+    histogram.observe(0.5);
+    histogram.observe(3);
+
+    const text = histogram.render();
+    expect(text).toContain('latency_seconds_bucket{le="0.1"} 1');
+    expect(text).toContain('latency_seconds_bucket{le="1"} 2');
+    expect(text).toContain('latency_seconds_bucket{le="+Inf"} 3');
+    expect(text).toContain("latency_seconds_sum 3.55");
+    expect(text).toContain("latency_seconds_count 3");
+  });
+
+  it("treats bucket bounds as inclusive", () => {
+    const histogram = new Histogram("latency_seconds", "Latency.", [0.1]);
+
+    histogram.observe(0.1);
+
+    expect(histogram.render()).toContain('latency_seconds_bucket{le="0.1"} 1');
+  });
+
+  it("sorts configured buckets", () => {
+    const histogram = new Histogram("latency_seconds", "Latency.", [1, 0.1]);
+
+    const text = histogram.render();
+    expect(text.indexOf('le="0.1"')).toBeLessThan(text.indexOf('le="1"'));
+  });
+});
+
+describe("Counter", () => {
+  it("renders its count", () => {
+    const counter = new Counter("events_total", "Events.");
+
+    counter.inc();
+    counter.inc();
+
+    expect(counter.value).toBe(2);
+    expect(counter.render()).toBe(
+      [
+        "# HELP events_total Events.",
+        "# TYPE events_total counter",
+        "events_total 2",
+        "",
+      ].join("\n")
+    );
+  });
+});
+
+describe("OperationCounter", () => {
+  it("renders success and error counts for each operation", () => {
+    const counter = new OperationCounter("ops_total", "Operations.");
+
+    counter.inc("add", "success");
+    counter.inc("add", "success");
+    counter.inc("divide", "error");
+
+    expect(counter.value("add", "success")).toBe(2);
+    expect(counter.value("add", "error")).toBe(0);
+    expect(counter.render()).toBe(
+      [
+        "# HELP ops_total Operations.",
+        "# TYPE ops_total counter",
+        'ops_total{operation="add",status="success"} 2',
+        'ops_total{operation="add",status="error"} 0',
+        'ops_total{operation="divide",status="success"} 0',
+        'ops_total{operation="divide",status="error"} 1',
+        "",
+      ].join("\n")
+    );
+  });
+});
+
+describe("Metrics", () => {
+  it("exposes the request duration histogram", () => {
+    const metrics = new Metrics([0.5]);
+
+    metrics.requestDuration.observe(0.25);
+
+    expect(metrics.render()).toContain(
+      'calculator_request_duration_seconds_bucket{le="0.5"} 1'
+    );
+  });
+
+  it("exposes the cancelled request counter", () => {
+    const metrics = new Metrics();
+
+    metrics.requestsCancelled.inc();
+
+    expect(metrics.render()).toContain("calculator_requests_cancelled_total 1");
+  });
+});
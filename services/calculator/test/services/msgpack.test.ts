@@ -0,0 +1,85 @@
+import { describe, it, expect } from "vitest";
+import {
+  decodeMessagePack,
+  encodeMessagePack,
+  MessagePackError,
+} from "../../src/services/msgpack";
+
+function bytes(value: unknown): number[] {
+  return Array.from(new Uint8Array(encodeMessagePack(value)));
+}
+
+describe("MessagePack", () => {
+  it.each([
+    { value: null, expected: [0xc0] },
+    { value: true, expected: [0xc3] },
+    { value: 5, expected: [0x05] },
+    { value: -1, expected: [0xff] },
+    { value: 200, expected: [0xcc, 0xc8] },
+    { value: -200, expected: [0xd1, 0xff, 0x38] },
+    { value: 1.5, expected: [0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0] },
+    { value: "hi", expected: [0xa2, 0x68, 0x69] },
+    { value: [1, 2], expected: [0x92, 0x01, 0x02] },
+    { value: { a: 1 }, expected: [0x81, 0xa1, 0x61, 0x01] },
+  ])("encodes $value", ({ value, expected }) => {
+    expect(bytes(value)).toEqual(expected);
+  });
+
+  it.each([
+    0,
+    127,
+    -32,
+    -33,
+    65535,
+    65536,
+    2 ** 32,
+    -(2 ** 31) - 1,
+    Number.MAX_SAFE_INTEGER,
+    Number.MIN_SAFE_INTEGER,
+    0.1,
+    -1e300,
+    Infinity,
+  ])("round-trips the number %d", (value) => {
+    expect(decodeMessagePack(encodeMessagePack(value))).toBe(value);
+  });
+
+  it("round-trips an OperationResponse", () => {
+    const response = { result: 3.14, exact: "157/50" };
+
+    const decoded = decodeMessagePack(encodeMessagePack(response));
+
+    expect(decoded).toEqual(response);
+  });
+
+  it("round-trips nested values and long strings", () => {
+    const value = {
+      text: "x".repeat(300),
+      unicode: "héllo ✓",
+      list: Array.from({ length: 20 }, (_, i) => i),
+      nested: { ok: false, missing: null },
+    };
+
+    expect(decodeMessagePack(encodeMessagePack(value))).toEqual(value);
+  });
+
+  it("skips undefined properties like JSON", () => {
+    const encoded = encodeMessagePack({ a: 1, b: undefined });
+
+    expect(decodeMessagePack(encoded)).toEqual({ a: 1 });
+  });
+
+  it("keeps -0 as a float", () => {
+    expect(Object.is(decodeMessagePack(encodeMessagePack(-0)), -0)).toBe(true);
+  });
+
+  it("rejects truncated and trailing input", () => {
+    const encoded = new Uint8Array(encodeMessagePack("hello"));
+
+    expect(() => decodeMessagePack(encoded.subarray(0, 3))).toThrow(
+      MessagePackError
+    );
+    expect(() => decodeMessagePack(new Uint8Array([0x01, 0x02]))).toThrow(
+      MessagePackError
+    );
+  });
+});
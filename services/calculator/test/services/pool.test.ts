@@ -0,0 +1,105 @@
+import { describe, it, expect } from "vitest";
+import { mapConcurrent, Semaphore } from "../../src/services/pool";
+
+function delay(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+describe("mapConcurrent", () => {
+  it("keeps item order when calls finish out of order", async () => {
+    const finished: number[] = [];
+
+    const results = await mapConcurrent([30, 10, 20], 3, async (ms) => {
+      await delay(ms);
+      finished.push(ms);
+      return ms * 2;
+    });
+
+    expect(finished).toEqual([10, 20, 30]);
+    expect(results).toEqual([60, 20, 40]);
+  });
+
+  it("keeps at most limit calls in flight", async () => {
+    let inFlight = 0;
+    let peak = 0;
+
+    await mapConcurrent([5, 1, 4, 2, 3, 1, 2], 3, async (ms) => {
+      inFlight++;
+      peak = Math.max(peak, inFlight);
+      await delay(ms);
+      inFlight--;
+    });
+
+    expect(peak).toBe(3);
+  });
+
+  it("passes each item's index", async () => {
+    const results = await mapConcurrent(["a", "b"], 2, async (item, index) =>
+      `${index}:${item}`
+    );
+
+    expect(results).toEqual(["0:a", "1:b"]);
+  });
+
+  it("runs one call at a time for a non-positive limit", async () => {
+    let inFlight = 0;
+    let peak = 0;
+
+    await mapConcurrent([1, 1, 1], 0, async (ms) => {
+      inFlight++;
+      peak = Math.max(peak, inFlight);
+      await delay(ms);
+      inFlight--;
+    });
+
+    expect(peak).toBe(1);
+  });
+
+  it("resolves with no results for no items", async () => {
+    await expect(mapConcurrent([], 4, async () => 1)).resolves.toEqual([]);
+  });
+
+  it("starts no further items once the signal aborts", async () => {
+    const controller = new AbortController();
+    const started: number[] = [];
+
+    await mapConcurrent(
+      [1, 2, 3, 4],
+      1,
+      async (item) => {
+        started.push(item);
+        if (item === 2) {
+          controller.abort();
+        }
+      },
+      controller.signal
+    );
+
+    expect(started).toEqual([1, 2]);
+  });
+});
+
+describe("Semaphore", () => {
+  it("hands out at most its permits", () => {
+    const semaphore = new Semaphore(3);
+
+    expect(semaphore.tryAcquire(2)).toBe(2);
+    expect(semaphore.tryAcquire(2)).toBe(1);
+    expect(semaphore.tryAcquire(1)).toBe(0);
+    expect(semaphore.available).toBe(0);
+  });
+
+  it("makes released permits available again", () => {
+    const semaphore = new Semaphore(2);
+    semaphore.tryAcquire(2);
+
+    semaphore.release(1);
+
+    expect(semaphore.available).toBe(1);
+    expect(semaphore.tryAcquire(2)).toBe(1);
+  });
+
+  it.each([0, -1, 1.5, NaN])("rejects %s permits", (permits) => {
+    expect(() => new Semaphore(permits)).toThrow(RangeError);
+  });
+});
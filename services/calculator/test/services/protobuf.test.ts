@@ -0,0 +1,74 @@
+import { describe, it, expect } from "vitest";
+import {
+  decodeErrorResponse,
+  decodeOperationRequest,
+  decodeOperationResponse,
+  encodeErrorResponse,
+  encodeOperationRequest,
+  encodeOperationResponse,
+  ProtobufError,
+} from "../../src/services/protobuf";
+
+describe("Protocol Buffers", () => {
+  it("encodes an OperationRequest as two little-endian doubles", () => {
+    expect(Array.from(encodeOperationRequest({ a: 1.5, b: 2 }))).toEqual([
+      0x09, 0, 0, 0, 0, 0, 0, 0xf8, 0x3f, 0x11, 0, 0, 0, 0, 0, 0, 0, 0x40,
+    ]);
+  });
+
+  it.each([
+    { a: 2, b: 3 },
+    { a: -0.1, b: 1e300 },
+    { a: 0, b: -0 },
+  ])("round-trips OperationRequest $a, $b", (request) => {
+    expect(decodeOperationRequest(encodeOperationRequest(request))).toEqual(
+      request
+    );
+  });
+
+  it("defaults omitted operands to 0", () => {
+    expect(decodeOperationRequest(new Uint8Array())).toEqual({ a: 0, b: 0 });
+  });
+
+  it("skips unknown fields", () => {
+    // Field 3 as a varint and field 4 as a string, then b = 2.
+    const bytes = Uint8Array.from([
+      0x18, 0x96, 0x01, 0x22, 0x01, 0x78, 0x11, 0, 0, 0, 0, 0, 0, 0, 0x40,
+    ]);
+
+    expect(decodeOperationRequest(bytes)).toEqual({ a: 0, b: 2 });
+  });
+
+  it("round-trips an OperationResponse with an exact result", () => {
+    const response = { result: 5, exact: "5" };
+
+    expect(decodeOperationResponse(encodeOperationResponse(response))).toEqual(
+      response
+    );
+  });
+
+  it("round-trips an ErrorResponse with field errors", () => {
+    const response = {
+      error: "Invalid request",
+      code: "invalid_request" as const,
+      timestamp: "2024-01-02T03:04:05.000Z",
+      errors: [{ field: "a", message: "must be a number" }],
+    };
+
+    expect(decodeErrorResponse(encodeErrorResponse(response))).toEqual(
+      response
+    );
+  });
+
+  it.each([
+    { name: "truncated double", bytes: [0x09, 0, 0] },
+    { name: "truncated varint", bytes: [0x18, 0x96] },
+    { name: "unsupported wire type", bytes: [0x0b] },
+    { name: "field number 0", bytes: [0x01] },
+    { name: "string for a double", bytes: [0x0a, 0x00] },
+  ])("rejects a $name", ({ bytes }) => {
+    expect(() => decodeOperationRequest(Uint8Array.from(bytes))).toThrow(
+      ProtobufError
+    );
+  });
+});
@@ -0,0 +1,79 @@
+import { describe, it, expect } from "vitest";
+import {
+  DegradedMode,
+  runReadinessChecks,
+  selfArithmeticCheck,
+} from "../../src/services/readiness";
+
+const sleep = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
+
+describe("runReadinessChecks", () => {
+  it("times each check", async () => {
+    const [result] = await runReadinessChecks(
+      [{ name: "slow", check: () => sleep(50) }],
+      AbortSignal.timeout(1000)
+    );
+
+    expect(result.name).toBe("slow");
+    expect(result.status).toBe("ok");
+    expect(result.latencyMs).toBeGreaterThanOrEqual(40);
+  });
+
+  it("reports a failing check with its error", async () => {
+    const results = await runReadinessChecks(
+      [
+        selfArithmeticCheck,
+        {
+          name: "database",
+          check: () => Promise.reject(new Error("connection refused")),
+        },
+      ],
+      AbortSignal.timeout(1000)
+    );
+
+    expect(results).toEqual([
+      { name: "arithmetic", status: "ok", latencyMs: expect.any(Number) },
+      {
+        name: "database",
+        status: "failing",
+        latencyMs: expect.any(Number),
+        error: "connection refused",
+      },
+    ]);
+  });
+
+  it("fails a check still running when the signal aborts", async () => {
+    const controller = new AbortController();
+    const results = runReadinessChecks(
+      [{ name: "hung", check: () => new Promise(() => {}) }],
+      controller.signal
+    );
+    controller.abort(new Error("deadline passed"));
+
+    const [result] = await results;
+    expect(result.status).toBe("failing");
+    expect(result.error).toBe("deadline passed");
+  });
+
+  it("times the arithmetic self-check under a millisecond", async () => {
+    const [result] = await runReadinessChecks(
+      [selfArithmeticCheck],
+      AbortSignal.timeout(1000)
+    );
+
+    expect(result.status).toBe("ok");
+    expect(result.latencyMs).toBeLessThan(1);
+  });
+});
+
+describe("DegradedMode", () => {
+  it("starts healthy and toggles", () => {
+    const mode = new DegradedMode();
+
+    expect(mode.active).toBe(false);
+    mode.degrade();
+    expect(mode.active).toBe(true);
+    mode.recover();
+    expect(mode.active).toBe(false);
+  });
+});
@@ -0,0 +1,95 @@
+import { describe, it, expect } from "vitest";
+import {
+  Recording,
+  recordRequest,
+  recordResponse,
+  replay,
+} from "../../src/services/recording";
+import type { RecordedExchange } from "../../src/services/recording";
+
+function exchange(path: string): RecordedExchange {
+  return {
+    request: { method: "GET", path, headers: {}, body: "" },
+    response: { status: 200, headers: {}, body: "" },
+  };
+}
+
+describe("Recording", () => {
+  it("keeps the most recent exchanges, oldest first", () => {
+    const recording = new Recording(2);
+
+    recording.record(exchange("/a"));
+    recording.record(exchange("/b"));
+    recording.record(exchange("/c"));
+
+    expect(recording.exchanges().map(({ request }) => request.path)).toEqual([
+      "/b",
+      "/c",
+    ]);
+  });
+
+  it.each([0, -1, 1.5])("rejects size %s", (size) => {
+    expect(() => new Recording(size)).toThrow(RangeError);
+  });
+});
+
+describe("recordRequest", () => {
+  it("keeps the original body readable", async () => {
+    const request = new Request("http://localhost/add?exact=true", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: '{"a":1,"b":2}',
+    });
+
+    expect(await recordRequest(request)).toEqual({
+      method: "POST",
+      path: "/add?exact=true",
+      headers: { "content-type": "application/json" },
+      body: '{"a":1,"b":2}',
+    });
+    expect(await request.text()).toBe('{"a":1,"b":2}');
+  });
+});
+
+describe("recordResponse", () => {
+  it("stores binary bodies as base64", async () => {
+    const response = new Response(new Uint8Array([0x81, 0xa1, 0x00]), {
+      headers: { "Content-Type": "application/msgpack" },
+    });
+
+    expect(await recordResponse(response)).toMatchObject({
+      status: 200,
+      body: "gaEA",
+      base64: true,
+    });
+  });
+});
+
+describe("replay", () => {
+  it("re-sends binary request bodies intact", async () => {
+    const received: number[][] = [];
+    const mismatches = await replay(
+      {
+        exchanges: [
+          {
+            request: {
+              method: "POST",
+              path: "/echo",
+              headers: { "content-type": "application/x-protobuf" },
+              body: "CQ==",
+              base64: true,
+            },
+            response: { status: 204, headers: {}, body: "" },
+          },
+        ],
+      },
+      async (request) => {
+        received.push([...new Uint8Array(await request.arrayBuffer())]);
+        return new Response(null, { status: 204 });
+      }
+    );
+
+    expect(received).toEqual([[0x09]]);
+    expect(mismatches).toEqual([]);
+  });
+});
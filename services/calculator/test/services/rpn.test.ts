@@ -0,0 +1,66 @@
+import { describe, it, expect } from "vitest";
+import {
+  DivisionByZeroError,
+  InvalidInputError,
+} from "../../src/services/calculator";
+import { calculatorService } from "../../src/services/operations";
+import { evaluateRpn } from "../../src/services/rpn";
+import type { RpnOperationName } from "../../src/services/rpn";
+
+const apply = (name: RpnOperationName, a: number, b: number) =>
+  calculatorService[name](a, b);
+
+describe("evaluateRpn", () => {
+  it.each([
+    { tokens: ["3", "4", "+", "2", "*"], result: 14 },
+    { tokens: ["5", "1", "2", "+", "4", "*", "+", "3", "-"], result: 14 },
+    { tokens: ["10", "4", "/"], result: 2.5 },
+    { tokens: ["-3", "2", "-"], result: -5 },
+    { tokens: ["1.5e2"], result: 150 },
+  ])("evaluates $tokens to $result", async ({ tokens, result }) => {
+    expect(await evaluateRpn(tokens, apply)).toBe(result);
+  });
+
+  it("passes operands to apply in stack order", async () => {
+    const calls: unknown[] = [];
+    await evaluateRpn(["7", "2", "-"], (name, a, b) => {
+      calls.push([name, a, b]);
+      return a - b;
+    });
+
+    expect(calls).toEqual([["subtract", 7, 2]]);
+  });
+
+  it("reports stack underflow with the token position", async () => {
+    await expect(evaluateRpn(["1", "*"], apply)).rejects.toThrow(
+      'invalid input: stack underflow at token 1 ("*" needs 2 operands, found 1)'
+    );
+  });
+
+  it("reports operands left on the stack", async () => {
+    await expect(evaluateRpn(["1", "2"], apply)).rejects.toThrow(
+      "invalid input: 2 operands left on the stack, expected 1"
+    );
+  });
+
+  it("rejects an empty expression", async () => {
+    await expect(evaluateRpn([], apply)).rejects.toThrow(
+      "invalid input: expression is empty"
+    );
+  });
+
+  it.each(["x", "", "0x10", "1,5", "++"])(
+    "rejects the unknown token %j",
+    async (token) => {
+      await expect(evaluateRpn(["1", token], apply)).rejects.toThrow(
+        InvalidInputError
+      );
+    }
+  );
+
+  it("propagates errors from apply", async () => {
+    await expect(evaluateRpn(["1", "0", "/"], apply)).rejects.toThrow(
+      DivisionByZeroError
+    );
+  });
+});
@@ -0,0 +1,41 @@
+import { describe, it, expect } from "vitest";
+import { FakeClock } from "../../src/services/clock";
+import { Stats } from "../../src/services/stats";
+
+describe("Stats", () => {
+  it("starts empty", () => {
+    const stats = new Stats(new FakeClock());
+
+    expect(stats.snapshot()).toEqual({
+      total: 0,
+      errors: 0,
+      operations: {},
+      uptimeSeconds: 0,
+    });
+  });
+
+  it("counts operations and errors", () => {
+    const stats = new Stats(new FakeClock());
+
+    stats.record("add", true);
+    stats.record("add", false);
+    stats.record("ln", true);
+
+    expect(stats.snapshot()).toMatchObject({
+      total: 3,
+      errors: 1,
+      operations: { add: 2, ln: 1 },
+    });
+  });
+
+  it("measures uptime from the first record", () => {
+    const clock = new FakeClock();
+    const stats = new Stats(clock);
+    clock.advance(60_000);
+
+    stats.record("add", true);
+    clock.advance(2500);
+
+    expect(stats.snapshot().uptimeSeconds).toBe(2.5);
+  });
+});
@@ -0,0 +1,166 @@
+import { describe, it, expect } from "vitest";
+import {
+  InvalidInputError,
+  NonIntegerError,
+  OverflowError,
+} from "../../src/services/calculator";
+import {
+  ValidatorRegistry,
+  createDefaultValidators,
+} from "../../src/services/validators";
+
+function rejectOdd(operands: number[]): void {
+  if (operands.some((n) => n % 2 !== 0)) {
+    throw new InvalidInputError("invalid input: operands must be even");
+  }
+}
+
+describe("ValidatorRegistry", () => {
+  it("applies the NaN/Infinity baseline to every operation", () => {
+    const registry = new ValidatorRegistry();
+
+    expect(() => registry.validate("anything", [NaN, 1])).toThrow(
+      "invalid input: NaN and Infinity not allowed"
+    );
+    expect(() => registry.validate("anything", [1, 2])).not.toThrow();
+  });
+
+  it("runs validators registered for the operation", () => {
+    const registry = new ValidatorRegistry().register("add", rejectOdd);
+
+    expect(() => registry.validate("add", [2, 4])).not.toThrow();
+    expect(() => registry.validate("add", [2, 3])).toThrow(
+      "invalid input: operands must be even"
+    );
+  });
+
+  it("does not apply validators to other operations", () => {
+    const registry = new ValidatorRegistry().register("add", rejectOdd);
+
+    expect(() => registry.validate("multiply", [3, 5])).not.toThrow();
+  });
+
+  it("runs every validator registered for an operation in order", () => {
+    const calls: string[] = [];
+    const registry = new ValidatorRegistry()
+      .register("add", () => calls.push("first"))
+      .register("add", () => calls.push("second"));
+
+    registry.validate("add", [1, 2]);
+
+    expect(calls).toEqual(["first", "second"]);
+  });
+
+  it("checks the baseline before custom validators", () => {
+    const calls: string[] = [];
+    const registry = new ValidatorRegistry().register("add", () =>
+      calls.push("custom")
+    );
+
+    expect(() => registry.validate("add", [Infinity, 1])).toThrow(
+      InvalidInputError
+    );
+    expect(calls).toEqual([]);
+  });
+
+  describe("createDefaultValidators", () => {
+    it.each(["log", "ln"])("%s rejects non-positive operands", (operation) => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate(operation, [0])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate(operation, [-1])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate(operation, [1])).not.toThrow();
+    });
+
+    it("divide rejects a zero divisor", () => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate("divide", [1, 0])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate("divide", [0, 1])).not.toThrow();
+    });
+
+    it("approxEqual rejects a negative epsilon", () => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate("approxEqual", [1, 1, -1])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate("approxEqual", [1, 1, 0])).not.toThrow();
+    });
+
+    it("polynomial rejects an empty coefficient list", () => {
+      const registry = createDefaultValidators();
+
+      // The operands are the coefficients followed by x.
+      expect(() => registry.validate("polynomial", [2])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate("polynomial", [1, 2])).not.toThrow();
+    });
+
+    it("divmod rejects a zero divisor", () => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate("divmod", [1, 0])).toThrow(
+        InvalidInputError
+      );
+    });
+
+    it("multiply rejects a product beyond the largest finite number", () => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate("multiply", [1e155, 1e155])).toThrow(
+        OverflowError
+      );
+      expect(() => registry.validate("multiply", [1e154, 1e154])).not.toThrow();
+    });
+
+    it("factorial rejects negative and non-integer operands", () => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate("factorial", [-1])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate("factorial", [1.5])).toThrow(
+        NonIntegerError
+      );
+      expect(() => registry.validate("factorial", [0])).not.toThrow();
+    });
+
+    it("round rejects places outside 0 to 15", () => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate("round", [1.5, -1])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate("round", [1.5, 16])).toThrow(
+        InvalidInputError
+      );
+      expect(() => registry.validate("round", [1.5, 2])).not.toThrow();
+    });
+
+    it.each(["gcd", "lcm"])("%s rejects non-integer operands", (operation) => {
+      const registry = createDefaultValidators();
+
+      expect(() => registry.validate(operation, [1.5, 2])).toThrow(
+        NonIntegerError
+      );
+      expect(() => registry.validate(operation, [4, 6])).not.toThrow();
+    });
+
+    it("returns independent registries", () => {
+      const extended = createDefaultValidators().register("add", rejectOdd);
+
+      expect(() => extended.validate("add", [1, 2])).toThrow();
+      expect(() =>
+        createDefaultValidators().validate("add", [1, 2])
+      ).not.toThrow();
+    });
+  });
+});